@@ -44,6 +44,11 @@ func Execute() error {
 func init() {
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(utilsBootstrapCmd)
+	rootCmd.AddCommand(utilsPrepareDatadirCmd)
+	rootCmd.AddCommand(utilsExportBlocksCmd)
+	rootCmd.AddCommand(utilsReconcileCmd)
+	rootCmd.AddCommand(utilsSignCmd)
+	rootCmd.AddCommand(utilsRecordFixtureCmd)
 }
 
 // handleSignals handles OS signals so we can ensure we close database
@@ -62,6 +62,16 @@ func runRunCmd(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("%w: unable to load configuration", err)
 	}
 
+	serverCfg, err := configuration.LoadServerConfiguration()
+	if err != nil {
+		return fmt.Errorf("%w: unable to load server configuration", err)
+	}
+
+	tlsConfig, err := services.NewTLSConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("%w: unable to load TLS configuration", err)
+	}
+
 	// The asserter automatically rejects incorrectly formatted
 	// requests.
 	asserter, err := asserter.NewServer(
@@ -85,34 +95,73 @@ func runRunCmd(cmd *cobra.Command, args []string) error {
 
 	var client *fantom.Client
 	if cfg.Mode == configuration.Online {
+		var err error
+		client, err = fantom.NewClient(cfg.OperaURL, cfg.SkipAdmin)
+		if err != nil {
+			return fmt.Errorf("%w: cannot initialize ethereum client", err)
+		}
+		defer client.Close()
+
+		if len(cfg.ABIDir) > 0 {
+			registry := fantom.NewABIRegistry()
+			if err := registry.LoadDir(cfg.ABIDir); err != nil {
+				return fmt.Errorf("%w: unable to load ABI_DIR", err)
+			}
+			client.SetABIRegistry(registry)
+		}
+
 		if !cfg.RemoteOpera {
+			datadirCfg, err := configuration.LoadDatadirConfiguration()
+			if err != nil {
+				return fmt.Errorf("%w: unable to load datadir configuration", err)
+			}
+
+			if err := fantom.PrepareDatadir(ctx, datadirCfg); err != nil {
+				return fmt.Errorf("%w: unable to prepare datadir", err)
+			}
+
 			g.Go(func() error {
-				return fantom.StartOpera(ctx, cfg.OperaArguments, g)
+				return fantom.StartOpera(ctx, cfg.OperaBinary, cfg.OperaArguments, client.MarkNotReady)
 			})
 		}
 
-		var err error
-		client, err = fantom.NewClient(cfg.OperaURL, cfg.SkipAdmin)
+		g.Go(func() error {
+			return client.TrackTransactions(ctx, cfg.RebroadcastInterval)
+		})
+
+		reconcileCfg, err := configuration.LoadReconcileConfiguration()
 		if err != nil {
-			return fmt.Errorf("%w: cannot initialize ethereum client", err)
+			return fmt.Errorf("%w: unable to load reconciliation configuration", err)
+		}
+
+		if reconcileCfg != nil {
+			g.Go(func() error {
+				return fantom.RunReconciler(ctx, client, reconcileCfg)
+			})
 		}
-		defer client.Close()
 	}
 
 	router := services.NewBlockchainRouter(cfg, client, asserter)
 
-	loggedRouter := server.LoggerMiddleware(router)
-	corsRouter := server.CorsMiddleware(loggedRouter)
+	limitedRouter := services.RateLimitMiddleware(serverCfg, router)
+	authRouter := services.AuthMiddleware(serverCfg, limitedRouter)
+	loggedRouter := server.LoggerMiddleware(authRouter)
+	corsRouter := services.CorsMiddleware(serverCfg, loggedRouter)
 	server := &http.Server{
-		Addr:         fmt.Sprintf(":%d", cfg.Port),
+		Addr:         cfg.Addr(),
 		Handler:      corsRouter,
 		ReadTimeout:  readTimeout,
 		WriteTimeout: writeTimeout,
 		IdleTimeout:  idleTimeout,
+		TLSConfig:    tlsConfig,
 	}
 
 	g.Go(func() error {
-		log.Printf("server listening on port %d", cfg.Port)
+		log.Printf("server listening on %s", cfg.Addr())
+		if tlsConfig != nil {
+			// The certificate is served by tlsConfig.GetCertificate
+			return server.ListenAndServeTLS("", "")
+		}
 		return server.ListenAndServe()
 	})
 
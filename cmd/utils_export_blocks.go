@@ -0,0 +1,137 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Fantom-foundation/rosetta-fantom/configuration"
+	"github.com/Fantom-foundation/rosetta-fantom/fantom"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	utilsExportBlocksCmd = &cobra.Command{
+		Use:   "utils:export-blocks",
+		Short: "Export blocks as newline-delimited JSON",
+		Long: `To load Rosetta blocks into data pipelines, this command
+fetches a range of blocks from Opera in parallel and writes
+them in order as newline-delimited JSON, either as Rosetta
+blocks (--format blocks) or as flattened operations with one
+row per operation (--format operations).
+
+Without --end, blocks are exported up to the current head.
+With --follow, new blocks keep being exported as they are
+produced.
+
+With --checkpoint, the index of the last exported block is
+stored in the provided file and an interrupted export resumes
+after it, appending to --output after its last complete line.
+The blocks of the batch being written when the export was
+interrupted may be repeated.
+
+Opera is reached at OPERA (or --opera-url).`,
+		RunE: runUtilsExportBlocksCmd,
+		Args: cobra.NoArgs,
+	}
+
+	exportOperaURL    string
+	exportStart       int64
+	exportEnd         int64
+	exportFollow      bool
+	exportConcurrency int
+	exportFormat      string
+	exportOutput      string
+	exportCheckpoint  string
+)
+
+func init() {
+	operaURL := os.Getenv(configuration.OperaEnv)
+	if len(operaURL) == 0 {
+		operaURL = configuration.DefaultOperaURL
+	}
+
+	flags := utilsExportBlocksCmd.Flags()
+	flags.StringVar(&exportOperaURL, "opera-url", operaURL, "URL of the Opera JSON-RPC endpoint")
+	flags.Int64Var(&exportStart, "start", 0, "index of the first block to export")
+	flags.Int64Var(&exportEnd, "end", -1, "index of the last block to export (current head if negative)")
+	flags.BoolVar(&exportFollow, "follow", false, "keep exporting new blocks once the head is reached")
+	flags.IntVar(
+		&exportConcurrency,
+		"concurrency",
+		fantom.DefaultExportConcurrency,
+		"number of blocks fetched in parallel",
+	)
+	flags.StringVar(
+		&exportFormat,
+		"format",
+		fantom.ExportFormatBlocks,
+		fmt.Sprintf("export format (%s or %s)", fantom.ExportFormatBlocks, fantom.ExportFormatOperations),
+	)
+	flags.StringVar(&exportOutput, "output", "", "file to export to (stdout if empty)")
+	flags.StringVar(&exportCheckpoint, "checkpoint", "", "file storing the last exported block")
+}
+
+func runUtilsExportBlocksCmd(cmd *cobra.Command, args []string) error {
+	client, err := fantom.NewClient(exportOperaURL, true)
+	if err != nil {
+		return fmt.Errorf("%w: cannot initialize opera client", err)
+	}
+	defer client.Close()
+
+	var output io.Writer = os.Stdout
+	if len(exportOutput) > 0 {
+		// Append to the output of an interrupted export,
+		// after its last complete line
+		flag := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+		if len(exportCheckpoint) > 0 {
+			if _, err := os.Stat(exportCheckpoint); err == nil {
+				if err := fantom.TruncateExportOutput(exportOutput); err != nil {
+					return fmt.Errorf("%w: unable to truncate output", err)
+				}
+				flag = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+			}
+		}
+
+		f, err := os.OpenFile(exportOutput, flag, 0600) // #nosec G304
+		if err != nil {
+			return fmt.Errorf("%w: unable to open output", err)
+		}
+		defer f.Close()
+		output = f
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go handleSignals([]context.CancelFunc{cancel})
+
+	err = fantom.ExportBlocks(ctx, client, output, &fantom.ExportConfig{
+		Start:       exportStart,
+		End:         exportEnd,
+		Follow:      exportFollow,
+		Concurrency: exportConcurrency,
+		Format:      exportFormat,
+		Checkpoint:  exportCheckpoint,
+	})
+	if SignalReceived && errors.Is(err, context.Canceled) {
+		return errors.New("export interrupted")
+	}
+
+	return err
+}
@@ -0,0 +1,63 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+
+	"github.com/Fantom-foundation/rosetta-fantom/configuration"
+	"github.com/Fantom-foundation/rosetta-fantom/fantom"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	utilsPrepareDatadirCmd = &cobra.Command{
+		Use:   "utils:prepare-datadir",
+		Short: "Prepare the Opera datadir",
+		Long: `Before Opera is started for the first time, its datadir
+must contain the genesis file of the network and, optionally,
+the extracted chain database of a snapshot. This command
+downloads (resuming interrupted downloads), verifies and
+extracts these files. It is run automatically by the run
+command when Opera is started by rosetta-fantom.
+
+The datadir and files are configured with the NETWORK, DATA_DIR,
+GENESIS, GENESIS_CHECKSUM, SNAPSHOT, SNAPSHOT_CHECKSUM and
+DOWNLOAD_BASE_URL environment variables.
+
+Exit codes:
+51 - genesis file download failed
+52 - invalid genesis file checksum
+53 - invalid configuration
+54 - snapshot archive download failed
+55 - invalid snapshot archive checksum
+56 - snapshot archive extraction failed`,
+		RunE: runUtilsPrepareDatadirCmd,
+		Args: cobra.NoArgs,
+	}
+)
+
+func runUtilsPrepareDatadirCmd(cmd *cobra.Command, args []string) error {
+	cfg, err := configuration.LoadDatadirConfiguration()
+	if err != nil {
+		return &fantom.DatadirError{Code: fantom.ExitCodeInvalidConfig, Err: err}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go handleSignals([]context.CancelFunc{cancel})
+
+	return fantom.PrepareDatadir(ctx, cfg)
+}
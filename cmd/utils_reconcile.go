@@ -0,0 +1,148 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Fantom-foundation/rosetta-fantom/configuration"
+	"github.com/Fantom-foundation/rosetta-fantom/fantom"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	utilsReconcileCmd = &cobra.Command{
+		Use:   "utils:reconcile",
+		Short: "Reconcile the balances of accounts",
+		Long: `To check that the operations of rosetta-fantom account for all
+balance changes, this command replays the operations of a set
+of accounts and compares the computed balances with the balances
+returned by Opera at the same block.
+
+The computed balances are initialized at block --start and
+compared every --check-interval blocks up to --end (or the
+current head). With --follow, new blocks keep being reconciled.
+Each mismatch is reported with the block range containing the
+operations causing it.
+
+The accounts (--accounts) and the exempt accounts whose mismatches
+are not alerted on (--exempt-accounts) are JSON files in the
+format of the exempt accounts of rosetta-cli, like
+rosetta-cli-conf/mainnet/exempt_accounts.json.
+
+The command exits with an error if any non-exempt account
+mismatched. Opera is reached at OPERA (or --opera-url).`,
+		RunE: runUtilsReconcileCmd,
+		Args: cobra.NoArgs,
+	}
+
+	reconcileOperaURL       string
+	reconcileAccounts       string
+	reconcileExemptAccounts string
+	reconcileStart          int64
+	reconcileEnd            int64
+	reconcileFollow         bool
+	reconcileConcurrency    int
+	reconcileCheckInterval  int64
+	reconcileAlertURL       string
+)
+
+func init() {
+	operaURL := os.Getenv(configuration.OperaEnv)
+	if len(operaURL) == 0 {
+		operaURL = configuration.DefaultOperaURL
+	}
+
+	flags := utilsReconcileCmd.Flags()
+	flags.StringVar(&reconcileOperaURL, "opera-url", operaURL, "URL of the Opera JSON-RPC endpoint")
+	flags.StringVar(&reconcileAccounts, "accounts", "", "file listing the accounts to reconcile")
+	flags.StringVar(&reconcileExemptAccounts, "exempt-accounts", "", "file listing the exempt accounts")
+	flags.Int64Var(
+		&reconcileStart,
+		"start",
+		-1,
+		"block at which balances are initialized (current head if negative)",
+	)
+	flags.Int64Var(&reconcileEnd, "end", -1, "last block to reconcile (current head if negative)")
+	flags.BoolVar(&reconcileFollow, "follow", false, "keep reconciling new blocks once the head is reached")
+	flags.IntVar(
+		&reconcileConcurrency,
+		"concurrency",
+		fantom.DefaultExportConcurrency,
+		"number of blocks fetched in parallel",
+	)
+	flags.Int64Var(&reconcileCheckInterval, "check-interval", 1, "number of blocks between balance comparisons")
+	flags.StringVar(&reconcileAlertURL, "alert-url", "", "URL receiving mismatches as JSON POST requests")
+}
+
+func runUtilsReconcileCmd(cmd *cobra.Command, args []string) error {
+	if len(reconcileAccounts) == 0 {
+		return errors.New("--accounts must be provided")
+	}
+
+	accounts, err := fantom.LoadAccountCurrencies(reconcileAccounts)
+	if err != nil {
+		return fmt.Errorf("%w: unable to load accounts", err)
+	}
+
+	var exempt []*types.AccountCurrency
+	if len(reconcileExemptAccounts) > 0 {
+		exempt, err = fantom.LoadAccountCurrencies(reconcileExemptAccounts)
+		if err != nil {
+			return fmt.Errorf("%w: unable to load exempt accounts", err)
+		}
+	}
+
+	client, err := fantom.NewClient(reconcileOperaURL, true)
+	if err != nil {
+		return fmt.Errorf("%w: cannot initialize opera client", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go handleSignals([]context.CancelFunc{cancel})
+
+	result, err := fantom.Reconcile(ctx, client, &fantom.ReconcileConfig{
+		Accounts:      accounts,
+		Exempt:        exempt,
+		Start:         reconcileStart,
+		End:           reconcileEnd,
+		Follow:        reconcileFollow,
+		Concurrency:   reconcileConcurrency,
+		CheckInterval: reconcileCheckInterval,
+		AlertURL:      reconcileAlertURL,
+	})
+	if result != nil {
+		fmt.Println(types.PrettyPrintStruct(result))
+	}
+	if err != nil && !(SignalReceived && errors.Is(err, context.Canceled)) {
+		return err
+	}
+
+	if result != nil && result.Failed() {
+		return fmt.Errorf(
+			"reconciliation of blocks %d to %d found mismatches",
+			result.StartIndex,
+			result.EndIndex,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Fantom-foundation/rosetta-fantom/configuration"
+	"github.com/Fantom-foundation/rosetta-fantom/fantom"
+	"github.com/Fantom-foundation/rosetta-fantom/operatest"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// fixturesDir is the directory of the operatest
+	// fixtures in the output directory.
+	fixturesDir = "fixtures"
+)
+
+var (
+	utilsRecordFixtureCmd = &cobra.Command{
+		Use:   "utils:record-fixture",
+		Short: "Record the node responses of a block or transaction as testdata",
+		Long: `To add regression tests without building testdata by hand,
+this command fetches a block (by index) or a transaction (by
+hash) from Opera through a recording proxy and writes:
+
+- the result of every JSON-RPC call made by the client using
+  the testdata file names of the fantom package
+  (block_<index>.json, block_trace_<hash>.json,
+  tx_receipt_<hash>.json, transaction_<hash>.json...)
+- the resulting Rosetta output as block_response_<index>.json
+  or transaction_response_<hash>.json
+- all recorded calls as operatest fixtures in
+  fixtures/block_<index>.json or fixtures/transaction_<hash>.json,
+  which can be replayed with operatest.LoadFixtures
+
+Opera is reached at OPERA (or --opera-url).
+
+When calling this command, you must provide 1 argument:
+[1] the index of the block or the hash of the transaction`,
+		RunE: runUtilsRecordFixtureCmd,
+		Args: cobra.ExactArgs(1),
+	}
+
+	recordOperaURL  string
+	recordOutputDir string
+)
+
+func init() {
+	operaURL := os.Getenv(configuration.OperaEnv)
+	if len(operaURL) == 0 {
+		operaURL = configuration.DefaultOperaURL
+	}
+
+	flags := utilsRecordFixtureCmd.Flags()
+	flags.StringVar(&recordOperaURL, "opera-url", operaURL, "URL of the Opera JSON-RPC endpoint")
+	flags.StringVar(&recordOutputDir, "output-dir", "fantom/testdata", "directory to write the testdata to")
+}
+
+func runUtilsRecordFixtureCmd(cmd *cobra.Command, args []string) error {
+	recorder := operatest.NewRecorder(recordOperaURL)
+	proxy := httptest.NewServer(recorder)
+	defer proxy.Close()
+
+	client, err := fantom.NewClient(proxy.URL, true)
+	if err != nil {
+		return fmt.Errorf("%w: cannot initialize opera client", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	var name string
+	var response interface{}
+	if strings.HasPrefix(args[0], "0x") {
+		hash := common.HexToHash(args[0]).Hex()
+		if !strings.EqualFold(hash, args[0]) {
+			return fmt.Errorf("invalid transaction hash %s", args[0])
+		}
+
+		blockHash, err := transactionBlockHash(ctx, recordOperaURL, hash)
+		if err != nil {
+			return err
+		}
+
+		tx, err := client.Transaction(
+			ctx,
+			&types.BlockIdentifier{Hash: blockHash},
+			&types.TransactionIdentifier{Hash: hash},
+		)
+		if err != nil {
+			return fmt.Errorf("%w: unable to fetch transaction %s", err, hash)
+		}
+
+		name = fmt.Sprintf("transaction_%s.json", hash)
+		response = &types.BlockTransactionResponse{Transaction: tx}
+	} else {
+		index, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil || index < 0 {
+			return fmt.Errorf("invalid block index %s", args[0])
+		}
+
+		block, err := client.Block(ctx, &types.PartialBlockIdentifier{Index: &index})
+		if err != nil {
+			return fmt.Errorf("%w: unable to fetch block %d", err, index)
+		}
+
+		name = fmt.Sprintf("block_%d.json", index)
+		response = &types.BlockResponse{Block: block}
+	}
+
+	fixtures := recorder.Fixtures()
+	written, err := operatest.WriteTestdata(recordOutputDir, fixtures)
+	if err != nil {
+		return fmt.Errorf("%w: unable to write testdata", err)
+	}
+
+	// block_<index>.json becomes block_response_<index>.json
+	responsePath := filepath.Join(recordOutputDir, strings.Replace(name, "_", "_response_", 1))
+	contents, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(responsePath, append(contents, '\n'), 0600); err != nil {
+		return fmt.Errorf("%w: unable to write response", err)
+	}
+	written = append(written, responsePath)
+
+	fixturesPath := filepath.Join(recordOutputDir, fixturesDir, name)
+	if err := operatest.WriteFixtures(fixturesPath, fixtures); err != nil {
+		return fmt.Errorf("%w: unable to write fixtures", err)
+	}
+	written = append(written, fixturesPath)
+
+	for _, path := range written {
+		fmt.Println(path)
+	}
+
+	return nil
+}
+
+// transactionBlockHash returns the hash of the block
+// including the transaction hash.
+func transactionBlockHash(ctx context.Context, url string, hash string) (string, error) {
+	c, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return "", fmt.Errorf("%w: unable to dial node", err)
+	}
+	defer c.Close()
+
+	var tx *struct {
+		BlockHash *string `json:"blockHash"`
+	}
+	if err := c.CallContext(ctx, &tx, "eth_getTransactionByHash", hash); err != nil {
+		return "", fmt.Errorf("%w: unable to fetch transaction %s", err, hash)
+	}
+	if tx == nil {
+		return "", fmt.Errorf("transaction %s not found", hash)
+	}
+	if tx.BlockHash == nil {
+		return "", fmt.Errorf("transaction %s is pending", hash)
+	}
+
+	return *tx.BlockHash, nil
+}
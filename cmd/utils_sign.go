@@ -0,0 +1,172 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/Fantom-foundation/rosetta-fantom/services"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	utilsSignCmd = &cobra.Command{
+		Use:   "utils:sign",
+		Short: "Sign the payloads of a /construction/payloads response offline",
+		Long: `To sign transactions without sending private keys to a
+server, this command signs the payloads returned by
+/construction/payloads with a local key. The hash of each
+payload is recomputed from the unsigned transaction and
+the command fails if it does not match.
+
+The key is loaded from a geth V3 keystore file (--keystore),
+whose passphrase is read from --passphrase-file or prompted
+for, or from a file containing a hex private key (--private-key).
+
+The signatures array is written to stdout (or --output) and
+can be provided as "signatures" to /construction/combine.
+
+When calling this command, you must provide 1 argument:
+[1] the location of the /construction/payloads response JSON`,
+		RunE: runUtilsSignCmd,
+		Args: cobra.ExactArgs(1),
+	}
+
+	signKeystore       string
+	signPassphraseFile string
+	signPrivateKey     string
+	signOutput         string
+)
+
+func init() {
+	flags := utilsSignCmd.Flags()
+	flags.StringVar(&signKeystore, "keystore", "", "geth V3 keystore file of the signing key")
+	flags.StringVar(
+		&signPassphraseFile,
+		"passphrase-file",
+		"",
+		"file containing the keystore passphrase (prompted for if empty)",
+	)
+	flags.StringVar(&signPrivateKey, "private-key", "", "file containing the hex private key")
+	flags.StringVar(&signOutput, "output", "", "file to write the signatures to (stdout if empty)")
+}
+
+func runUtilsSignCmd(cmd *cobra.Command, args []string) error {
+	var response types.ConstructionPayloadsResponse
+	if err := loadJSONFile(args[0], &response); err != nil {
+		return fmt.Errorf("%w: unable to load payloads", err)
+	}
+
+	key, err := loadSigningKey()
+	if err != nil {
+		return err
+	}
+
+	signatures, err := services.SignPayloads(&response, key)
+	if err != nil {
+		return err
+	}
+
+	if len(signOutput) == 0 {
+		fmt.Println(types.PrettyPrintStruct(signatures))
+		return nil
+	}
+
+	return ioutil.WriteFile(signOutput, []byte(types.PrettyPrintStruct(signatures)), 0600) // nolint:gomnd
+}
+
+func loadJSONFile(path string, output interface{}) error {
+	contents, err := ioutil.ReadFile(path) // #nosec G304
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(contents, output)
+}
+
+func loadSigningKey() (*ecdsa.PrivateKey, error) {
+	switch {
+	case len(signKeystore) > 0 && len(signPrivateKey) > 0:
+		return nil, errors.New("only one of --keystore and --private-key can be provided")
+	case len(signPrivateKey) > 0:
+		key, err := crypto.LoadECDSA(signPrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to load private key", err)
+		}
+		return key, nil
+	case len(signKeystore) > 0:
+		keyJSON, err := ioutil.ReadFile(signKeystore) // #nosec G304
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to read keystore", err)
+		}
+
+		passphrase, err := readPassphrase()
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to read passphrase", err)
+		}
+
+		key, err := keystore.DecryptKey(keyJSON, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to decrypt keystore", err)
+		}
+		return key.PrivateKey, nil
+	}
+
+	return nil, errors.New("--keystore or --private-key must be provided")
+}
+
+// readPassphrase reads the keystore passphrase from
+// --passphrase-file or prompts for it on the terminal.
+func readPassphrase() (string, error) {
+	if len(signPassphraseFile) > 0 {
+		contents, err := ioutil.ReadFile(signPassphraseFile) // #nosec G304
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(contents), "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, "Passphrase: ")
+
+	// The passphrase is not echoed when typed on a terminal
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		passphrase, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+		return string(passphrase), nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && len(line) == 0 {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
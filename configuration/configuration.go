@@ -15,11 +15,17 @@
 package configuration
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"math"
 	"math/big"
+	"net"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Fantom-foundation/rosetta-fantom/fantom"
 
@@ -30,6 +36,10 @@ import (
 // the implementation is "online" or "offline".
 type Mode string
 
+// TLSClientAuth is the setting that determines if
+// TLS client certificates are required.
+type TLSClientAuth string
+
 const (
 	// Online is when the implementation is permitted
 	// to make outbound connections.
@@ -45,6 +55,14 @@ const (
 	// Testnet is the Fantom Testnet.
 	Testnet string = "TESTNET"
 
+	// TLSClientAuthOptional is when client certificates
+	// are verified if provided.
+	TLSClientAuthOptional TLSClientAuth = "OPTIONAL"
+
+	// TLSClientAuthRequired is when the connections of
+	// clients without a verified certificate are refused.
+	TLSClientAuthRequired TLSClientAuth = "REQUIRED"
+
 	// ModeEnv is the environment variable read
 	// to determine mode.
 	ModeEnv = "MODE"
@@ -76,6 +94,176 @@ const (
 	// OperaArgsEnv is an environment variable to pass arguments to the Opera process
 	OperaArgsEnv = "OPERA_ARGS"
 
+	// OperaBinaryEnv is an optional environment variable
+	// with the path of the Opera binary started when
+	// OperaEnv is not populated.
+	OperaBinaryEnv = "OPERA_BINARY"
+
+	// DefaultOperaBinary is the default path of the Opera
+	// binary. This is used when OperaBinaryEnv is not populated.
+	DefaultOperaBinary = "/app/opera"
+
+	// RebroadcastIntervalEnv is an optional environment variable
+	// with the duration (e.g. "1m") after which transactions submitted
+	// through /construction/submit and still pending are broadcast
+	// again. When not set, transactions are never rebroadcast.
+	RebroadcastIntervalEnv = "REBROADCAST_INTERVAL"
+
+	// DataDirEnv is an optional environment variable with
+	// the Opera datadir prepared before Opera is started.
+	DataDirEnv = "DATA_DIR"
+
+	// DefaultDataDir is the default Opera datadir. This
+	// is used when DataDirEnv is not populated.
+	DefaultDataDir = "/data"
+
+	// GenesisEnv is an optional environment variable with the
+	// name of the genesis file (or the absolute path of a locally
+	// provided one). Setting it to an empty value disables the
+	// genesis file of the network.
+	GenesisEnv = "GENESIS"
+
+	// GenesisChecksumEnv is an optional environment variable
+	// with the checksum (e.g. "sha256:<hex>") of the genesis file.
+	GenesisChecksumEnv = "GENESIS_CHECKSUM"
+
+	// SnapshotEnv is an optional environment variable with the
+	// name of the snapshot archive (or the absolute path of a locally
+	// provided one). Setting it to an empty value disables the
+	// snapshot of the network.
+	SnapshotEnv = "SNAPSHOT"
+
+	// SnapshotChecksumEnv is an optional environment variable
+	// with the checksum (e.g. "sha256:<hex>") of the snapshot archive.
+	SnapshotChecksumEnv = "SNAPSHOT_CHECKSUM"
+
+	// DownloadBaseURLEnv is an optional environment variable
+	// with the URL of a mirror to download the genesis file
+	// and the snapshot archive from.
+	DownloadBaseURLEnv = "DOWNLOAD_BASE_URL"
+
+	// IncludeZeroValueCallsEnv is an optional environment
+	// variable to include the zero-value call frames of traces as
+	// operations by default (see fantom.BlockOptions). Requests to
+	// /block and /block/transaction can override it with the
+	// "include_zero_value_calls" metadata. When not set, defaults
+	// to false.
+	IncludeZeroValueCallsEnv = "INCLUDE_ZERO_VALUE_CALLS"
+
+	// MetadataProfileEnv is an optional environment variable
+	// with the default fantom.MetadataProfile of transactions
+	// (FULL, COMPACT or NONE). Requests to /block and
+	// /block/transaction can override it with the
+	// "metadata_profile" metadata. When not set, defaults
+	// to FULL.
+	MetadataProfileEnv = "METADATA_PROFILE"
+
+	// IncludeWFTMOperationsEnv is an optional environment
+	// variable to include the operations of wrapped FTM deposits
+	// and withdrawals by default (see fantom.BlockOptions).
+	// Requests to /block and /block/transaction can override it
+	// with the "include_wftm_operations" metadata. When not set,
+	// defaults to false.
+	IncludeWFTMOperationsEnv = "INCLUDE_WFTM_OPERATIONS"
+
+	// IncludeNFTOperationsEnv is an optional environment
+	// variable to include the operations of ERC-721 and ERC-1155
+	// token transfers by default (see fantom.BlockOptions).
+	// Requests to /block and /block/transaction can override it
+	// with the "include_nft_operations" metadata. When not set,
+	// defaults to false.
+	IncludeNFTOperationsEnv = "INCLUDE_NFT_OPERATIONS"
+
+	// BlockStreamEnv is an optional environment variable to
+	// serve the Server-Sent Events stream of new blocks at
+	// /block/stream in online mode. When not set, defaults
+	// to false.
+	BlockStreamEnv = "BLOCK_STREAM"
+
+	// ABIDirEnv is an optional environment variable with the
+	// path of a directory of JSON ABIs. When set, logs are decoded
+	// in the FULL metadata of transactions with its events, in
+	// addition to the events of ERC-20, ERC-721 and ERC-1155
+	// tokens, the SFC and WFTM.
+	ABIDirEnv = "ABI_DIR"
+
+	// ReconcileAccountsEnv is an optional environment variable
+	// with the path of a file listing the accounts reconciled in
+	// the background, in the format of the exempt accounts of
+	// rosetta-cli. Background reconciliation is disabled if it
+	// is not populated.
+	ReconcileAccountsEnv = "RECONCILE_ACCOUNTS"
+
+	// ReconcileExemptAccountsEnv is an optional environment
+	// variable with the path of a file listing the accounts whose
+	// reconciliation mismatches are not alerted on.
+	ReconcileExemptAccountsEnv = "RECONCILE_EXEMPT_ACCOUNTS"
+
+	// ReconcileAlertURLEnv is an optional environment variable
+	// with a URL receiving reconciliation mismatches as JSON
+	// POST requests.
+	ReconcileAlertURLEnv = "RECONCILE_ALERT_URL"
+
+	// ReconcileCheckIntervalEnv is an optional environment
+	// variable with the number of blocks between balance
+	// comparisons of the background reconciliation.
+	ReconcileCheckIntervalEnv = "RECONCILE_CHECK_INTERVAL"
+
+	// APIClientsEnv is an optional environment variable with the
+	// path of a JSON file listing the clients allowed to call the
+	// Rosetta API. Authentication is disabled if it is not populated.
+	APIClientsEnv = "API_CLIENTS"
+
+	// APIKeyHeaderEnv is an optional environment variable with
+	// the header carrying the API key of clients.
+	APIKeyHeaderEnv = "API_KEY_HEADER"
+
+	// DefaultAPIKeyHeader is the header carrying the API key
+	// of clients when APIKeyHeaderEnv is not populated.
+	DefaultAPIKeyHeader = "X-API-Key"
+
+	// RateLimitEnv is an optional environment variable with
+	// the default rate limit of the cheap endpoints of each
+	// client, as "<requests per second>[:<burst>]".
+	RateLimitEnv = "RATE_LIMIT"
+
+	// ExpensiveRateLimitEnv is an optional environment variable
+	// with the default rate limit of the expensive endpoints
+	// (/block and /call) of each client, as
+	// "<requests per second>[:<burst>]".
+	ExpensiveRateLimitEnv = "EXPENSIVE_RATE_LIMIT"
+
+	// CORSOriginsEnv is an optional environment variable with the
+	// comma-separated origins allowed to call the Rosetta API from
+	// browsers. All origins are allowed if it is not populated.
+	CORSOriginsEnv = "CORS_ORIGINS"
+
+	// ListenAddressEnv is an optional environment variable with
+	// the host or IP address the Rosetta API listens on (e.g.
+	// "127.0.0.1"). All interfaces are used if it is not populated.
+	ListenAddressEnv = "LISTEN_ADDRESS"
+
+	// TLSCertEnv is an optional environment variable with the
+	// path of the PEM certificate served by the Rosetta API.
+	// TLS is disabled if it is not populated. The certificate
+	// is reloaded when the file changes.
+	TLSCertEnv = "TLS_CERT"
+
+	// TLSKeyEnv is an environment variable with the path of
+	// the PEM private key of TLSCertEnv.
+	TLSKeyEnv = "TLS_KEY"
+
+	// TLSClientCAEnv is an optional environment variable with
+	// the path of the PEM certificates of the authorities issuing
+	// the client certificates authenticating clients (mTLS).
+	TLSClientCAEnv = "TLS_CLIENT_CA"
+
+	// TLSClientAuthEnv is an optional environment variable
+	// determining if client certificates are required when
+	// TLSClientCAEnv is populated. Options: OPTIONAL (default)
+	// or REQUIRED.
+	TLSClientAuthEnv = "TLS_CLIENT_AUTH"
+
 	// MiddlewareVersion is the version of rosetta-fantom.
 	MiddlewareVersion = "0.0.4"
 )
@@ -89,8 +277,26 @@ type Configuration struct {
 	RemoteOpera            bool
 	Port                   int
 	OperaArguments         string
+	OperaBinary            string
 	SkipAdmin              bool
 	ChainID                *big.Int
+	RebroadcastInterval    time.Duration
+	ListenAddress          string
+	TLSCert                string
+	TLSKey                 string
+	TLSClientCA            string
+	TLSClientAuth          TLSClientAuth
+	IncludeZeroValueCalls  bool
+	MetadataProfile        fantom.MetadataProfile
+	IncludeWFTMOperations  bool
+	IncludeNFTOperations   bool
+	BlockStream            bool
+	ABIDir                 string
+}
+
+// Addr returns the address the Rosetta API listens on.
+func (c *Configuration) Addr() string {
+	return net.JoinHostPort(c.ListenAddress, strconv.Itoa(c.Port))
 }
 
 // LoadConfiguration attempts to create a new Configuration
@@ -144,6 +350,12 @@ func LoadConfiguration() (*Configuration, error) {
 		return nil, errors.New("OPERA_ARGS must be populated")
 	}
 
+	config.OperaBinary = DefaultOperaBinary
+	envOperaBinary := os.Getenv(OperaBinaryEnv)
+	if len(envOperaBinary) > 0 {
+		config.OperaBinary = envOperaBinary
+	}
+
 	config.SkipAdmin = false
 	envSkipAdmin := os.Getenv(SkipAdminEnv)
 	if len(envSkipAdmin) > 0 {
@@ -154,6 +366,81 @@ func LoadConfiguration() (*Configuration, error) {
 		config.SkipAdmin = val
 	}
 
+	envIncludeZeroValueCalls := os.Getenv(IncludeZeroValueCallsEnv)
+	if len(envIncludeZeroValueCalls) > 0 {
+		val, err := strconv.ParseBool(envIncludeZeroValueCalls)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to parse INCLUDE_ZERO_VALUE_CALLS %s",
+				err,
+				envIncludeZeroValueCalls,
+			)
+		}
+		config.IncludeZeroValueCalls = val
+	}
+
+	envIncludeWFTMOperations := os.Getenv(IncludeWFTMOperationsEnv)
+	if len(envIncludeWFTMOperations) > 0 {
+		val, err := strconv.ParseBool(envIncludeWFTMOperations)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to parse INCLUDE_WFTM_OPERATIONS %s",
+				err,
+				envIncludeWFTMOperations,
+			)
+		}
+		config.IncludeWFTMOperations = val
+	}
+
+	envIncludeNFTOperations := os.Getenv(IncludeNFTOperationsEnv)
+	if len(envIncludeNFTOperations) > 0 {
+		val, err := strconv.ParseBool(envIncludeNFTOperations)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to parse INCLUDE_NFT_OPERATIONS %s",
+				err,
+				envIncludeNFTOperations,
+			)
+		}
+		config.IncludeNFTOperations = val
+	}
+
+	envBlockStream := os.Getenv(BlockStreamEnv)
+	if len(envBlockStream) > 0 {
+		val, err := strconv.ParseBool(envBlockStream)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse BLOCK_STREAM %s", err, envBlockStream)
+		}
+		config.BlockStream = val
+	}
+
+	config.ABIDir = os.Getenv(ABIDirEnv)
+
+	envMetadataProfile := os.Getenv(MetadataProfileEnv)
+	if len(envMetadataProfile) > 0 {
+		val, err := fantom.ParseMetadataProfile(envMetadataProfile)
+		if err != nil {
+			return nil, err
+		}
+		config.MetadataProfile = val
+	}
+
+	envRebroadcastInterval := os.Getenv(RebroadcastIntervalEnv)
+	if len(envRebroadcastInterval) > 0 {
+		val, err := time.ParseDuration(envRebroadcastInterval)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to parse REBROADCAST_INTERVAL %s",
+				err,
+				envRebroadcastInterval,
+			)
+		}
+		if val < 0 {
+			return nil, errors.New("REBROADCAST_INTERVAL must not be negative")
+		}
+		config.RebroadcastInterval = val
+	}
+
 	portValue := os.Getenv(PortEnv)
 	if len(portValue) == 0 {
 		return nil, errors.New("PORT must be populated")
@@ -165,5 +452,317 @@ func LoadConfiguration() (*Configuration, error) {
 	}
 	config.Port = port
 
+	config.ListenAddress = os.Getenv(ListenAddressEnv)
+	if _, _, err := net.SplitHostPort(config.ListenAddress); err == nil {
+		return nil, fmt.Errorf("LISTEN_ADDRESS %s must not include a port", config.ListenAddress)
+	}
+
+	config.TLSCert = os.Getenv(TLSCertEnv)
+	config.TLSKey = os.Getenv(TLSKeyEnv)
+	if (len(config.TLSCert) == 0) != (len(config.TLSKey) == 0) {
+		return nil, errors.New("TLS_CERT and TLS_KEY must be populated together")
+	}
+
+	config.TLSClientCA = os.Getenv(TLSClientCAEnv)
+	if len(config.TLSClientCA) > 0 {
+		if len(config.TLSCert) == 0 {
+			return nil, errors.New("TLS_CLIENT_CA requires TLS_CERT")
+		}
+		config.TLSClientAuth = TLSClientAuthOptional
+	}
+
+	clientAuthValue := TLSClientAuth(os.Getenv(TLSClientAuthEnv))
+	switch clientAuthValue {
+	case "":
+	case TLSClientAuthOptional, TLSClientAuthRequired:
+		if len(config.TLSClientCA) == 0 {
+			return nil, errors.New("TLS_CLIENT_AUTH requires TLS_CLIENT_CA")
+		}
+		config.TLSClientAuth = clientAuthValue
+	default:
+		return nil, fmt.Errorf("%s is not a valid TLS client auth", clientAuthValue)
+	}
+
+	return config, nil
+}
+
+// networkDatadirFiles are the default genesis files and
+// snapshot archives of each network.
+var networkDatadirFiles = map[string]struct {
+	genesis  fantom.DatadirFile
+	snapshot fantom.DatadirFile
+}{
+	Mainnet: {
+		snapshot: fantom.DatadirFile{
+			Name:     "opera_5may22.tgz",
+			Checksum: "md5:3981d701e47ec9fd8a71dbbdc01f1cde",
+		},
+	},
+	Testnet: {
+		genesis: fantom.DatadirFile{
+			Name:     "testnet.g",
+			Checksum: "sha1:ba37d578249da67cb5744069cc54f49a6938030d",
+		},
+	},
+}
+
+// datadirFile returns the file configured with the nameEnv
+// and checksumEnv environment variables, falling back
+// to the default file of the network.
+func datadirFile(
+	defaultFile fantom.DatadirFile,
+	nameEnv string,
+	checksumEnv string,
+	baseURL string,
+) *fantom.DatadirFile {
+	file := defaultFile
+	if name, ok := os.LookupEnv(nameEnv); ok {
+		file = fantom.DatadirFile{Name: name}
+	}
+	if checksum := os.Getenv(checksumEnv); len(checksum) > 0 {
+		file.Checksum = checksum
+	}
+	if envBaseURL := os.Getenv(DownloadBaseURLEnv); len(envBaseURL) > 0 {
+		baseURL = envBaseURL
+	}
+	file.BaseURL = baseURL
+
+	if len(file.Name) == 0 {
+		return nil
+	}
+
+	return &file
+}
+
+// LoadDatadirConfiguration attempts to create a new
+// fantom.DatadirConfig using the ENVs in the environment.
+func LoadDatadirConfiguration() (*fantom.DatadirConfig, error) {
+	networkValue := os.Getenv(NetworkEnv)
+	files, ok := networkDatadirFiles[networkValue]
+	if !ok {
+		if len(networkValue) == 0 {
+			return nil, errors.New("NETWORK must be populated")
+		}
+		return nil, fmt.Errorf("%s is not a valid network", networkValue)
+	}
+
+	config := &fantom.DatadirConfig{
+		Dir: DefaultDataDir,
+		Genesis: datadirFile(
+			files.genesis,
+			GenesisEnv,
+			GenesisChecksumEnv,
+			fantom.DefaultGenesisBaseURL,
+		),
+		Snapshot: datadirFile(
+			files.snapshot,
+			SnapshotEnv,
+			SnapshotChecksumEnv,
+			fantom.DefaultSnapshotBaseURL,
+		),
+	}
+	if envDataDir := os.Getenv(DataDirEnv); len(envDataDir) > 0 {
+		config.Dir = envDataDir
+	}
+
+	return config, nil
+}
+
+// LoadReconcileConfiguration attempts to create a new
+// fantom.ReconcileConfig using the ENVs in the environment.
+// It returns nil if background reconciliation is disabled.
+func LoadReconcileConfiguration() (*fantom.ReconcileConfig, error) {
+	accountsFile := os.Getenv(ReconcileAccountsEnv)
+	if len(accountsFile) == 0 {
+		return nil, nil
+	}
+
+	accounts, err := fantom.LoadAccountCurrencies(accountsFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to load RECONCILE_ACCOUNTS", err)
+	}
+
+	config := &fantom.ReconcileConfig{
+		Accounts: accounts,
+		AlertURL: os.Getenv(ReconcileAlertURLEnv),
+	}
+
+	if exemptFile := os.Getenv(ReconcileExemptAccountsEnv); len(exemptFile) > 0 {
+		exempt, err := fantom.LoadAccountCurrencies(exemptFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to load RECONCILE_EXEMPT_ACCOUNTS", err)
+		}
+		config.Exempt = exempt
+	}
+
+	envCheckInterval := os.Getenv(ReconcileCheckIntervalEnv)
+	if len(envCheckInterval) > 0 {
+		val, err := strconv.ParseInt(envCheckInterval, 10, 64)
+		if err != nil || val <= 0 {
+			return nil, fmt.Errorf(
+				"%w: unable to parse RECONCILE_CHECK_INTERVAL %s",
+				err,
+				envCheckInterval,
+			)
+		}
+		config.CheckInterval = val
+	}
+
+	return config, nil
+}
+
+// RateLimit is a token bucket rate limit.
+type RateLimit struct {
+	// Rate is the number of requests per second.
+	Rate float64 `json:"rate"`
+
+	// Burst is the number of requests allowed at once.
+	Burst int `json:"burst"`
+}
+
+// APIClient is a client allowed to call the Rosetta API,
+// authenticated by its API key or by the common name of
+// its TLS client certificate.
+type APIClient struct {
+	Name       string `json:"name"`
+	Key        string `json:"key,omitempty"`
+	CommonName string `json:"common_name,omitempty"`
+
+	// RateLimit and ExpensiveRateLimit override the
+	// default rate limits for the client.
+	RateLimit          *RateLimit `json:"rate_limit,omitempty"`
+	ExpensiveRateLimit *RateLimit `json:"expensive_rate_limit,omitempty"`
+}
+
+// ServerConfiguration determines how the Rosetta API
+// is exposed.
+type ServerConfiguration struct {
+	// Clients are the clients allowed to call the Rosetta
+	// API. Any client is allowed if it is empty.
+	Clients      []*APIClient
+	APIKeyHeader string
+
+	// RateLimit and ExpensiveRateLimit are the default rate
+	// limits of each client. Requests are not rate limited
+	// if they are nil.
+	RateLimit          *RateLimit
+	ExpensiveRateLimit *RateLimit
+
+	CORSOrigins []string
+}
+
+// parseRateLimit parses a "<rate>[:<burst>]" rate limit. The
+// burst defaults to the rate, rounded up.
+func parseRateLimit(value string) (*RateLimit, error) {
+	parts := strings.SplitN(value, ":", 2)
+	rate, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return nil, err
+	}
+	if rate <= 0 {
+		return nil, errors.New("rate must be positive")
+	}
+
+	limit := &RateLimit{Rate: rate, Burst: int(math.Ceil(rate))}
+	if len(parts) == 2 {
+		burst, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		if burst <= 0 {
+			return nil, errors.New("burst must be positive")
+		}
+		limit.Burst = burst
+	}
+
+	return limit, nil
+}
+
+// loadAPIClients loads and validates the clients listed in path.
+// Clients identified only by a common name are rejected unless
+// clientCA is set, as their certificates cannot be verified.
+func loadAPIClients(path string, clientCA bool) ([]*APIClient, error) {
+	contents, err := ioutil.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+
+	var clients []*APIClient
+	if err := json.Unmarshal(contents, &clients); err != nil {
+		return nil, err
+	}
+	if len(clients) == 0 {
+		return nil, errors.New("no clients listed")
+	}
+
+	keys := map[string]struct{}{}
+	for _, client := range clients {
+		if len(client.Name) == 0 {
+			return nil, errors.New("client name missing")
+		}
+		if len(client.Key) == 0 && len(client.CommonName) == 0 {
+			return nil, fmt.Errorf("client %s has neither a key nor a common name", client.Name)
+		}
+		if len(client.Key) == 0 && !clientCA {
+			return nil, fmt.Errorf("client %s has only a common name but TLS_CLIENT_CA is not populated", client.Name)
+		}
+		if len(client.Key) > 0 {
+			if _, ok := keys[client.Key]; ok {
+				return nil, fmt.Errorf("key of client %s is not unique", client.Name)
+			}
+			keys[client.Key] = struct{}{}
+		}
+
+		for _, limit := range []*RateLimit{client.RateLimit, client.ExpensiveRateLimit} {
+			if limit != nil && (limit.Rate <= 0 || limit.Burst <= 0) {
+				return nil, fmt.Errorf("invalid rate limit of client %s", client.Name)
+			}
+		}
+	}
+
+	return clients, nil
+}
+
+// LoadServerConfiguration attempts to create a new
+// ServerConfiguration using the ENVs in the environment.
+func LoadServerConfiguration() (*ServerConfiguration, error) {
+	config := &ServerConfiguration{APIKeyHeader: DefaultAPIKeyHeader}
+
+	if clientsFile := os.Getenv(APIClientsEnv); len(clientsFile) > 0 {
+		clients, err := loadAPIClients(clientsFile, len(os.Getenv(TLSClientCAEnv)) > 0)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to load API_CLIENTS", err)
+		}
+		config.Clients = clients
+	}
+
+	if header := os.Getenv(APIKeyHeaderEnv); len(header) > 0 {
+		config.APIKeyHeader = header
+	}
+
+	if envRateLimit := os.Getenv(RateLimitEnv); len(envRateLimit) > 0 {
+		limit, err := parseRateLimit(envRateLimit)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse RATE_LIMIT %s", err, envRateLimit)
+		}
+		config.RateLimit = limit
+	}
+
+	if envRateLimit := os.Getenv(ExpensiveRateLimitEnv); len(envRateLimit) > 0 {
+		limit, err := parseRateLimit(envRateLimit)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse EXPENSIVE_RATE_LIMIT %s", err, envRateLimit)
+		}
+		config.ExpensiveRateLimit = limit
+	}
+
+	if envOrigins := os.Getenv(CORSOriginsEnv); len(envOrigins) > 0 {
+		for _, origin := range strings.Split(envOrigins, ",") {
+			if origin = strings.TrimSpace(origin); len(origin) > 0 {
+				config.CORSOrigins = append(config.CORSOrigins, origin)
+			}
+		}
+	}
+
 	return config, nil
 }
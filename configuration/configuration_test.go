@@ -17,9 +17,12 @@ package configuration
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"math/big"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/Fantom-foundation/rosetta-fantom/fantom"
 
@@ -36,6 +39,21 @@ func TestLoadConfiguration(t *testing.T) {
 		SkipAdmin string
 		OperaArgs string
 
+		OperaBinary           string
+		RebroadcastInterval   string
+		IncludeZeroValueCalls string
+		MetadataProfile       string
+		IncludeWFTMOperations string
+		IncludeNFTOperations  string
+		BlockStream           string
+		ABIDir                string
+
+		ListenAddress string
+		TLSCert       string
+		TLSKey        string
+		TLSClientCA   string
+		TLSClientAuth string
+
 		cfg *Configuration
 		err error
 	}{
@@ -68,6 +86,7 @@ func TestLoadConfiguration(t *testing.T) {
 				Port:                   1000,
 				OperaURL:               DefaultOperaURL,
 				OperaArguments:         "--",
+				OperaBinary:            DefaultOperaBinary,
 				SkipAdmin:              false,
 				ChainID:                big.NewInt(0xFA),
 			},
@@ -90,6 +109,7 @@ func TestLoadConfiguration(t *testing.T) {
 				OperaURL:               "http://blah",
 				RemoteOpera:            true,
 				OperaArguments:         "--",
+				OperaBinary:            DefaultOperaBinary,
 				SkipAdmin:              true,
 				ChainID:                big.NewInt(0xFA),
 			},
@@ -110,10 +130,334 @@ func TestLoadConfiguration(t *testing.T) {
 				Port:                   1000,
 				OperaURL:               DefaultOperaURL,
 				OperaArguments:         "--",
+				OperaBinary:            DefaultOperaBinary,
 				SkipAdmin:              true,
 				ChainID:                big.NewInt(0xFA2),
 			},
 		},
+		"all set (testnet) + rebroadcast": {
+			Mode:                string(Online),
+			Network:             Testnet,
+			Port:                "1000",
+			OperaArgs:           "--",
+			RebroadcastInterval: "90s",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    fantom.TestnetNetwork,
+					Blockchain: fantom.Blockchain,
+				},
+				GenesisBlockIdentifier: fantom.FantomTestnetGenesisBlockIdentifier,
+				Port:                   1000,
+				OperaURL:               DefaultOperaURL,
+				OperaArguments:         "--",
+				OperaBinary:            DefaultOperaBinary,
+				ChainID:                big.NewInt(0xFA2),
+				RebroadcastInterval:    90 * time.Second,
+			},
+		},
+		"all set (testnet) + opera binary": {
+			Mode:        string(Online),
+			Network:     Testnet,
+			Port:        "1000",
+			OperaArgs:   "--",
+			OperaBinary: "/usr/local/bin/opera",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    fantom.TestnetNetwork,
+					Blockchain: fantom.Blockchain,
+				},
+				GenesisBlockIdentifier: fantom.FantomTestnetGenesisBlockIdentifier,
+				Port:                   1000,
+				OperaURL:               DefaultOperaURL,
+				OperaArguments:         "--",
+				OperaBinary:            "/usr/local/bin/opera",
+				ChainID:                big.NewInt(0xFA2),
+			},
+		},
+		"all set (testnet) + listen address + tls": {
+			Mode:          string(Online),
+			Network:       Testnet,
+			Port:          "1000",
+			OperaArgs:     "--",
+			ListenAddress: "127.0.0.1",
+			TLSCert:       "cert.pem",
+			TLSKey:        "key.pem",
+			TLSClientCA:   "ca.pem",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    fantom.TestnetNetwork,
+					Blockchain: fantom.Blockchain,
+				},
+				GenesisBlockIdentifier: fantom.FantomTestnetGenesisBlockIdentifier,
+				Port:                   1000,
+				OperaURL:               DefaultOperaURL,
+				OperaArguments:         "--",
+				OperaBinary:            DefaultOperaBinary,
+				ChainID:                big.NewInt(0xFA2),
+				ListenAddress:          "127.0.0.1",
+				TLSCert:                "cert.pem",
+				TLSKey:                 "key.pem",
+				TLSClientCA:            "ca.pem",
+				TLSClientAuth:          TLSClientAuthOptional,
+			},
+		},
+		"all set (testnet) + required client certificates": {
+			Mode:          string(Online),
+			Network:       Testnet,
+			Port:          "1000",
+			OperaArgs:     "--",
+			TLSCert:       "cert.pem",
+			TLSKey:        "key.pem",
+			TLSClientCA:   "ca.pem",
+			TLSClientAuth: string(TLSClientAuthRequired),
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    fantom.TestnetNetwork,
+					Blockchain: fantom.Blockchain,
+				},
+				GenesisBlockIdentifier: fantom.FantomTestnetGenesisBlockIdentifier,
+				Port:                   1000,
+				OperaURL:               DefaultOperaURL,
+				OperaArguments:         "--",
+				OperaBinary:            DefaultOperaBinary,
+				ChainID:                big.NewInt(0xFA2),
+				TLSCert:                "cert.pem",
+				TLSKey:                 "key.pem",
+				TLSClientCA:            "ca.pem",
+				TLSClientAuth:          TLSClientAuthRequired,
+			},
+		},
+		"listen address with port": {
+			Mode:          string(Online),
+			Network:       Testnet,
+			Port:          "1000",
+			OperaArgs:     "--",
+			ListenAddress: "localhost:1000",
+			err:           errors.New("LISTEN_ADDRESS localhost:1000 must not include a port"),
+		},
+		"tls cert without key": {
+			Mode:      string(Online),
+			Network:   Testnet,
+			Port:      "1000",
+			OperaArgs: "--",
+			TLSCert:   "cert.pem",
+			err:       errors.New("TLS_CERT and TLS_KEY must be populated together"),
+		},
+		"client ca without tls": {
+			Mode:        string(Online),
+			Network:     Testnet,
+			Port:        "1000",
+			OperaArgs:   "--",
+			TLSClientCA: "ca.pem",
+			err:         errors.New("TLS_CLIENT_CA requires TLS_CERT"),
+		},
+		"client auth without client ca": {
+			Mode:          string(Online),
+			Network:       Testnet,
+			Port:          "1000",
+			OperaArgs:     "--",
+			TLSCert:       "cert.pem",
+			TLSKey:        "key.pem",
+			TLSClientAuth: string(TLSClientAuthRequired),
+			err:           errors.New("TLS_CLIENT_AUTH requires TLS_CLIENT_CA"),
+		},
+		"invalid client auth": {
+			Mode:          string(Online),
+			Network:       Testnet,
+			Port:          "1000",
+			OperaArgs:     "--",
+			TLSCert:       "cert.pem",
+			TLSKey:        "key.pem",
+			TLSClientCA:   "ca.pem",
+			TLSClientAuth: "SOMETIMES",
+			err:           errors.New("SOMETIMES is not a valid TLS client auth"),
+		},
+		"all set (testnet) + zero-value calls": {
+			Mode:                  string(Online),
+			Network:               Testnet,
+			Port:                  "1000",
+			OperaArgs:             "--",
+			IncludeZeroValueCalls: "true",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    fantom.TestnetNetwork,
+					Blockchain: fantom.Blockchain,
+				},
+				GenesisBlockIdentifier: fantom.FantomTestnetGenesisBlockIdentifier,
+				Port:                   1000,
+				OperaURL:               DefaultOperaURL,
+				OperaArguments:         "--",
+				OperaBinary:            DefaultOperaBinary,
+				ChainID:                big.NewInt(0xFA2),
+				IncludeZeroValueCalls:  true,
+			},
+		},
+		"invalid zero-value calls": {
+			Mode:                  string(Online),
+			Network:               Testnet,
+			Port:                  "1000",
+			OperaArgs:             "--",
+			IncludeZeroValueCalls: "sometimes",
+			err:                   errors.New("unable to parse INCLUDE_ZERO_VALUE_CALLS sometimes"),
+		},
+		"all set (testnet) + compact metadata": {
+			Mode:            string(Online),
+			Network:         Testnet,
+			Port:            "1000",
+			OperaArgs:       "--",
+			MetadataProfile: "COMPACT",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    fantom.TestnetNetwork,
+					Blockchain: fantom.Blockchain,
+				},
+				GenesisBlockIdentifier: fantom.FantomTestnetGenesisBlockIdentifier,
+				Port:                   1000,
+				OperaURL:               DefaultOperaURL,
+				OperaArguments:         "--",
+				OperaBinary:            DefaultOperaBinary,
+				ChainID:                big.NewInt(0xFA2),
+				MetadataProfile:        fantom.MetadataProfileCompact,
+			},
+		},
+		"all set (testnet) + wftm operations": {
+			Mode:                  string(Online),
+			Network:               Testnet,
+			Port:                  "1000",
+			OperaArgs:             "--",
+			IncludeWFTMOperations: "true",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    fantom.TestnetNetwork,
+					Blockchain: fantom.Blockchain,
+				},
+				GenesisBlockIdentifier: fantom.FantomTestnetGenesisBlockIdentifier,
+				Port:                   1000,
+				OperaURL:               DefaultOperaURL,
+				OperaArguments:         "--",
+				OperaBinary:            DefaultOperaBinary,
+				ChainID:                big.NewInt(0xFA2),
+				IncludeWFTMOperations:  true,
+			},
+		},
+		"all set (testnet) + nft operations": {
+			Mode:                 string(Online),
+			Network:              Testnet,
+			Port:                 "1000",
+			OperaArgs:            "--",
+			IncludeNFTOperations: "true",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    fantom.TestnetNetwork,
+					Blockchain: fantom.Blockchain,
+				},
+				GenesisBlockIdentifier: fantom.FantomTestnetGenesisBlockIdentifier,
+				Port:                   1000,
+				OperaURL:               DefaultOperaURL,
+				OperaArguments:         "--",
+				OperaBinary:            DefaultOperaBinary,
+				ChainID:                big.NewInt(0xFA2),
+				IncludeNFTOperations:   true,
+			},
+		},
+		"all set (testnet) + block stream": {
+			Mode:        string(Online),
+			Network:     Testnet,
+			Port:        "1000",
+			OperaArgs:   "--",
+			BlockStream: "true",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    fantom.TestnetNetwork,
+					Blockchain: fantom.Blockchain,
+				},
+				GenesisBlockIdentifier: fantom.FantomTestnetGenesisBlockIdentifier,
+				Port:                   1000,
+				OperaURL:               DefaultOperaURL,
+				OperaArguments:         "--",
+				OperaBinary:            DefaultOperaBinary,
+				ChainID:                big.NewInt(0xFA2),
+				BlockStream:            true,
+			},
+		},
+		"all set (testnet) + abi dir": {
+			Mode:      string(Online),
+			Network:   Testnet,
+			Port:      "1000",
+			OperaArgs: "--",
+			ABIDir:    "/abis",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    fantom.TestnetNetwork,
+					Blockchain: fantom.Blockchain,
+				},
+				GenesisBlockIdentifier: fantom.FantomTestnetGenesisBlockIdentifier,
+				Port:                   1000,
+				OperaURL:               DefaultOperaURL,
+				OperaArguments:         "--",
+				OperaBinary:            DefaultOperaBinary,
+				ChainID:                big.NewInt(0xFA2),
+				ABIDir:                 "/abis",
+			},
+		},
+		"invalid wftm operations": {
+			Mode:                  string(Online),
+			Network:               Testnet,
+			Port:                  "1000",
+			OperaArgs:             "--",
+			IncludeWFTMOperations: "maybe",
+			err:                   errors.New("unable to parse INCLUDE_WFTM_OPERATIONS maybe"),
+		},
+		"invalid block stream": {
+			Mode:        string(Online),
+			Network:     Testnet,
+			Port:        "1000",
+			OperaArgs:   "--",
+			BlockStream: "maybe",
+			err:         errors.New("unable to parse BLOCK_STREAM maybe"),
+		},
+		"invalid nft operations": {
+			Mode:                 string(Online),
+			Network:              Testnet,
+			Port:                 "1000",
+			OperaArgs:            "--",
+			IncludeNFTOperations: "maybe",
+			err:                  errors.New("unable to parse INCLUDE_NFT_OPERATIONS maybe"),
+		},
+		"invalid metadata profile": {
+			Mode:            string(Online),
+			Network:         Testnet,
+			Port:            "1000",
+			OperaArgs:       "--",
+			MetadataProfile: "tiny",
+			err:             errors.New("tiny is not a valid metadata profile"),
+		},
+		"invalid rebroadcast interval": {
+			Mode:                string(Online),
+			Network:             Testnet,
+			Port:                "1000",
+			OperaArgs:           "--",
+			RebroadcastInterval: "often",
+			err:                 errors.New("unable to parse REBROADCAST_INTERVAL often"),
+		},
+		"negative rebroadcast interval": {
+			Mode:                string(Online),
+			Network:             Testnet,
+			Port:                "1000",
+			OperaArgs:           "--",
+			RebroadcastInterval: "-1m",
+			err:                 errors.New("REBROADCAST_INTERVAL must not be negative"),
+		},
 		"invalid mode": {
 			Mode:    "bad mode",
 			Network: Testnet,
@@ -143,6 +487,19 @@ func TestLoadConfiguration(t *testing.T) {
 			os.Setenv(OperaEnv, test.Opera)
 			os.Setenv(SkipAdminEnv, test.SkipAdmin)
 			os.Setenv(OperaArgsEnv, test.OperaArgs)
+			os.Setenv(OperaBinaryEnv, test.OperaBinary)
+			os.Setenv(RebroadcastIntervalEnv, test.RebroadcastInterval)
+			os.Setenv(IncludeZeroValueCallsEnv, test.IncludeZeroValueCalls)
+			os.Setenv(MetadataProfileEnv, test.MetadataProfile)
+			os.Setenv(IncludeWFTMOperationsEnv, test.IncludeWFTMOperations)
+			os.Setenv(IncludeNFTOperationsEnv, test.IncludeNFTOperations)
+			os.Setenv(BlockStreamEnv, test.BlockStream)
+			os.Setenv(ABIDirEnv, test.ABIDir)
+			os.Setenv(ListenAddressEnv, test.ListenAddress)
+			os.Setenv(TLSCertEnv, test.TLSCert)
+			os.Setenv(TLSKeyEnv, test.TLSKey)
+			os.Setenv(TLSClientCAEnv, test.TLSClientCA)
+			os.Setenv(TLSClientAuthEnv, test.TLSClientAuth)
 
 			cfg, err := LoadConfiguration()
 			if test.err != nil {
@@ -156,3 +513,266 @@ func TestLoadConfiguration(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadDatadirConfiguration(t *testing.T) {
+	tests := map[string]struct {
+		Network          string
+		DataDir          *string
+		Genesis          *string
+		GenesisChecksum  string
+		Snapshot         *string
+		SnapshotChecksum string
+		DownloadBaseURL  string
+
+		cfg *fantom.DatadirConfig
+		err error
+	}{
+		"no network": {
+			err: errors.New("NETWORK must be populated"),
+		},
+		"invalid network": {
+			Network: "bad network",
+			err:     errors.New("bad network is not a valid network"),
+		},
+		"mainnet defaults": {
+			Network: Mainnet,
+			cfg: &fantom.DatadirConfig{
+				Dir: DefaultDataDir,
+				Snapshot: &fantom.DatadirFile{
+					Name:     "opera_5may22.tgz",
+					Checksum: "md5:3981d701e47ec9fd8a71dbbdc01f1cde",
+					BaseURL:  fantom.DefaultSnapshotBaseURL,
+				},
+			},
+		},
+		"testnet defaults": {
+			Network: Testnet,
+			cfg: &fantom.DatadirConfig{
+				Dir: DefaultDataDir,
+				Genesis: &fantom.DatadirFile{
+					Name:     "testnet.g",
+					Checksum: "sha1:ba37d578249da67cb5744069cc54f49a6938030d",
+					BaseURL:  fantom.DefaultGenesisBaseURL,
+				},
+			},
+		},
+		"mainnet overrides": {
+			Network:          Mainnet,
+			DataDir:          strPtr("/var/opera"),
+			Genesis:          strPtr("/tmp/mainnet.g"),
+			GenesisChecksum:  "sha256:1234",
+			Snapshot:         strPtr(""),
+			SnapshotChecksum: "sha256:5678",
+			DownloadBaseURL:  "https://mirror.example.com",
+			cfg: &fantom.DatadirConfig{
+				Dir: "/var/opera",
+				Genesis: &fantom.DatadirFile{
+					Name:     "/tmp/mainnet.g",
+					Checksum: "sha256:1234",
+					BaseURL:  "https://mirror.example.com",
+				},
+			},
+		},
+	}
+
+	setEnv := func(key string, value *string) {
+		if value == nil {
+			os.Unsetenv(key)
+			return
+		}
+		os.Setenv(key, *value)
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			os.Setenv(NetworkEnv, test.Network)
+			setEnv(DataDirEnv, test.DataDir)
+			setEnv(GenesisEnv, test.Genesis)
+			os.Setenv(GenesisChecksumEnv, test.GenesisChecksum)
+			setEnv(SnapshotEnv, test.Snapshot)
+			os.Setenv(SnapshotChecksumEnv, test.SnapshotChecksum)
+			os.Setenv(DownloadBaseURLEnv, test.DownloadBaseURL)
+
+			cfg, err := LoadDatadirConfiguration()
+			if test.err != nil {
+				assert.Nil(t, cfg)
+				assert.Contains(t, err.Error(), test.err.Error())
+			} else {
+				assert.Equal(t, test.cfg, cfg)
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestLoadReconcileConfiguration(t *testing.T) {
+	exemptAccounts := "../rosetta-cli-conf/mainnet/exempt_accounts.json"
+	accounts := []*types.AccountCurrency{
+		{
+			Account:  &types.AccountIdentifier{Address: "0xFC00FACE00000000000000000000000000000000"},
+			Currency: fantom.Currency,
+		},
+	}
+
+	tests := map[string]struct {
+		Accounts       string
+		ExemptAccounts string
+		AlertURL       string
+		CheckInterval  string
+
+		cfg *fantom.ReconcileConfig
+		err error
+	}{
+		"disabled": {},
+		"accounts only": {
+			Accounts: exemptAccounts,
+			cfg: &fantom.ReconcileConfig{
+				Accounts: accounts,
+			},
+		},
+		"all fields": {
+			Accounts:       exemptAccounts,
+			ExemptAccounts: exemptAccounts,
+			AlertURL:       "https://alerts.example.com",
+			CheckInterval:  "100",
+			cfg: &fantom.ReconcileConfig{
+				Accounts:      accounts,
+				Exempt:        accounts,
+				AlertURL:      "https://alerts.example.com",
+				CheckInterval: 100,
+			},
+		},
+		"missing accounts file": {
+			Accounts: "missing.json",
+			err:      errors.New("unable to load RECONCILE_ACCOUNTS"),
+		},
+		"invalid check interval": {
+			Accounts:      exemptAccounts,
+			CheckInterval: "0",
+			err:           errors.New("unable to parse RECONCILE_CHECK_INTERVAL 0"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			os.Setenv(ReconcileAccountsEnv, test.Accounts)
+			os.Setenv(ReconcileExemptAccountsEnv, test.ExemptAccounts)
+			os.Setenv(ReconcileAlertURLEnv, test.AlertURL)
+			os.Setenv(ReconcileCheckIntervalEnv, test.CheckInterval)
+
+			cfg, err := LoadReconcileConfiguration()
+			if test.err != nil {
+				assert.Nil(t, cfg)
+				assert.Contains(t, err.Error(), test.err.Error())
+			} else {
+				assert.Equal(t, test.cfg, cfg)
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLoadServerConfiguration(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name string, contents string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+		return path
+	}
+
+	clients := writeFile("clients.json", `[
+		{"name": "explorer", "key": "explorer-key"},
+		{"name": "custody", "common_name": "custody.internal", "expensive_rate_limit": {"rate": 0.5, "burst": 2}}
+	]`)
+	duplicateKeys := writeFile("duplicate.json", `[
+		{"name": "a", "key": "key"},
+		{"name": "b", "key": "key"}
+	]`)
+	noCredentials := writeFile("no_credentials.json", `[{"name": "a"}]`)
+
+	tests := map[string]struct {
+		Clients            string
+		APIKeyHeader       string
+		RateLimit          string
+		ExpensiveRateLimit string
+		CORSOrigins        string
+		TLSClientCA        string
+
+		cfg *ServerConfiguration
+		err error
+	}{
+		"defaults": {
+			cfg: &ServerConfiguration{APIKeyHeader: DefaultAPIKeyHeader},
+		},
+		"all set": {
+			Clients:            clients,
+			APIKeyHeader:       "Authorization",
+			RateLimit:          "10",
+			ExpensiveRateLimit: "0.5:5",
+			CORSOrigins:        "https://a.example.com, https://b.example.com",
+			TLSClientCA:        "ca.pem",
+			cfg: &ServerConfiguration{
+				Clients: []*APIClient{
+					{Name: "explorer", Key: "explorer-key"},
+					{
+						Name:               "custody",
+						CommonName:         "custody.internal",
+						ExpensiveRateLimit: &RateLimit{Rate: 0.5, Burst: 2},
+					},
+				},
+				APIKeyHeader:       "Authorization",
+				RateLimit:          &RateLimit{Rate: 10, Burst: 10},
+				ExpensiveRateLimit: &RateLimit{Rate: 0.5, Burst: 5},
+				CORSOrigins:        []string{"https://a.example.com", "https://b.example.com"},
+			},
+		},
+		"missing clients file": {
+			Clients: filepath.Join(dir, "missing.json"),
+			err:     errors.New("unable to load API_CLIENTS"),
+		},
+		"duplicate keys": {
+			Clients: duplicateKeys,
+			err:     errors.New("key of client b is not unique"),
+		},
+		"no credentials": {
+			Clients: noCredentials,
+			err:     errors.New("client a has neither a key nor a common name"),
+		},
+		"common name without client ca": {
+			Clients: clients,
+			err:     errors.New("client custody has only a common name but TLS_CLIENT_CA is not populated"),
+		},
+		"invalid rate limit": {
+			RateLimit: "0",
+			err:       errors.New("unable to parse RATE_LIMIT 0"),
+		},
+		"invalid expensive burst": {
+			ExpensiveRateLimit: "1:x",
+			err:                errors.New("unable to parse EXPENSIVE_RATE_LIMIT 1:x"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			os.Setenv(APIClientsEnv, test.Clients)
+			os.Setenv(APIKeyHeaderEnv, test.APIKeyHeader)
+			os.Setenv(RateLimitEnv, test.RateLimit)
+			os.Setenv(ExpensiveRateLimitEnv, test.ExpensiveRateLimit)
+			os.Setenv(CORSOriginsEnv, test.CORSOrigins)
+			os.Setenv(TLSClientCAEnv, test.TLSClientCA)
+
+			cfg, err := LoadServerConfiguration()
+			if test.err != nil {
+				assert.Nil(t, cfg)
+				assert.Contains(t, err.Error(), test.err.Error())
+			} else {
+				assert.Equal(t, test.cfg, cfg)
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
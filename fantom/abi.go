@@ -0,0 +1,356 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// maxSafeJSONInteger is the largest integer a JSON number
+// can hold without losing precision.
+const maxSafeJSONInteger = 1<<53 - 1
+
+// parseMethodSignature parses a human-readable method signature
+// into an abi.Method. Both the compact form "balanceOf(address)(uint256)"
+// and the Solidity form "function balanceOf(address owner) view returns
+// (uint256)" are supported. Tuple parameters are only supported in JSON
+// ABI fragments.
+func parseMethodSignature(signature string) (*abi.Method, error) {
+	s := strings.TrimSpace(signature)
+	s = strings.TrimSpace(strings.TrimPrefix(s, "function "))
+
+	open := strings.Index(s, "(")
+	if open <= 0 {
+		return nil, fmt.Errorf("%s is not a valid method signature", signature)
+	}
+	name := strings.TrimSpace(s[:open])
+
+	inputs, rest, err := parseParameterList(s[open:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid inputs in %s", err, signature)
+	}
+
+	var outputs abi.Arguments
+	rest = strings.TrimSpace(rest)
+	if idx := strings.Index(rest, "returns"); idx >= 0 {
+		rest = strings.TrimSpace(rest[idx+len("returns"):])
+	} else if !strings.HasPrefix(rest, "(") {
+		// Only modifiers (e.g. view) without any outputs
+		rest = ""
+	}
+
+	if len(rest) > 0 {
+		outputs, rest, err = parseParameterList(rest)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid outputs in %s", err, signature)
+		}
+		if len(strings.TrimSpace(rest)) > 0 {
+			return nil, fmt.Errorf("unexpected %s in %s", rest, signature)
+		}
+	}
+
+	method := abi.NewMethod(name, name, abi.Function, "view", true, false, inputs, outputs)
+	return &method, nil
+}
+
+// parseParameterList parses a parenthesized list of parameters
+// and returns the remainder of the string.
+func parseParameterList(s string) (abi.Arguments, string, error) {
+	if !strings.HasPrefix(s, "(") {
+		return nil, "", errors.New("missing opening parenthesis")
+	}
+
+	closing := strings.Index(s, ")")
+	if closing < 0 {
+		return nil, "", errors.New("missing closing parenthesis")
+	}
+
+	list := strings.TrimSpace(s[1:closing])
+	rest := s[closing+1:]
+	if strings.Contains(list, "(") {
+		return nil, "", errors.New("tuple parameters are not supported")
+	}
+
+	arguments := abi.Arguments{}
+	if len(list) == 0 {
+		return arguments, rest, nil
+	}
+
+	for _, param := range strings.Split(list, ",") {
+		fields := strings.Fields(param)
+		if len(fields) == 0 {
+			return nil, "", errors.New("empty parameter")
+		}
+
+		typ, err := abi.NewType(normalizeTypeName(fields[0]), "", nil)
+		if err != nil {
+			return nil, "", err
+		}
+
+		// Skip data location keywords
+		var name string
+		for _, field := range fields[1:] {
+			if field == "memory" || field == "calldata" || field == "storage" || field == "indexed" {
+				continue
+			}
+			name = field
+		}
+
+		arguments = append(arguments, abi.Argument{Name: name, Type: typ})
+	}
+
+	return arguments, rest, nil
+}
+
+// normalizeTypeName expands the uint and int aliases of uint256
+// and int256 in typeName, including in arrays and slices (e.g.
+// "uint[2]" becomes "uint256[2]").
+func normalizeTypeName(typeName string) string {
+	base, suffix := typeName, ""
+	if idx := strings.Index(typeName, "["); idx >= 0 {
+		base, suffix = typeName[:idx], typeName[idx:]
+	}
+
+	switch base {
+	case "uint":
+		base = "uint256"
+	case "int":
+		base = "int256"
+	}
+
+	return base + suffix
+}
+
+// abiValue converts a JSON value into the Go value
+// expected by the abi package to pack the provided type.
+func abiValue(t abi.Type, v interface{}) (reflect.Value, error) { // nolint:gocognit
+	target := t.GetType()
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		n, err := jsonInteger(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		if target == reflect.TypeOf(&big.Int{}) {
+			return reflect.ValueOf(n), nil
+		}
+
+		value := reflect.New(target).Elem()
+		if t.T == abi.UintTy {
+			if n.Sign() < 0 || !n.IsUint64() || value.OverflowUint(n.Uint64()) {
+				return reflect.Value{}, fmt.Errorf("%s overflows %s", n.String(), t.String())
+			}
+			value.SetUint(n.Uint64())
+		} else {
+			if !n.IsInt64() || value.OverflowInt(n.Int64()) {
+				return reflect.Value{}, fmt.Errorf("%s overflows %s", n.String(), t.String())
+			}
+			value.SetInt(n.Int64())
+		}
+		return value, nil
+	case abi.BoolTy:
+		b, ok := v.(bool)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("%v is not a bool", v)
+		}
+		return reflect.ValueOf(b), nil
+	case abi.StringTy:
+		s, ok := v.(string)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("%v is not a string", v)
+		}
+		return reflect.ValueOf(s), nil
+	case abi.AddressTy:
+		s, ok := v.(string)
+		if !ok || !common.IsHexAddress(s) {
+			return reflect.Value{}, fmt.Errorf("%v is not an address", v)
+		}
+		return reflect.ValueOf(common.HexToAddress(s)), nil
+	case abi.BytesTy:
+		b, err := jsonBytes(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(b), nil
+	case abi.FixedBytesTy:
+		b, err := jsonBytes(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if len(b) != t.Size {
+			return reflect.Value{}, fmt.Errorf("%s expects %d bytes but got %d", t.String(), t.Size, len(b))
+		}
+		value := reflect.New(target).Elem()
+		reflect.Copy(value, reflect.ValueOf(b))
+		return value, nil
+	case abi.SliceTy, abi.ArrayTy:
+		items, ok := v.([]interface{})
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("%v is not a list", v)
+		}
+
+		var value reflect.Value
+		if t.T == abi.SliceTy {
+			value = reflect.MakeSlice(target, len(items), len(items))
+		} else {
+			if len(items) != t.Size {
+				return reflect.Value{}, fmt.Errorf("%s expects %d items but got %d", t.String(), t.Size, len(items))
+			}
+			value = reflect.New(target).Elem()
+		}
+
+		for i, item := range items {
+			elem, err := abiValue(*t.Elem, item)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			value.Index(i).Set(elem)
+		}
+		return value, nil
+	case abi.TupleTy:
+		value := reflect.New(target).Elem()
+		for i, elemType := range t.TupleElems {
+			var item interface{}
+			switch fields := v.(type) {
+			case map[string]interface{}:
+				item = fields[t.TupleRawNames[i]]
+			case []interface{}:
+				if len(fields) != len(t.TupleElems) {
+					return reflect.Value{}, fmt.Errorf("%s expects %d fields", t.String(), len(t.TupleElems))
+				}
+				item = fields[i]
+			default:
+				return reflect.Value{}, fmt.Errorf("%v is not a tuple", v)
+			}
+
+			elem, err := abiValue(*elemType, item)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			value.Field(i).Set(elem)
+		}
+		return value, nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("%s is not supported", t.String())
+}
+
+// jsonInteger converts a JSON number or a decimal or
+// hex string into a *big.Int.
+func jsonInteger(v interface{}) (*big.Int, error) {
+	switch n := v.(type) {
+	case float64:
+		if n != math.Trunc(n) || math.Abs(n) > maxSafeJSONInteger {
+			return nil, fmt.Errorf("%v must be provided as a string", n)
+		}
+		return big.NewInt(int64(n)), nil
+	case json.Number:
+		i, ok := new(big.Int).SetString(n.String(), 10) // nolint:gomnd
+		if !ok {
+			return nil, fmt.Errorf("%s is not an integer", n)
+		}
+		return i, nil
+	case string:
+		if strings.HasPrefix(n, "-") {
+			i, err := parseQuantity(n[1:])
+			if err != nil {
+				return nil, err
+			}
+			return new(big.Int).Neg(i), nil
+		}
+		return parseQuantity(n)
+	}
+
+	return nil, fmt.Errorf("%v is not an integer", v)
+}
+
+func jsonBytes(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("%v is not a hex string", v)
+	}
+
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s is not a hex string", err, s)
+	}
+
+	return b, nil
+}
+
+// abiJSON converts a value unpacked by the abi package into
+// its JSON representation. Integers are converted to decimal
+// strings, addresses to checksum addresses and bytes to hex.
+func abiJSON(t abi.Type, v interface{}) interface{} {
+	value := reflect.ValueOf(v)
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		if n, ok := v.(*big.Int); ok {
+			return n.String()
+		}
+		if t.T == abi.UintTy {
+			return new(big.Int).SetUint64(value.Uint()).String()
+		}
+		return big.NewInt(value.Int()).String()
+	case abi.AddressTy:
+		return MustChecksum(v.(common.Address).Hex())
+	case abi.BytesTy:
+		return hexutil.Encode(v.([]byte))
+	case abi.FixedBytesTy, abi.HashTy, abi.FunctionTy, abi.FixedPointTy:
+		b := make([]byte, value.Len())
+		reflect.Copy(reflect.ValueOf(b), value)
+		return hexutil.Encode(b)
+	case abi.SliceTy, abi.ArrayTy:
+		items := make([]interface{}, value.Len())
+		for i := range items {
+			items[i] = abiJSON(*t.Elem, value.Index(i).Interface())
+		}
+		return items
+	case abi.TupleTy:
+		fields := map[string]interface{}{}
+		for i, elemType := range t.TupleElems {
+			fields[t.TupleRawNames[i]] = abiJSON(*elemType, value.Field(i).Interface())
+		}
+		return fields
+	}
+
+	return v
+}
+
+// abiArgumentsJSON converts all unpacked values into a list of
+// JSON objects carrying the name, type and value of each argument.
+func abiArgumentsJSON(arguments abi.Arguments, values []interface{}) []interface{} {
+	results := make([]interface{}, len(values))
+	for i, value := range values {
+		results[i] = map[string]interface{}{
+			"name":  arguments[i].Name,
+			"type":  arguments[i].Type.String(),
+			"value": abiJSON(arguments[i].Type, value),
+		}
+	}
+
+	return results
+}
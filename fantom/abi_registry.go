@@ -0,0 +1,292 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// erc20ABIJSON contains the events of ERC-20 tokens.
+	erc20ABIJSON = `[
+	{"type": "event", "name": "Transfer", "inputs": [
+		{"name": "from", "type": "address", "indexed": true},
+		{"name": "to", "type": "address", "indexed": true},
+		{"name": "value", "type": "uint256", "indexed": false}
+	]},
+	{"type": "event", "name": "Approval", "inputs": [
+		{"name": "owner", "type": "address", "indexed": true},
+		{"name": "spender", "type": "address", "indexed": true},
+		{"name": "value", "type": "uint256", "indexed": false}
+	]}
+]`
+
+	// erc721ABIJSON contains the events of ERC-721 tokens
+	// and the safeTransferFrom method used to transfer them.
+	erc721ABIJSON = `[
+	{"type": "function", "name": "safeTransferFrom", "stateMutability": "nonpayable", "inputs": [
+		{"name": "from", "type": "address"},
+		{"name": "to", "type": "address"},
+		{"name": "tokenId", "type": "uint256"}
+	], "outputs": []},
+	{"type": "event", "name": "Transfer", "inputs": [
+		{"name": "from", "type": "address", "indexed": true},
+		{"name": "to", "type": "address", "indexed": true},
+		{"name": "tokenId", "type": "uint256", "indexed": true}
+	]},
+	{"type": "event", "name": "Approval", "inputs": [
+		{"name": "owner", "type": "address", "indexed": true},
+		{"name": "approved", "type": "address", "indexed": true},
+		{"name": "tokenId", "type": "uint256", "indexed": true}
+	]},
+	{"type": "event", "name": "ApprovalForAll", "inputs": [
+		{"name": "owner", "type": "address", "indexed": true},
+		{"name": "operator", "type": "address", "indexed": true},
+		{"name": "approved", "type": "bool", "indexed": false}
+	]}
+]`
+
+	// erc1155ABIJSON contains the events of ERC-1155 tokens
+	// and the safeTransferFrom method used to transfer them.
+	erc1155ABIJSON = `[
+	{"type": "function", "name": "safeTransferFrom", "stateMutability": "nonpayable", "inputs": [
+		{"name": "from", "type": "address"},
+		{"name": "to", "type": "address"},
+		{"name": "id", "type": "uint256"},
+		{"name": "amount", "type": "uint256"},
+		{"name": "data", "type": "bytes"}
+	], "outputs": []},
+	{"type": "event", "name": "TransferSingle", "inputs": [
+		{"name": "operator", "type": "address", "indexed": true},
+		{"name": "from", "type": "address", "indexed": true},
+		{"name": "to", "type": "address", "indexed": true},
+		{"name": "id", "type": "uint256", "indexed": false},
+		{"name": "value", "type": "uint256", "indexed": false}
+	]},
+	{"type": "event", "name": "TransferBatch", "inputs": [
+		{"name": "operator", "type": "address", "indexed": true},
+		{"name": "from", "type": "address", "indexed": true},
+		{"name": "to", "type": "address", "indexed": true},
+		{"name": "ids", "type": "uint256[]", "indexed": false},
+		{"name": "values", "type": "uint256[]", "indexed": false}
+	]},
+	{"type": "event", "name": "ApprovalForAll", "inputs": [
+		{"name": "account", "type": "address", "indexed": true},
+		{"name": "operator", "type": "address", "indexed": true},
+		{"name": "approved", "type": "bool", "indexed": false}
+	]},
+	{"type": "event", "name": "URI", "inputs": [
+		{"name": "value", "type": "string", "indexed": false},
+		{"name": "id", "type": "uint256", "indexed": true}
+	]}
+]`
+)
+
+var (
+	erc20ABI   = mustParseABI(erc20ABIJSON)
+	erc721ABI  = mustParseABI(erc721ABIJSON)
+	erc1155ABI = mustParseABI(erc1155ABIJSON)
+)
+
+// DecodedLog is an event log decoded with
+// the ABI of its event.
+type DecodedLog struct {
+	LogIndex  uint                   `json:"log_index"`
+	Address   string                 `json:"address"`
+	Event     string                 `json:"event"`
+	Signature string                 `json:"signature"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// ABIRegistry decodes event logs with the events of the
+// registered ABIs. Events sharing a signature, like the
+// Transfer events of ERC-20 and ERC-721 tokens, are told
+// apart by their number of indexed arguments.
+//
+// ABIRegistry is not safe for concurrent registration, so
+// ABIs must be registered before logs are decoded.
+type ABIRegistry struct {
+	events map[common.Hash][]abi.Event
+}
+
+// NewABIRegistry returns an ABIRegistry with the events of
+// ERC-20, ERC-721 and ERC-1155 tokens, the SFC and WFTM.
+func NewABIRegistry() *ABIRegistry {
+	r := &ABIRegistry{events: map[common.Hash][]abi.Event{}}
+	for _, parsed := range []abi.ABI{erc20ABI, erc721ABI, erc1155ABI, sfcABI, wftmABI} {
+		r.Register(parsed)
+	}
+
+	return r
+}
+
+// Register adds the events of parsed to the registry. Events
+// with the signature and indexed arguments of a registered
+// event are ignored.
+func (r *ABIRegistry) Register(parsed abi.ABI) {
+	names := make([]string, 0, len(parsed.Events))
+	for name := range parsed.Events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		event := parsed.Events[name]
+		if event.Anonymous || r.lookup(event.ID, indexedCount(event)) != nil {
+			continue
+		}
+
+		r.events[event.ID] = append(r.events[event.ID], event)
+	}
+}
+
+// LoadDir registers the ABIs of all JSON files in dir. Files
+// contain either an ABI or a build artifact with an "abi"
+// field, as written by Hardhat and Truffle.
+func (r *ABIRegistry) LoadDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		contents, err := ioutil.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("%w: unable to read ABI %s", err, file)
+		}
+
+		// Build artifacts wrap the ABI
+		var artifact struct {
+			ABI json.RawMessage `json:"abi"`
+		}
+		if json.Unmarshal(contents, &artifact) == nil && len(artifact.ABI) > 0 {
+			contents = artifact.ABI
+		}
+
+		parsed, err := abi.JSON(bytes.NewReader(contents))
+		if err != nil {
+			return fmt.Errorf("%w: unable to parse ABI %s", err, file)
+		}
+
+		r.Register(parsed)
+	}
+
+	return nil
+}
+
+// DecodeLogs decodes all logs with a registered event.
+// Other logs are skipped.
+func (r *ABIRegistry) DecodeLogs(logs []*types.Log) []*DecodedLog {
+	decoded := []*DecodedLog{}
+	for _, log := range logs {
+		if decodedLog := r.DecodeLog(log); decodedLog != nil {
+			decoded = append(decoded, decodedLog)
+		}
+	}
+
+	return decoded
+}
+
+// DecodeLog decodes log with its registered event, or
+// returns nil if the event of log is not registered.
+func (r *ABIRegistry) DecodeLog(log *types.Log) *DecodedLog {
+	if len(log.Topics) == 0 {
+		return nil
+	}
+
+	event := r.lookup(log.Topics[0], len(log.Topics)-1)
+	if event == nil {
+		return nil
+	}
+
+	values, err := event.Inputs.NonIndexed().Unpack(log.Data)
+	if err != nil {
+		return nil
+	}
+
+	arguments := map[string]interface{}{}
+	topics := log.Topics[1:]
+	for i, input := range event.Inputs {
+		name := input.Name
+		if len(name) == 0 {
+			name = fmt.Sprintf("arg%d", i)
+		}
+
+		if !input.Indexed {
+			arguments[name] = abiJSON(input.Type, values[0])
+			values = values[1:]
+			continue
+		}
+
+		topic := topics[0]
+		topics = topics[1:]
+		switch input.Type.T {
+		case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+			// Topics only hold the hash of dynamic values
+			arguments[name] = topic.Hex()
+		default:
+			value := map[string]interface{}{}
+			if err := abi.ParseTopicsIntoMap(value, abi.Arguments{input}, []common.Hash{topic}); err != nil {
+				return nil
+			}
+			arguments[name] = abiJSON(input.Type, value[input.Name])
+		}
+	}
+
+	return &DecodedLog{
+		LogIndex:  log.Index,
+		Address:   MustChecksum(log.Address.Hex()),
+		Event:     event.RawName,
+		Signature: event.Sig,
+		Arguments: arguments,
+	}
+}
+
+// lookup returns the registered event with id and
+// indexed indexed arguments, or nil if there is none.
+func (r *ABIRegistry) lookup(id common.Hash, indexed int) *abi.Event {
+	for i, event := range r.events[id] {
+		if indexedCount(event) == indexed {
+			return &r.events[id][i]
+		}
+	}
+
+	return nil
+}
+
+// indexedArguments returns the indexed arguments of event.
+func indexedArguments(event abi.Event) abi.Arguments {
+	var indexed abi.Arguments
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+
+	return indexed
+}
+
+func indexedCount(event abi.Event) int {
+	return len(indexedArguments(event))
+}
@@ -0,0 +1,250 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testTokenAddress = common.HexToAddress("0x04068da6c83afcfa0e13ba15a6696662335d5b75")
+	testFromAddress  = common.HexToAddress("0xfb8af5814b2c10746d76a0da99bbde1d9e2b6a2b")
+	testToAddress    = common.HexToAddress("0x1ff502f9fe838cd772874cb67d0d96b93fd1d6d7")
+)
+
+func eventTopic(signature string) common.Hash {
+	return crypto.Keccak256Hash([]byte(signature))
+}
+
+func addressTopic(address common.Address) common.Hash {
+	return common.BytesToHash(address.Bytes())
+}
+
+func TestABIRegistry_DecodeLog(t *testing.T) {
+	registry := NewABIRegistry()
+	transferTopic := eventTopic("Transfer(address,address,uint256)")
+
+	t.Run("erc20 transfer", func(t *testing.T) {
+		decoded := registry.DecodeLog(&types.Log{
+			Address: testTokenAddress,
+			Topics:  []common.Hash{transferTopic, addressTopic(testFromAddress), addressTopic(testToAddress)},
+			Data:    math.U256Bytes(big.NewInt(1000)),
+			Index:   3,
+		})
+		assert.Equal(t, &DecodedLog{
+			LogIndex:  3,
+			Address:   MustChecksum(testTokenAddress.Hex()),
+			Event:     "Transfer",
+			Signature: "Transfer(address,address,uint256)",
+			Arguments: map[string]interface{}{
+				"from":  MustChecksum(testFromAddress.Hex()),
+				"to":    MustChecksum(testToAddress.Hex()),
+				"value": "1000",
+			},
+		}, decoded)
+	})
+
+	t.Run("erc721 transfer", func(t *testing.T) {
+		decoded := registry.DecodeLog(&types.Log{
+			Address: testTokenAddress,
+			Topics: []common.Hash{
+				transferTopic,
+				addressTopic(testFromAddress),
+				addressTopic(testToAddress),
+				common.BigToHash(big.NewInt(42)),
+			},
+		})
+		assert.Equal(t, "Transfer", decoded.Event)
+		assert.Equal(t, map[string]interface{}{
+			"from":    MustChecksum(testFromAddress.Hex()),
+			"to":      MustChecksum(testToAddress.Hex()),
+			"tokenId": "42",
+		}, decoded.Arguments)
+	})
+
+	t.Run("erc1155 batch transfer", func(t *testing.T) {
+		data, err := erc1155ABI.Events["TransferBatch"].Inputs.NonIndexed().Pack(
+			[]*big.Int{big.NewInt(1), big.NewInt(2)},
+			[]*big.Int{big.NewInt(10), big.NewInt(20)},
+		)
+		assert.NoError(t, err)
+
+		decoded := registry.DecodeLog(&types.Log{
+			Address: testTokenAddress,
+			Topics: []common.Hash{
+				eventTopic("TransferBatch(address,address,address,uint256[],uint256[])"),
+				addressTopic(testFromAddress),
+				addressTopic(testFromAddress),
+				addressTopic(testToAddress),
+			},
+			Data: data,
+		})
+		assert.Equal(t, "TransferBatch", decoded.Event)
+		assert.Equal(t, []interface{}{"1", "2"}, decoded.Arguments["ids"])
+		assert.Equal(t, []interface{}{"10", "20"}, decoded.Arguments["values"])
+	})
+
+	t.Run("sfc delegation", func(t *testing.T) {
+		decoded := registry.DecodeLog(&types.Log{
+			Address: SFCAddress,
+			Topics: []common.Hash{
+				eventTopic("Delegated(address,uint256,uint256)"),
+				addressTopic(testFromAddress),
+				common.BigToHash(big.NewInt(7)),
+			},
+			Data: math.U256Bytes(big.NewInt(500)),
+		})
+		assert.Equal(t, "Delegated", decoded.Event)
+		assert.Equal(t, map[string]interface{}{
+			"delegator":     MustChecksum(testFromAddress.Hex()),
+			"toValidatorID": "7",
+			"amount":        "500",
+		}, decoded.Arguments)
+	})
+
+	t.Run("unknown event", func(t *testing.T) {
+		assert.Nil(t, registry.DecodeLog(&types.Log{
+			Topics: []common.Hash{eventTopic("Unknown(uint256)")},
+			Data:   math.U256Bytes(big.NewInt(1)),
+		}))
+
+		// Indexed arguments must match the event
+		assert.Nil(t, registry.DecodeLog(&types.Log{
+			Topics: []common.Hash{transferTopic, addressTopic(testFromAddress)},
+		}))
+		assert.Nil(t, registry.DecodeLog(&types.Log{}))
+	})
+}
+
+func TestABIRegistry_LoadDir(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "vault.json"), []byte(`{
+		"contractName": "Vault",
+		"abi": [
+			{"type": "event", "name": "Staked", "inputs": [
+				{"name": "user", "type": "address", "indexed": true},
+				{"name": "", "type": "uint256", "indexed": false},
+				{"name": "memo", "type": "string", "indexed": true}
+			]}
+		]
+	}`), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not an ABI"), 0600))
+
+	registry := NewABIRegistry()
+	assert.NoError(t, registry.LoadDir(dir))
+
+	memo := crypto.Keccak256Hash([]byte("hello"))
+	decoded := registry.DecodeLog(&types.Log{
+		Address: testTokenAddress,
+		Topics: []common.Hash{
+			eventTopic("Staked(address,uint256,string)"),
+			addressTopic(testFromAddress),
+			memo,
+		},
+		Data: math.U256Bytes(big.NewInt(5)),
+	})
+	assert.Equal(t, "Staked", decoded.Event)
+	assert.Equal(t, map[string]interface{}{
+		"user": MustChecksum(testFromAddress.Hex()),
+		"arg1": "5",
+		"memo": memo.Hex(),
+	}, decoded.Arguments)
+
+	// Built-in events are still decoded
+	assert.NotNil(t, registry.DecodeLog(&types.Log{
+		Topics: []common.Hash{
+			eventTopic("Deposit(address,uint256)"),
+			addressTopic(testFromAddress),
+		},
+		Data: math.U256Bytes(big.NewInt(5)),
+	}))
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "invalid.json"), []byte(`{"abi": 1}`), 0600))
+	assert.Error(t, registry.LoadDir(dir))
+}
+
+func TestPopulateTransaction_DecodedLogs(t *testing.T) {
+	hash := common.HexToHash("0x9cc8e6a09ae9cbdb7da77515110a8e343a945df4269c53842dd26969d32c6cc4")
+	tx := &loadedTransaction{
+		Transaction: types.NewTransaction(0, testTokenAddress, new(big.Int), 60000, big.NewInt(1), nil),
+		From:        &testFromAddress,
+		FeeAmount:   big.NewInt(60000),
+		Hash:        &hash,
+		Receipt: &types.Receipt{
+			Logs: []*types.Log{
+				{
+					Address: testTokenAddress,
+					Topics: []common.Hash{
+						eventTopic("Transfer(address,address,uint256)"),
+						addressTopic(testFromAddress),
+						addressTopic(testToAddress),
+					},
+					Data: math.U256Bytes(big.NewInt(1000)),
+				},
+			},
+		},
+	}
+
+	c := &Client{abis: NewABIRegistry()}
+	transaction, err := c.populateTransaction(tx, &BlockOptions{})
+	assert.NoError(t, err)
+	decodedLogs := transaction.Metadata["decoded_logs"].([]*DecodedLog)
+	assert.Len(t, decodedLogs, 1)
+	assert.Equal(t, "Transfer", decodedLogs[0].Event)
+
+	transaction, err = c.populateTransaction(tx, &BlockOptions{MetadataProfile: MetadataProfileFull})
+	assert.NoError(t, err)
+	assert.Contains(t, transaction.Metadata, "decoded_logs")
+
+	transaction, err = c.populateTransaction(tx, &BlockOptions{MetadataProfile: MetadataProfileCompact})
+	assert.NoError(t, err)
+	assert.NotContains(t, transaction.Metadata, "decoded_logs")
+
+	transaction, err = c.populateTransaction(tx, &BlockOptions{MetadataProfile: MetadataProfileNone})
+	assert.NoError(t, err)
+	assert.NotContains(t, transaction.Metadata, "decoded_logs")
+
+	// Logs are not decoded without a registry
+	c.SetABIRegistry(nil)
+	transaction, err = c.populateTransaction(tx, &BlockOptions{})
+	assert.NoError(t, err)
+	assert.NotContains(t, transaction.Metadata, "decoded_logs")
+}
+
+func TestNewClient_ABIRegistry(t *testing.T) {
+	// The tracer is loaded relative to the root of the repository
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(".."))
+	defer func() { assert.NoError(t, os.Chdir(wd)) }()
+
+	// Logs are only decoded once a registry is set
+	c, err := NewClient("http://localhost:18545", true)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer c.Close()
+	assert.Nil(t, c.abis)
+}
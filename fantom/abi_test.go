@@ -0,0 +1,126 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMethodSignature(t *testing.T) {
+	tests := map[string]struct {
+		signature string
+		sig       string
+		inputs    []string
+		outputs   []string
+	}{
+		"compact": {
+			signature: "balanceOf(address)(uint256)",
+			sig:       "balanceOf(address)",
+			inputs:    []string{"address"},
+			outputs:   []string{"uint256"},
+		},
+		"solidity": {
+			signature: "function getReserves(uint8 id, bytes memory data) view returns (uint112, int)",
+			sig:       "getReserves(uint8,bytes)",
+			inputs:    []string{"uint8", "bytes"},
+			outputs:   []string{"uint112", "int256"},
+		},
+		"integer arrays": {
+			signature: "prices(uint[2], int[], uint64[3])(uint[])",
+			sig:       "prices(uint256[2],int256[],uint64[3])",
+			inputs:    []string{"uint256[2]", "int256[]", "uint64[3]"},
+			outputs:   []string{"uint256[]"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			method, err := parseMethodSignature(test.signature)
+			assert.NoError(t, err)
+			assert.Equal(t, test.sig, method.Sig)
+
+			inputs := []string{}
+			for _, input := range method.Inputs {
+				inputs = append(inputs, input.Type.String())
+			}
+			assert.Equal(t, test.inputs, inputs)
+
+			outputs := []string{}
+			for _, output := range method.Outputs {
+				outputs = append(outputs, output.Type.String())
+			}
+			assert.Equal(t, test.outputs, outputs)
+		})
+	}
+}
+
+func TestContractMethod_Overloaded(t *testing.T) {
+	abiJSON := `[
+		{"type": "function", "name": "balanceOf", "stateMutability": "view",
+			"inputs": [{"name": "owner", "type": "address"}],
+			"outputs": [{"name": "", "type": "uint256"}]},
+		{"type": "function", "name": "balanceOf", "stateMutability": "view",
+			"inputs": [{"name": "owner", "type": "address"}, {"name": "id", "type": "uint256"}],
+			"outputs": [{"name": "", "type": "uint256"}]},
+		{"type": "function", "name": "decimals", "stateMutability": "view",
+			"inputs": [],
+			"outputs": [{"name": "", "type": "uint8"}]}
+	]`
+
+	tests := map[string]struct {
+		method string
+		sig    string
+		err    string
+	}{
+		"name": {
+			method: "decimals",
+			sig:    "decimals()",
+		},
+		"signature": {
+			method: "balanceOf(address)",
+			sig:    "balanceOf(address)",
+		},
+		"overload signature": {
+			method: "balanceOf(address, uint)",
+			sig:    "balanceOf(address,uint256)",
+		},
+		"overloaded name": {
+			method: "balanceOf",
+			err:    "method balanceOf is overloaded",
+		},
+		"unknown signature": {
+			method: "balanceOf(uint256)",
+			err:    "method balanceOf(uint256) not found in abi",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			method, err := contractMethod(&ContractReadInput{Method: test.method, ABI: abiJSON})
+			if len(test.err) > 0 {
+				assert.Nil(t, method)
+				assert.True(t, errors.Is(err, ErrCallParametersInvalid))
+				assert.Contains(t, err.Error(), test.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.sig, method.Sig)
+		})
+	}
+}
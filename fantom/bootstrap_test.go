@@ -0,0 +1,68 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateBootstrapFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bootstrap")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	genesisFile := filepath.Join(dir, "genesis.json")
+	assert.NoError(t, ioutil.WriteFile(genesisFile, []byte(`{
+		"alloc": {
+			"541e408443a592c38e01bed0cb31f9de8c1322d0": {"balance": "0x64"},
+			"fb8af5814b2c10746d76a0da99bbde1d9e2b6a2b": {"balance": "0x0"}
+		}
+	}`), 0600))
+
+	output := filepath.Join(dir, "bootstrap.json")
+	assert.NoError(t, GenerateBootstrapFile(genesisFile, output))
+
+	var balances []*modules.BootstrapBalance
+	assert.NoError(t, utils.LoadAndParse(output, &balances))
+	assert.Equal(t, []*modules.BootstrapBalance{
+		{
+			Account:  &types.AccountIdentifier{Address: "0x541E408443A592C38e01Bed0cB31f9De8c1322d0"},
+			Value:    "100",
+			Currency: Currency,
+		},
+	}, balances)
+}
+
+func TestGenerateBootstrapFile_InvalidGenesis(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bootstrap")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Binary Opera genesis files are not supported
+	genesisFile := filepath.Join(dir, "opera.g")
+	assert.NoError(t, ioutil.WriteFile(genesisFile, []byte{0x64, 0x1b, 0x00, 0xac, 0x00, 0x01, 0x00, 0x01}, 0600))
+
+	output := filepath.Join(dir, "bootstrap.json")
+	assert.Error(t, GenerateBootstrapFile(genesisFile, output))
+	assert.NoFileExists(t, output)
+}
@@ -0,0 +1,660 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	// maxGetLogsBlockRange is the maximum number of blocks
+	// a single "eth_getLogs" call may query.
+	maxGetLogsBlockRange = int64(1000) // nolint:gomnd
+
+	// maxFeeHistoryBlockCount is the maximum number of blocks
+	// a single "eth_feeHistory" call may query.
+	maxFeeHistoryBlockCount = int64(1024) // nolint:gomnd
+)
+
+// Quantity is a big integer call parameter which can be provided
+// as a JSON number, a decimal string or a 0x-prefixed hex string.
+type Quantity big.Int
+
+// UnmarshalJSON is a custom unmarshaler for Quantity.
+func (q *Quantity) UnmarshalJSON(input []byte) error {
+	value, err := parseQuantity(strings.Trim(string(input), "\""))
+	if err != nil {
+		return err
+	}
+
+	*q = Quantity(*value)
+	return nil
+}
+
+// parseQuantity parses a non-negative decimal or
+// 0x-prefixed hex integer.
+func parseQuantity(s string) (*big.Int, error) {
+	if len(s) == 0 {
+		return nil, fmt.Errorf("empty quantity")
+	}
+
+	var value *big.Int
+	var ok bool
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		value, ok = new(big.Int).SetString(s[2:], 16) // nolint:gomnd
+	} else {
+		value, ok = new(big.Int).SetString(s, 10) // nolint:gomnd
+	}
+	if !ok || value.Sign() < 0 {
+		return nil, fmt.Errorf("%s is not a valid quantity", s)
+	}
+
+	return value, nil
+}
+
+// Big returns the *big.Int value of the Quantity.
+func (q *Quantity) Big() *big.Int {
+	return (*big.Int)(q)
+}
+
+// StateOverride is the set of account fields overridden
+// for the duration of an "eth_call".
+type StateOverride struct {
+	Balance   *Quantity         `json:"balance,omitempty"`
+	Nonce     *uint64           `json:"nonce,omitempty"`
+	Code      string            `json:"code,omitempty"`
+	State     map[string]string `json:"state,omitempty"`
+	StateDiff map[string]string `json:"state_diff,omitempty"`
+}
+
+// GetLogsInput is the input to the call
+// method "eth_getLogs".
+type GetLogsInput struct {
+	FromBlock *int64     `json:"from_block,omitempty"`
+	ToBlock   *int64     `json:"to_block,omitempty"`
+	BlockHash string     `json:"block_hash,omitempty"`
+	Addresses []string   `json:"addresses,omitempty"`
+	Topics    [][]string `json:"topics,omitempty"`
+}
+
+// GetAccountInput is the input to the call
+// methods "eth_getCode" and "eth_getBalance".
+type GetAccountInput struct {
+	BlockIndex *int64 `json:"index,omitempty"`
+	BlockHash  string `json:"hash,omitempty"`
+	Address    string `json:"address"`
+}
+
+// GetStorageAtInput is the input to the call
+// method "eth_getStorageAt".
+type GetStorageAtInput struct {
+	BlockIndex *int64 `json:"index,omitempty"`
+	BlockHash  string `json:"hash,omitempty"`
+	Address    string `json:"address"`
+	Position   string `json:"position"`
+}
+
+// GetFeeHistoryInput is the input to the call
+// method "eth_feeHistory".
+type GetFeeHistoryInput struct {
+	BlockCount        int64     `json:"block_count"`
+	NewestBlock       *int64    `json:"newest_block,omitempty"`
+	RewardPercentiles []float64 `json:"reward_percentiles,omitempty"`
+}
+
+// blockArg returns the block argument of a JSON-RPC
+// state query. The hash takes precedence over the index and
+// the latest block is used if none are provided.
+func blockArg(index *int64, hash string) string {
+	if len(hash) > 0 {
+		return hash
+	}
+
+	if index != nil {
+		return toBlockNumArg(big.NewInt(*index))
+	}
+
+	return toBlockNumArg(nil)
+}
+
+func validateBlockQuery(index *int64, hash string) error {
+	if index != nil && *index < 0 {
+		return fmt.Errorf("%w: index must not be negative", ErrCallParametersInvalid)
+	}
+
+	if len(hash) > 0 {
+		if _, err := hexutil.Decode(hash); err != nil || len(hash) != 2+2*common.HashLength {
+			return fmt.Errorf("%w: %s is not a valid block hash", ErrCallParametersInvalid, hash)
+		}
+	}
+
+	return nil
+}
+
+// stateOverrides converts the provided overrides into the
+// format expected by "eth_call".
+func stateOverrides(
+	overrides map[string]*StateOverride,
+) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	for address, override := range overrides {
+		checkAddress, ok := ChecksumAddress(address)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is not a valid address", ErrCallParametersInvalid, address)
+		}
+
+		if override == nil {
+			continue
+		}
+
+		if len(override.State) > 0 && len(override.StateDiff) > 0 {
+			return nil, fmt.Errorf(
+				"%w: state and state_diff of %s cannot both be overridden",
+				ErrCallParametersInvalid,
+				address,
+			)
+		}
+
+		account := map[string]interface{}{}
+		if override.Balance != nil {
+			account["balance"] = hexutil.EncodeBig(override.Balance.Big())
+		}
+		if override.Nonce != nil {
+			account["nonce"] = hexutil.EncodeUint64(*override.Nonce)
+		}
+		if len(override.Code) > 0 {
+			if _, err := hexutil.Decode(override.Code); err != nil {
+				return nil, fmt.Errorf("%w: code of %s: %s", ErrCallParametersInvalid, address, err.Error())
+			}
+			account["code"] = override.Code
+		}
+
+		for key, slots := range map[string]map[string]string{
+			"state":     override.State,
+			"stateDiff": override.StateDiff,
+		} {
+			if len(slots) == 0 {
+				continue
+			}
+
+			storage := map[string]string{}
+			for slot, value := range slots {
+				if !isHash(slot) || !isHash(value) {
+					return nil, fmt.Errorf(
+						"%w: storage slots of %s must be 32 byte hex values",
+						ErrCallParametersInvalid,
+						address,
+					)
+				}
+				storage[slot] = value
+			}
+			account[key] = storage
+		}
+
+		result[checkAddress] = account
+	}
+
+	return result, nil
+}
+
+func isHash(s string) bool {
+	b, err := hexutil.Decode(s)
+	return err == nil && len(b) == common.HashLength
+}
+
+// getLogs returns the logs matching the provided filter.
+func (ec *Client) getLogs(
+	ctx context.Context,
+	params map[string]interface{},
+) (map[string]interface{}, error) {
+	var input GetLogsInput
+	if err := RosettaTypes.UnmarshalMap(params, &input); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+	}
+
+	filter := map[string]interface{}{}
+	if len(input.BlockHash) > 0 {
+		if input.FromBlock != nil || input.ToBlock != nil {
+			return nil, fmt.Errorf(
+				"%w: block_hash cannot be combined with from_block or to_block",
+				ErrCallParametersInvalid,
+			)
+		}
+		if err := validateBlockQuery(nil, input.BlockHash); err != nil {
+			return nil, err
+		}
+		filter["blockHash"] = input.BlockHash
+	} else {
+		if input.FromBlock == nil {
+			return nil, fmt.Errorf("%w:from_block missing from params", ErrCallParametersInvalid)
+		}
+
+		toBlock := input.ToBlock
+		if toBlock == nil {
+			header, err := ec.blockHeaderByNumber(ctx, nil)
+			if err != nil {
+				return nil, err
+			}
+			latest := header.Number.Int64()
+			toBlock = &latest
+		}
+
+		if *input.FromBlock < 0 || *toBlock < *input.FromBlock {
+			return nil, fmt.Errorf(
+				"%w: invalid block range %d-%d",
+				ErrCallParametersInvalid,
+				*input.FromBlock,
+				*toBlock,
+			)
+		}
+
+		if *toBlock-*input.FromBlock+1 > maxGetLogsBlockRange {
+			return nil, fmt.Errorf(
+				"%w: block range %d-%d exceeds the limit of %d blocks",
+				ErrCallParametersInvalid,
+				*input.FromBlock,
+				*toBlock,
+				maxGetLogsBlockRange,
+			)
+		}
+
+		filter["fromBlock"] = toBlockNumArg(big.NewInt(*input.FromBlock))
+		filter["toBlock"] = toBlockNumArg(big.NewInt(*toBlock))
+	}
+
+	if len(input.Addresses) > 0 {
+		addresses := make([]string, len(input.Addresses))
+		for i, address := range input.Addresses {
+			checkAddress, ok := ChecksumAddress(address)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s is not a valid address", ErrCallParametersInvalid, address)
+			}
+			addresses[i] = checkAddress
+		}
+		filter["address"] = addresses
+	}
+
+	if len(input.Topics) > 0 {
+		topics := make([]interface{}, len(input.Topics))
+		for i, options := range input.Topics {
+			// An empty position matches any topic
+			if len(options) == 0 {
+				continue
+			}
+
+			for _, topic := range options {
+				if !isHash(topic) {
+					return nil, fmt.Errorf("%w: %s is not a valid topic", ErrCallParametersInvalid, topic)
+				}
+			}
+			topics[i] = options
+		}
+		filter["topics"] = topics
+	}
+
+	var logs []interface{}
+	if err := ec.c.CallContext(ctx, &logs, "eth_getLogs", filter); err != nil {
+		return nil, err
+	}
+	if logs == nil {
+		logs = []interface{}{}
+	}
+
+	return map[string]interface{}{
+		"logs": logs,
+	}, nil
+}
+
+func validateAccountInput(address string, index *int64, hash string) (string, error) {
+	if len(address) == 0 {
+		return "", fmt.Errorf("%w:address missing from params", ErrCallParametersInvalid)
+	}
+
+	checkAddress, ok := ChecksumAddress(address)
+	if !ok {
+		return "", fmt.Errorf("%w: %s is not a valid address", ErrCallParametersInvalid, address)
+	}
+
+	if err := validateBlockQuery(index, hash); err != nil {
+		return "", err
+	}
+
+	return checkAddress, nil
+}
+
+// getCode returns the code of an account.
+func (ec *Client) getCode(
+	ctx context.Context,
+	params map[string]interface{},
+) (map[string]interface{}, error) {
+	var input GetAccountInput
+	if err := RosettaTypes.UnmarshalMap(params, &input); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+	}
+
+	address, err := validateAccountInput(input.Address, input.BlockIndex, input.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var code string
+	query := blockArg(input.BlockIndex, input.BlockHash)
+	if err := ec.c.CallContext(ctx, &code, "eth_getCode", address, query); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"code": code,
+	}, nil
+}
+
+// getBalance returns the balance of an account.
+func (ec *Client) getBalance(
+	ctx context.Context,
+	params map[string]interface{},
+) (map[string]interface{}, error) {
+	var input GetAccountInput
+	if err := RosettaTypes.UnmarshalMap(params, &input); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+	}
+
+	address, err := validateAccountInput(input.Address, input.BlockIndex, input.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var balance hexutil.Big
+	query := blockArg(input.BlockIndex, input.BlockHash)
+	if err := ec.c.CallContext(ctx, &balance, "eth_getBalance", address, query); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"balance": balance.String(),
+	}, nil
+}
+
+// getStorageAt returns the value of a storage slot of an account.
+func (ec *Client) getStorageAt(
+	ctx context.Context,
+	params map[string]interface{},
+) (map[string]interface{}, error) {
+	var input GetStorageAtInput
+	if err := RosettaTypes.UnmarshalMap(params, &input); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+	}
+
+	address, err := validateAccountInput(input.Address, input.BlockIndex, input.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(input.Position) == 0 {
+		return nil, fmt.Errorf("%w:position missing from params", ErrCallParametersInvalid)
+	}
+
+	position, err := parseQuantity(input.Position)
+	if err != nil {
+		return nil, fmt.Errorf("%w: position: %s", ErrCallParametersInvalid, err.Error())
+	}
+
+	var data string
+	query := blockArg(input.BlockIndex, input.BlockHash)
+	err = ec.c.CallContext(
+		ctx,
+		&data,
+		"eth_getStorageAt",
+		address,
+		hexutil.EncodeBig(position),
+		query,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"data": data,
+	}, nil
+}
+
+// chainID returns the chain ID used for signing replay-protected
+// transactions.
+func (ec *Client) chainID(ctx context.Context) (map[string]interface{}, error) {
+	var chainID hexutil.Big
+	if err := ec.c.CallContext(ctx, &chainID, "eth_chainId"); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"chain_id": chainID.String(),
+	}, nil
+}
+
+// feeHistory returns the base fees, gas usage ratios and priority
+// fee percentiles of a range of blocks.
+func (ec *Client) feeHistory(
+	ctx context.Context,
+	params map[string]interface{},
+) (map[string]interface{}, error) {
+	var input GetFeeHistoryInput
+	if err := RosettaTypes.UnmarshalMap(params, &input); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+	}
+
+	if input.BlockCount <= 0 || input.BlockCount > maxFeeHistoryBlockCount {
+		return nil, fmt.Errorf(
+			"%w: block_count must be between 1 and %d",
+			ErrCallParametersInvalid,
+			maxFeeHistoryBlockCount,
+		)
+	}
+
+	if err := validateBlockQuery(input.NewestBlock, ""); err != nil {
+		return nil, err
+	}
+
+	for i, percentile := range input.RewardPercentiles {
+		if percentile < 0 || percentile > 100 {
+			return nil, fmt.Errorf("%w: invalid reward percentile %f", ErrCallParametersInvalid, percentile)
+		}
+		if i > 0 && percentile < input.RewardPercentiles[i-1] {
+			return nil, fmt.Errorf(
+				"%w: reward percentiles must be in ascending order",
+				ErrCallParametersInvalid,
+			)
+		}
+	}
+
+	percentiles := input.RewardPercentiles
+	if percentiles == nil {
+		percentiles = []float64{}
+	}
+
+	var raw json.RawMessage
+	err := ec.c.CallContext(
+		ctx,
+		&raw,
+		"eth_feeHistory",
+		hexutil.EncodeUint64(uint64(input.BlockCount)),
+		blockArg(input.NewestBlock, ""),
+		percentiles,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var history map[string]interface{}
+	if err := json.Unmarshal(raw, &history); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallOutputMarshal, err.Error())
+	}
+
+	return history, nil
+}
+
+// GetEpochStatsInput is the input to the call
+// method "ftm_getEpochStats".
+type GetEpochStatsInput struct {
+	Epoch *int64 `json:"epoch,omitempty"`
+}
+
+// GetValidatorInput is the input to the call
+// method "sfc_getValidator".
+type GetValidatorInput struct {
+	BlockIndex  *int64 `json:"index,omitempty"`
+	BlockHash   string `json:"hash,omitempty"`
+	ValidatorID int64  `json:"validator_id"`
+}
+
+// GetEventInput is the input to the call
+// method "dag_getEvent".
+type GetEventInput struct {
+	EventID string `json:"event_id"`
+}
+
+// currentEpoch returns the current (sealing) epoch.
+func (ec *Client) currentEpoch(ctx context.Context) (map[string]interface{}, error) {
+	var epoch hexutil.Uint64
+	if err := ec.c.CallContext(ctx, &epoch, "ftm_currentEpoch"); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"epoch": epoch.String(),
+	}, nil
+}
+
+// epochStats returns the statistics of a sealed epoch.
+func (ec *Client) epochStats(
+	ctx context.Context,
+	params map[string]interface{},
+) (map[string]interface{}, error) {
+	var input GetEpochStatsInput
+	if err := RosettaTypes.UnmarshalMap(params, &input); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+	}
+
+	if input.Epoch != nil && *input.Epoch < 0 {
+		return nil, fmt.Errorf("%w: epoch must not be negative", ErrCallParametersInvalid)
+	}
+
+	var stats map[string]interface{}
+	if err := ec.c.CallContext(ctx, &stats, "ftm_getEpochStats", blockArg(input.Epoch, "")); err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		return nil, ethereum.NotFound
+	}
+
+	return stats, nil
+}
+
+// validator returns the state of a validator registered in the SFC.
+func (ec *Client) validator(
+	ctx context.Context,
+	params map[string]interface{},
+) (map[string]interface{}, error) {
+	var input GetValidatorInput
+	if err := RosettaTypes.UnmarshalMap(params, &input); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+	}
+
+	if input.ValidatorID <= 0 {
+		return nil, fmt.Errorf("%w:validator_id missing from params", ErrCallParametersInvalid)
+	}
+
+	if err := validateBlockQuery(input.BlockIndex, input.BlockHash); err != nil {
+		return nil, err
+	}
+
+	data, err := sfcABI.Pack("getValidator", big.NewInt(input.ValidatorID))
+	if err != nil {
+		return nil, err
+	}
+
+	callParams := map[string]string{
+		"to":   SFCAddress.Hex(),
+		"data": hexutil.Encode(data),
+	}
+
+	var resp string
+	query := blockArg(input.BlockIndex, input.BlockHash)
+	if err := ec.c.CallContext(ctx, &resp, "eth_call", callParams, query); err != nil {
+		return nil, err
+	}
+
+	output, err := hexutil.Decode(resp)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallOutputMarshal, err.Error())
+	}
+
+	values := map[string]interface{}{}
+	if err := sfcABI.UnpackIntoMap(values, "getValidator", output); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallOutputMarshal, err.Error())
+	}
+
+	auth := values["auth"].(common.Address)
+	if auth == (common.Address{}) {
+		return nil, fmt.Errorf("%w: validator %d does not exist", ErrCallParametersInvalid, input.ValidatorID)
+	}
+
+	return map[string]interface{}{
+		"validator_id":      input.ValidatorID,
+		"status":            values["status"].(*big.Int).String(),
+		"deactivated_time":  values["deactivatedTime"].(*big.Int).String(),
+		"deactivated_epoch": values["deactivatedEpoch"].(*big.Int).String(),
+		"received_stake":    values["receivedStake"].(*big.Int).String(),
+		"created_epoch":     values["createdEpoch"].(*big.Int).String(),
+		"created_time":      values["createdTime"].(*big.Int).String(),
+		"auth":              MustChecksum(auth.Hex()),
+	}, nil
+}
+
+// event returns the header of a Lachesis DAG event.
+func (ec *Client) event(
+	ctx context.Context,
+	params map[string]interface{},
+) (map[string]interface{}, error) {
+	var input GetEventInput
+	if err := RosettaTypes.UnmarshalMap(params, &input); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+	}
+
+	if len(input.EventID) == 0 {
+		return nil, fmt.Errorf("%w:event_id missing from params", ErrCallParametersInvalid)
+	}
+
+	if !isHash(input.EventID) {
+		return nil, fmt.Errorf("%w: %s is not a valid event id", ErrCallParametersInvalid, input.EventID)
+	}
+
+	var event map[string]interface{}
+	if err := ec.c.CallContext(ctx, &event, "dag_getEvent", input.EventID); err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, ethereum.NotFound
+	}
+
+	return event, nil
+}
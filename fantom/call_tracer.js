@@ -69,6 +69,9 @@
 				call.output = toHex(frameResult.getOutput())
 			} else {
 				call.error = error
+				if (error === "execution reverted" && frameResult.getOutput().length > 0) {
+					call.output = toHex(frameResult.getOutput())
+				}
 				if (call.type === 'CREATE' || call.type === 'CREATE2') {
 					delete call.to
 				}
@@ -23,6 +23,7 @@ import (
 	"log"
 	"math/big"
 	"net/http"
+	"sync"
 	"time"
 
 	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
@@ -63,6 +64,21 @@ type Client struct {
 	traceSemaphore *semaphore.Weighted
 
 	skipAdminCalls bool
+
+	tracker *txTracker
+
+	// abis decodes the logs of transactions in their
+	// metadata. Logs are not decoded if it is nil.
+	abis *ABIRegistry
+
+	// ready is set once Opera answers RPC queries and
+	// has imported blocks. readyEpoch is incremented by
+	// MarkNotReady so that a check started before it
+	// does not mark Opera ready. Both are guarded by
+	// readyMutex.
+	readyMutex sync.Mutex
+	ready      bool
+	readyEpoch uint64
 }
 
 // NewClient creates a Client that from the provided url and params.
@@ -84,7 +100,22 @@ func NewClient(url string, skipAdminCalls bool) (*Client, error) {
 		return nil, fmt.Errorf("%w: unable to create GraphQL client", err)
 	}
 
-	return &Client{tc, c, g, semaphore.NewWeighted(maxTraceConcurrency), skipAdminCalls}, nil
+	return &Client{
+		tc:             tc,
+		c:              c,
+		g:              g,
+		traceSemaphore: semaphore.NewWeighted(maxTraceConcurrency),
+		skipAdminCalls: skipAdminCalls,
+		tracker:        newTxTracker(),
+	}, nil
+}
+
+// SetABIRegistry sets the registry decoding the logs of
+// transactions with MetadataProfileFull. Logs are not
+// decoded until a registry is set, a nil registry
+// disables decoding.
+func (ec *Client) SetABIRegistry(registry *ABIRegistry) {
+	ec.abis = registry
 }
 
 // Close shuts down the RPC client connection.
@@ -92,6 +123,46 @@ func (ec *Client) Close() {
 	ec.c.Close()
 }
 
+// Ready returns ErrOperaNotReady until Opera answers RPC queries
+// and has imported blocks. Once Opera is ready, the result is cached
+// until MarkNotReady is called.
+func (ec *Client) Ready(ctx context.Context) error {
+	ec.readyMutex.Lock()
+	ready, epoch := ec.ready, ec.readyEpoch
+	ec.readyMutex.Unlock()
+	if ready {
+		return nil
+	}
+
+	var number hexutil.Uint64
+	if err := ec.c.CallContext(ctx, &number, "eth_blockNumber"); err != nil {
+		return fmt.Errorf("%w: %s", ErrOperaNotReady, err.Error())
+	}
+
+	if number == 0 {
+		return fmt.Errorf("%w: no blocks imported", ErrOperaNotReady)
+	}
+
+	ec.readyMutex.Lock()
+	defer ec.readyMutex.Unlock()
+	if ec.readyEpoch != epoch {
+		return fmt.Errorf("%w: Opera was restarted", ErrOperaNotReady)
+	}
+
+	ec.ready = true
+	return nil
+}
+
+// MarkNotReady resets the readiness of Opera (e.g. when
+// the Opera process exited and is being restarted).
+func (ec *Client) MarkNotReady() {
+	ec.readyMutex.Lock()
+	defer ec.readyMutex.Unlock()
+
+	ec.ready = false
+	ec.readyEpoch++
+}
+
 // Status returns opera status information
 // for determining node healthiness.
 func (ec *Client) Status(ctx context.Context) (
@@ -200,7 +271,27 @@ func (ec *Client) peers(ctx context.Context) ([]*RosettaTypes.Peer, error) {
 //
 // If the transaction was a contract creation use the TransactionReceipt method to get the
 // contract address after the transaction has been mined.
+//
+// Submitted transactions are tracked so that their lifecycle can be
+// queried with TrackedTransaction.
 func (ec *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := ec.sendRawTransaction(ctx, tx); err != nil {
+		return err
+	}
+
+	if ec.tracker != nil {
+		from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			log.Printf("unable to track transaction %s: %s\n", tx.Hash().Hex(), err)
+			return nil
+		}
+		ec.tracker.track(tx, from)
+	}
+
+	return nil
+}
+
+func (ec *Client) sendRawTransaction(ctx context.Context, tx *types.Transaction) error {
 	data, err := rlp.EncodeToBytes(tx)
 	if err != nil {
 		return err
@@ -257,17 +348,12 @@ func (ec *Client) Transaction(
 	}
 
 	receipt, err := ec.transactionReceipt(ctx, body.tx.Hash())
-	if receipt.BlockHash != *body.BlockHash {
-		return nil, fmt.Errorf(
-			"%w: expected block hash %s for transaction but got %s",
-			ErrBlockOrphaned,
-			body.BlockHash.Hex(),
-			receipt.BlockHash.Hex(),
-		)
-	}
 	if err != nil {
 		return nil, fmt.Errorf("%w: could not get tx receipt for %x", err, body.tx.Hash())
 	}
+	if receipt.BlockHash != *body.BlockHash {
+		return nil, ec.blockHashMismatch(ctx, header.Number, *body.BlockHash, body.tx.Hash(), receipt.BlockHash)
+	}
 
 	var traces *Call
 	var rawTraces json.RawMessage
@@ -288,6 +374,7 @@ func (ec *Client) Transaction(
 	}
 	loadedTx.FeeAmount = feeAmount
 	loadedTx.FeeBurned = feeBurned
+	loadedTx.BaseFee = header.BaseFee
 	loadedTx.Miner = MustChecksum(header.Coinbase.Hex())
 	loadedTx.Receipt = receipt
 
@@ -296,7 +383,7 @@ func (ec *Client) Transaction(
 		loadedTx.RawTrace = rawTraces
 	}
 
-	tx, err := ec.populateTransaction(loadedTx)
+	tx, err := ec.populateTransaction(loadedTx, BlockOptionsFromContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("%w: cannot parse %s", err, loadedTx.Hash.Hex())
 	}
@@ -369,6 +456,7 @@ func (ec *Client) blockHeaderByHash(ctx context.Context, hash string) (*blockHea
 
 type rpcBlock struct {
 	Hash         common.Hash      `json:"hash"`
+	Size         hexutil.Uint64   `json:"size"`
 	Transactions []rpcTransaction `json:"transactions"`
 	UncleHashes  []common.Hash    `json:"uncles"`
 }
@@ -437,7 +525,7 @@ func (ec *Client) getBlock(
 	args ...interface{},
 ) (
 	*types.Block,
-	*common.Hash,
+	*rpcBlock,
 	[]*loadedTransaction,
 	error,
 ) {
@@ -465,7 +553,7 @@ func (ec *Client) getBlock(
 	}
 
 	// Get all transaction receipts
-	receipts, err := ec.getBlockReceipts(ctx, body.Hash, body.Transactions)
+	receipts, err := ec.getBlockReceipts(ctx, head.Number, body.Hash, body.Transactions)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("%w: could not get block receipts for %x", err, body.Hash[:])
 	}
@@ -504,6 +592,7 @@ func (ec *Client) getBlock(
 		}
 		loadedTxs[i].FeeAmount = feeAmount
 		loadedTxs[i].FeeBurned = feeBurned
+		loadedTxs[i].BaseFee = head.BaseFee
 		loadedTxs[i].Miner = MustChecksum(head.Coinbase.Hex())
 		loadedTxs[i].Receipt = receipt
 
@@ -516,7 +605,7 @@ func (ec *Client) getBlock(
 		loadedTxs[i].RawTrace = rawTraces[i].Result
 	}
 
-	return types.NewBlockWithHeader(&head).WithBody(txs, uncles), &body.Hash, loadedTxs, nil
+	return types.NewBlockWithHeader(&head).WithBody(txs, uncles), &body, loadedTxs, nil
 }
 
 func calculateGas(
@@ -611,6 +700,7 @@ func (ec *Client) getBlockTraces(
 
 func (ec *Client) getBlockReceipts(
 	ctx context.Context,
+	blockNumber *big.Int,
 	blockHash common.Hash,
 	txs []rpcTransaction,
 ) ([]*types.Receipt, error) {
@@ -639,12 +729,7 @@ func (ec *Client) getBlockReceipts(
 		}
 
 		if receipts[i].BlockHash != blockHash {
-			return nil, fmt.Errorf(
-				"%w: expected block hash %s for transaction but got %s",
-				ErrBlockOrphaned,
-				blockHash.Hex(),
-				receipts[i].BlockHash.Hex(),
-			)
+			return nil, ec.blockHashMismatch(ctx, blockNumber, blockHash, txs[i].tx.Hash(), receipts[i].BlockHash)
 		}
 	}
 
@@ -666,8 +751,10 @@ type Call struct {
 	To           common.Address `json:"to"`
 	Value        *big.Int       `json:"value"`
 	GasUsed      *big.Int       `json:"gasUsed"`
+	Input        []byte         `json:"input"`
 	Revert       bool
-	ErrorMessage string  `json:"error"`
+	ErrorMessage string `json:"error"`
+	RevertReason string
 	Calls        []*Call `json:"calls"`
 }
 
@@ -677,19 +764,25 @@ type flatCall struct {
 	To           common.Address `json:"to"`
 	Value        *big.Int       `json:"value"`
 	GasUsed      *big.Int       `json:"gasUsed"`
+	Input        []byte         `json:"input"`
+	TraceAddress []int          `json:"traceAddress"`
 	Revert       bool
 	ErrorMessage string `json:"error"`
+	RevertReason string
 }
 
-func (t *Call) flatten() *flatCall {
+func (t *Call) flatten(traceAddress []int) *flatCall {
 	return &flatCall{
 		Type:         t.Type,
 		From:         t.From,
 		To:           t.To,
 		Value:        t.Value,
 		GasUsed:      t.GasUsed,
+		Input:        t.Input,
+		TraceAddress: traceAddress,
 		Revert:       t.Revert,
 		ErrorMessage: t.ErrorMessage,
+		RevertReason: t.RevertReason,
 	}
 }
 
@@ -701,9 +794,11 @@ func (t *Call) UnmarshalJSON(input []byte) error {
 		To           common.Address `json:"to"`
 		Value        *hexutil.Big   `json:"value"`
 		GasUsed      *hexutil.Big   `json:"gasUsed"`
+		Input        hexutil.Bytes  `json:"input"`
 		Revert       bool
-		ErrorMessage string  `json:"error"`
-		Calls        []*Call `json:"calls"`
+		Output       hexutil.Bytes `json:"output"`
+		ErrorMessage string        `json:"error"`
+		Calls        []*Call       `json:"calls"`
 	}
 	var dec CustomTrace
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -719,7 +814,7 @@ func (t *Call) UnmarshalJSON(input []byte) error {
 		t.Value = new(big.Int)
 	}
 	if dec.GasUsed != nil {
-		t.GasUsed = (*big.Int)(dec.Value)
+		t.GasUsed = (*big.Int)(dec.GasUsed)
 	} else {
 		t.GasUsed = new(big.Int)
 	}
@@ -727,7 +822,12 @@ func (t *Call) UnmarshalJSON(input []byte) error {
 		// Any error surfaced by the decoder means that the transaction
 		// has reverted.
 		t.Revert = true
+
+		// The tracer only keeps the output of a failed
+		// call when it holds the revert data.
+		t.RevertReason = decodeRevertReason(dec.Output)
 	}
+	t.Input = dec.Input
 	t.ErrorMessage = dec.ErrorMessage
 	t.Calls = dec.Calls
 	return nil
@@ -735,8 +835,15 @@ func (t *Call) UnmarshalJSON(input []byte) error {
 
 // flattenTraces recursively flattens all traces.
 func flattenTraces(data *Call, flattened []*flatCall) []*flatCall {
-	results := append(flattened, data.flatten())
-	for _, child := range data.Calls {
+	return flattenTracesAt(data, flattened, []int{})
+}
+
+// flattenTracesAt flattens the traces of data at
+// traceAddress, the path of child indexes leading
+// to data from the top-level call.
+func flattenTracesAt(data *Call, flattened []*flatCall, traceAddress []int) []*flatCall {
+	results := append(flattened, data.flatten(traceAddress))
+	for i, child := range data.Calls {
 		// Ensure all children of a reverted call
 		// are also reverted!
 		if data.Revert {
@@ -746,10 +853,15 @@ func flattenTraces(data *Call, flattened []*flatCall) []*flatCall {
 			// if child does not have one
 			if len(child.ErrorMessage) == 0 {
 				child.ErrorMessage = data.ErrorMessage
+				child.RevertReason = data.RevertReason
 			}
 		}
 
-		children := flattenTraces(child, flattened)
+		childAddress := make([]int, len(traceAddress)+1)
+		copy(childAddress, traceAddress)
+		childAddress[len(traceAddress)] = i
+
+		children := flattenTracesAt(child, flattened, childAddress)
 		results = append(results, children...)
 	}
 	return results
@@ -757,20 +869,39 @@ func flattenTraces(data *Call, flattened []*flatCall) []*flatCall {
 
 // traceOps returns all *RosettaTypes.Operation for a given
 // array of flattened traces.
-func traceOps(calls []*flatCall, startIndex int) []*RosettaTypes.Operation { // nolint: gocognit
+func traceOps( // nolint: gocognit
+	calls []*flatCall,
+	startIndex int,
+	options *BlockOptions,
+) []*RosettaTypes.Operation {
 	var ops []*RosettaTypes.Operation
 	if len(calls) == 0 {
 		return ops
 	}
 
 	destroyedAccounts := map[string]*big.Int{}
+
+	// callerOps holds, by depth, the operation of the account
+	// executing the frame at that depth, or of its closest
+	// ancestor if the frame has no operations.
+	callerOps := []*RosettaTypes.OperationIdentifier{}
 	for _, trace := range calls {
+		depth := len(trace.TraceAddress)
+		var parentOp *RosettaTypes.OperationIdentifier
+		if depth > 0 {
+			parentOp = callerOps[depth-1]
+		}
+		callerOps = append(callerOps[:depth], parentOp)
+
 		// Handle partial transaction success
 		metadata := map[string]interface{}{}
 		opStatus := SuccessStatus
 		if trace.Revert {
 			opStatus = FailureStatus
 			metadata["error"] = trace.ErrorMessage
+			if len(trace.RevertReason) > 0 {
+				metadata["revert_reason"] = trace.RevertReason
+			}
 		}
 
 		var zeroValue bool
@@ -778,15 +909,21 @@ func traceOps(calls []*flatCall, startIndex int) []*RosettaTypes.Operation { //
 			zeroValue = true
 		}
 
-		// Skip all 0 value CallType operations (TODO: make optional to include)
+		// Skip all 0 value CallType operations unless included
+		// by options.
 		//
 		// We can't continue here because we may need to adjust our destroyed
 		// accounts map if a CallTYpe operation resurrects an account.
 		shouldAdd := true
-		if zeroValue && CallType(trace.Type) {
+		if zeroValue && CallType(trace.Type) && !options.IncludeZeroValueCalls {
 			shouldAdd = false
 		}
 
+		addCallTreeMetadata(metadata, trace)
+		if options.IncludeZeroValueCalls && CallType(trace.Type) {
+			metadata["gas_used"] = trace.GasUsed.String()
+		}
+
 		// Checksum addresses
 		from := MustChecksum(trace.From.String())
 		to := MustChecksum(trace.To.String())
@@ -807,6 +944,13 @@ func traceOps(calls []*flatCall, startIndex int) []*RosettaTypes.Operation { //
 				},
 				Metadata: metadata,
 			}
+			if parentOp != nil {
+				fromOp.RelatedOperations = []*RosettaTypes.OperationIdentifier{
+					{
+						Index: parentOp.Index,
+					},
+				}
+			}
 			if zeroValue {
 				fromOp.Amount = nil
 			} else {
@@ -817,6 +961,7 @@ func traceOps(calls []*flatCall, startIndex int) []*RosettaTypes.Operation { //
 			}
 
 			ops = append(ops, fromOp)
+			callerOps[depth] = fromOp.OperationIdentifier
 		}
 
 		// Add to destroyed accounts if SELFDESTRUCT
@@ -878,6 +1023,7 @@ func traceOps(calls []*flatCall, startIndex int) []*RosettaTypes.Operation { //
 			}
 
 			ops = append(ops, toOp)
+			callerOps[depth] = toOp.OperationIdentifier
 		}
 	}
 
@@ -911,6 +1057,18 @@ func traceOps(calls []*flatCall, startIndex int) []*RosettaTypes.Operation { //
 	return ops
 }
 
+// addCallTreeMetadata adds the position of a frame in the
+// call tree, its call type and function selector to metadata,
+// so that the tree can be rebuilt from the operations.
+func addCallTreeMetadata(metadata map[string]interface{}, trace *flatCall) {
+	metadata["trace_address"] = trace.TraceAddress
+	metadata["depth"] = len(trace.TraceAddress)
+	metadata["call_type"] = trace.Type
+	if len(trace.Input) >= 4 { // nolint:gomnd
+		metadata["selector"] = hexutil.Encode(trace.Input[:4])
+	}
+}
+
 type txExtraInfo struct {
 	BlockNumber *string         `json:"blockNumber,omitempty"`
 	BlockHash   *common.Hash    `json:"blockHash,omitempty"`
@@ -948,6 +1106,7 @@ type loadedTransaction struct {
 	BlockHash   *common.Hash
 	FeeAmount   *big.Int
 	FeeBurned   *big.Int // nil if no fees were burned
+	BaseFee     *big.Int // nil before EIP-1559
 	Miner       string
 	Status      bool
 	Hash        *common.Hash
@@ -995,6 +1154,9 @@ func feeOps(tx *loadedTransaction) []*RosettaTypes.Operation {
 				Value:    new(big.Int).Neg(tx.FeeBurned).String(),
 				Currency: Currency,
 			},
+			Metadata: map[string]interface{}{
+				"subtype": FeeBurnSubtype,
+			},
 		}
 		ops = append(ops, burntOp)
 	}
@@ -1074,8 +1236,33 @@ func (ec *Client) contractCall(
 		"data": input.Data,
 	}
 
+	if len(input.From) > 0 {
+		if _, ok := ChecksumAddress(input.From); !ok {
+			return nil, ErrCallParametersInvalid
+		}
+		callParams["from"] = input.From
+	}
+	if input.Gas > 0 {
+		callParams["gas"] = hexutil.EncodeUint64(uint64(input.Gas))
+	}
+	if input.GasPrice != nil {
+		callParams["gasPrice"] = hexutil.EncodeBig(input.GasPrice.Big())
+	}
+	if input.Value != nil {
+		callParams["value"] = hexutil.EncodeBig(input.Value.Big())
+	}
+
+	args := []interface{}{callParams, blockQuery}
+	if len(input.StateOverrides) > 0 {
+		overrides, err := stateOverrides(input.StateOverrides)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, overrides)
+	}
+
 	var resp string
-	if err := ec.c.CallContext(ctx, &resp, "eth_call", callParams, blockQuery); err != nil {
+	if err := ec.c.CallContext(ctx, &resp, "eth_call", args...); err != nil {
 		return nil, err
 	}
 
@@ -1113,6 +1300,15 @@ func (ec *Client) estimateGas(
 		"to":   input.To,
 		"data": input.Data,
 	}
+	if input.Gas > 0 {
+		estimateGasParams["gas"] = hexutil.EncodeUint64(uint64(input.Gas))
+	}
+	if input.GasPrice != nil {
+		estimateGasParams["gasPrice"] = hexutil.EncodeBig(input.GasPrice.Big())
+	}
+	if input.Value != nil {
+		estimateGasParams["value"] = hexutil.EncodeBig(input.Value.Big())
+	}
 
 	var resp string
 	if err := ec.c.CallContext(ctx, &resp, "eth_estimateGas", estimateGasParams); err != nil {
@@ -1126,7 +1322,7 @@ func (ec *Client) estimateGas(
 
 func validateCallInput(params map[string]interface{}) (*GetCallInput, error) {
 	var input GetCallInput
-	if err := RosettaTypes.UnmarshalMap(params, &input); err != nil {
+	if err := unmarshalJSONMap(params, &input); err != nil {
 		return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
 	}
 
@@ -1149,13 +1345,13 @@ func (ec *Client) getParsedBlock(
 	*RosettaTypes.Block,
 	error,
 ) {
-	block, blockHash, loadedTransactions, err := ec.getBlock(ctx, blockMethod, args...)
+	block, body, loadedTransactions, err := ec.getBlock(ctx, blockMethod, args...)
 	if err != nil {
 		return nil, fmt.Errorf("%w: could not get block", err)
 	}
 
 	blockIdentifier := &RosettaTypes.BlockIdentifier{
-		Hash:  blockHash.String(),
+		Hash:  body.Hash.String(),
 		Index: block.Number().Int64(),
 	}
 
@@ -1167,7 +1363,12 @@ func (ec *Client) getParsedBlock(
 		}
 	}
 
-	txs, err := ec.populateTransactions(blockIdentifier, block, loadedTransactions)
+	txs, err := ec.populateTransactions(
+		blockIdentifier,
+		block,
+		loadedTransactions,
+		BlockOptionsFromContext(ctx),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("unable to populate transactions: %w", err)
 	}
@@ -1177,9 +1378,33 @@ func (ec *Client) getParsedBlock(
 		ParentBlockIdentifier: parentBlockIdentifier,
 		Timestamp:             convertTime(block.Time()),
 		Transactions:          txs,
+		Metadata:              blockMetadata(block, body),
 	}, nil
 }
 
+// blockMetadata returns the metadata of block, decoded from body.
+// Opera blocks are final once produced, so they are all finalized.
+//
+// The hash of an Opera block is the ID of its Atropos event, the
+// event of the DAG whose decision produced the block, which can be
+// looked up with the dag_getEvent RPC of Opera.
+func blockMetadata(block *types.Block, body *rpcBlock) map[string]interface{} {
+	metadata := map[string]interface{}{
+		"finalized":         true,
+		"epoch":             hexutil.EncodeUint64(blockEpoch(body.Hash)),
+		"atropos":           body.Hash.Hex(),
+		"gas_used":          hexutil.EncodeUint64(block.GasUsed()),
+		"gas_limit":         hexutil.EncodeUint64(block.GasLimit()),
+		"size":              body.Size.String(),
+		"transaction_count": hexutil.EncodeUint64(uint64(len(block.Transactions()))),
+	}
+	if block.BaseFee() != nil {
+		metadata["base_fee"] = hexutil.EncodeBig(block.BaseFee())
+	}
+
+	return metadata
+}
+
 func convertTime(time uint64) int64 {
 	return int64(time) * 1000
 }
@@ -1188,6 +1413,7 @@ func (ec *Client) populateTransactions(
 	blockIdentifier *RosettaTypes.BlockIdentifier,
 	block *EthTypes.Block,
 	loadedTransactions []*loadedTransaction,
+	options *BlockOptions,
 ) ([]*RosettaTypes.Transaction, error) {
 	transactions := make(
 		[]*RosettaTypes.Transaction,
@@ -1197,6 +1423,7 @@ func (ec *Client) populateTransactions(
 	for i, tx := range loadedTransactions {
 		transaction, err := ec.populateTransaction(
 			tx,
+			options,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("cannot populate tx %s: %w", tx.Hash.Hex(), err)
@@ -1210,6 +1437,7 @@ func (ec *Client) populateTransactions(
 
 func (ec *Client) populateTransaction(
 	tx *loadedTransaction,
+	options *BlockOptions,
 ) (*RosettaTypes.Transaction, error) {
 	var ops []*RosettaTypes.Operation
 
@@ -1221,27 +1449,18 @@ func (ec *Client) populateTransaction(
 	if tx.Trace != nil {
 		traces := flattenTraces(tx.Trace, []*flatCall{})
 
-		traceOps := traceOps(traces, len(ops))
+		traceOps := traceOps(traces, len(ops), options)
 		ops = append(ops, traceOps...)
 	}
 
-	// Marshal receipt and trace data
-	// TODO: replace with marshalJSONMap (used in `services`)
-	receiptBytes, err := tx.Receipt.MarshalJSON()
-	if err != nil {
-		return nil, fmt.Errorf("unable to marshal receipt: %w", err)
+	// Compute wrapped FTM operations
+	if options.IncludeWFTMOperations && tx.Receipt != nil {
+		ops = append(ops, wftmOps(tx.Receipt.Logs, ops)...)
 	}
 
-	var receiptMap map[string]interface{}
-	if err := json.Unmarshal(receiptBytes, &receiptMap); err != nil {
-		return nil, fmt.Errorf("unable to unmarshal receipt map: %w (%s)", err, receiptBytes)
-	}
-
-	var traceMap map[string]interface{}
-	if tx.RawTrace != nil {
-		if err := json.Unmarshal(tx.RawTrace, &traceMap); err != nil {
-			return nil, fmt.Errorf("unable to unmarshal trace map: %w (%s)", err, receiptBytes)
-		}
+	// Compute NFT transfer operations
+	if options.IncludeNFTOperations && tx.Receipt != nil {
+		ops = append(ops, nftOps(tx.Receipt.Logs, len(ops))...)
 	}
 
 	populatedTransaction := &RosettaTypes.Transaction{
@@ -1252,14 +1471,107 @@ func (ec *Client) populateTransaction(
 		Metadata: map[string]interface{}{
 			"gas_limit": hexutil.EncodeUint64(tx.Transaction.Gas()),
 			"gas_price": hexutil.EncodeBig(tx.Transaction.GasPrice()),
-			"receipt":   receiptMap,
-			"trace":     traceMap,
 		},
 	}
+	if err := addFeeMetadata(populatedTransaction.Metadata, tx); err != nil {
+		return nil, err
+	}
+	if err := addTransactionMetadata(populatedTransaction.Metadata, tx, options.MetadataProfile); err != nil {
+		return nil, err
+	}
+	fullProfile := options.MetadataProfile == "" || options.MetadataProfile == MetadataProfileFull
+	if ec.abis != nil && fullProfile && tx.Receipt != nil {
+		if decodedLogs := ec.abis.DecodeLogs(tx.Receipt.Logs); len(decodedLogs) > 0 {
+			populatedTransaction.Metadata["decoded_logs"] = decodedLogs
+		}
+	}
+	if tx.Trace != nil && len(tx.Trace.RevertReason) > 0 {
+		populatedTransaction.Metadata["revert_reason"] = tx.Trace.RevertReason
+	}
 
 	return populatedTransaction, nil
 }
 
+// addFeeMetadata adds the breakdown of the fee of tx to
+// metadata: its type, gas used, effective gas price and
+// burned fee and, since EIP-1559, the base fee and the
+// priority fee per gas paid to validators.
+func addFeeMetadata(metadata map[string]interface{}, tx *loadedTransaction) error {
+	gasPrice, err := effectiveGasPrice(tx.Transaction, tx.BaseFee)
+	if err != nil {
+		return fmt.Errorf("%w: failure getting effective gas price", err)
+	}
+
+	metadata["type"] = hexutil.EncodeUint64(uint64(tx.Transaction.Type()))
+	metadata["effective_gas_price"] = hexutil.EncodeBig(gasPrice)
+	if tx.Receipt != nil {
+		metadata["gas_used"] = hexutil.EncodeUint64(tx.Receipt.GasUsed)
+	}
+
+	feeBurned := new(big.Int)
+	if tx.FeeBurned != nil {
+		feeBurned = tx.FeeBurned
+	}
+	metadata["fee_burned"] = hexutil.EncodeBig(feeBurned)
+
+	if tx.BaseFee != nil {
+		metadata["base_fee"] = hexutil.EncodeBig(tx.BaseFee)
+		metadata["priority_fee"] = hexutil.EncodeBig(new(big.Int).Sub(gasPrice, tx.BaseFee))
+	}
+
+	return nil
+}
+
+// compactReceiptFields are the receipt fields included by
+// MetadataProfileCompact.
+var compactReceiptFields = []string{"status", "gasUsed", "contractAddress"}
+
+// addTransactionMetadata adds the receipt and the raw trace
+// of tx included by profile to metadata.
+func addTransactionMetadata(
+	metadata map[string]interface{},
+	tx *loadedTransaction,
+	profile MetadataProfile,
+) error {
+	if profile == MetadataProfileNone {
+		return nil
+	}
+
+	// Marshal receipt and trace data
+	// TODO: replace with marshalJSONMap (used in `services`)
+	receiptBytes, err := tx.Receipt.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("unable to marshal receipt: %w", err)
+	}
+
+	var receiptMap map[string]interface{}
+	if err := json.Unmarshal(receiptBytes, &receiptMap); err != nil {
+		return fmt.Errorf("unable to unmarshal receipt map: %w (%s)", err, receiptBytes)
+	}
+
+	if profile == MetadataProfileCompact {
+		compactReceipt := map[string]interface{}{}
+		for _, key := range compactReceiptFields {
+			if value, ok := receiptMap[key]; ok {
+				compactReceipt[key] = value
+			}
+		}
+		metadata["receipt"] = compactReceipt
+		return nil
+	}
+
+	var traceMap map[string]interface{}
+	if tx.RawTrace != nil {
+		if err := json.Unmarshal(tx.RawTrace, &traceMap); err != nil {
+			return fmt.Errorf("unable to unmarshal trace map: %w (%s)", err, receiptBytes)
+		}
+	}
+
+	metadata["receipt"] = receiptMap
+	metadata["trace"] = traceMap
+	return nil
+}
+
 type rpcProgress struct {
 	StartingBlock hexutil.Uint64
 	CurrentBlock  hexutil.Uint64
@@ -1385,14 +1697,21 @@ type GetTransactionReceiptInput struct {
 // GetCallInput is the input to the call
 // method "eth_call", "eth_estimateGas".
 type GetCallInput struct {
-	BlockIndex int64  `json:"index,omitempty"`
-	BlockHash  string `json:"hash,omitempty"`
-	From       string `json:"from"`
-	To         string `json:"to"`
-	Gas        int64  `json:"gas"`
-	GasPrice   int64  `json:"gas_price"`
-	Value      int64  `json:"value"`
-	Data       string `json:"data"`
+	BlockIndex     int64                     `json:"index,omitempty"`
+	BlockHash      string                    `json:"hash,omitempty"`
+	From           string                    `json:"from"`
+	To             string                    `json:"to"`
+	Gas            int64                     `json:"gas"`
+	GasPrice       *Quantity                 `json:"gas_price,omitempty"`
+	Value          *Quantity                 `json:"value,omitempty"`
+	Data           string                    `json:"data"`
+	StateOverrides map[string]*StateOverride `json:"state_overrides,omitempty"`
+}
+
+// GetTransactionStatusInput is the input to the call
+// method "transaction_status".
+type GetTransactionStatusInput struct {
+	TxHash string `json:"tx_hash"`
 }
 
 // Call handles calls to the /call endpoint.
@@ -1464,6 +1783,130 @@ func (ec *Client) Call(
 		return &RosettaTypes.CallResponse{
 			Result: resp,
 		}, nil
+	case "eth_getLogs":
+		resp, err := ec.getLogs(ctx, request.Parameters)
+		if err != nil {
+			return nil, err
+		}
+
+		return &RosettaTypes.CallResponse{
+			Result: resp,
+		}, nil
+	case "eth_getCode":
+		resp, err := ec.getCode(ctx, request.Parameters)
+		if err != nil {
+			return nil, err
+		}
+
+		return &RosettaTypes.CallResponse{
+			Result: resp,
+		}, nil
+	case "eth_getStorageAt":
+		resp, err := ec.getStorageAt(ctx, request.Parameters)
+		if err != nil {
+			return nil, err
+		}
+
+		return &RosettaTypes.CallResponse{
+			Result: resp,
+		}, nil
+	case "eth_getBalance":
+		resp, err := ec.getBalance(ctx, request.Parameters)
+		if err != nil {
+			return nil, err
+		}
+
+		return &RosettaTypes.CallResponse{
+			Result: resp,
+		}, nil
+	case "eth_chainId":
+		resp, err := ec.chainID(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return &RosettaTypes.CallResponse{
+			Result:     resp,
+			Idempotent: true,
+		}, nil
+	case "eth_feeHistory":
+		resp, err := ec.feeHistory(ctx, request.Parameters)
+		if err != nil {
+			return nil, err
+		}
+
+		return &RosettaTypes.CallResponse{
+			Result: resp,
+		}, nil
+	case "ftm_currentEpoch":
+		resp, err := ec.currentEpoch(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return &RosettaTypes.CallResponse{
+			Result: resp,
+		}, nil
+	case "ftm_getEpochStats":
+		resp, err := ec.epochStats(ctx, request.Parameters)
+		if err != nil {
+			return nil, err
+		}
+
+		return &RosettaTypes.CallResponse{
+			Result: resp,
+		}, nil
+	case "sfc_getValidator":
+		resp, err := ec.validator(ctx, request.Parameters)
+		if err != nil {
+			return nil, err
+		}
+
+		return &RosettaTypes.CallResponse{
+			Result: resp,
+		}, nil
+	case "dag_getEvent":
+		resp, err := ec.event(ctx, request.Parameters)
+		if err != nil {
+			return nil, err
+		}
+
+		return &RosettaTypes.CallResponse{
+			Result:     resp,
+			Idempotent: true,
+		}, nil
+	case "contract_read":
+		resp, err := ec.contractRead(ctx, request.Parameters)
+		if err != nil {
+			return nil, err
+		}
+
+		return &RosettaTypes.CallResponse{
+			Result: resp,
+		}, nil
+	case "transaction_status":
+		var input GetTransactionStatusInput
+		if err := RosettaTypes.UnmarshalMap(request.Parameters, &input); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+		}
+
+		if len(input.TxHash) == 0 {
+			return nil, fmt.Errorf("%w:tx_hash missing from params", ErrCallParametersInvalid)
+		}
+
+		tracked, err := ec.TrackedTransaction(ctx, common.HexToHash(input.TxHash))
+		if err != nil {
+			return nil, err
+		}
+
+		statusMap, err := marshalJSONMap(tracked)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrCallOutputMarshal, err.Error())
+		}
+
+		return &RosettaTypes.CallResponse{
+			Result: statusMap,
+		}, nil
 	}
 
 	return nil, fmt.Errorf("%w: %s", ErrCallMethodInvalid, request.Method)
@@ -1507,3 +1950,112 @@ func (ec *Client) GetMempool(ctx context.Context) (*RosettaTypes.MempoolResponse
 
 	return &RosettaTypes.MempoolResponse{TransactionIdentifiers: identifiers}, nil
 }
+
+// MempoolTransaction returns a transaction waiting in the Opera TxPool.
+// If the transaction was submitted through SendTransaction, its
+// tracked lifecycle is added to the transaction metadata.
+func (ec *Client) MempoolTransaction(
+	ctx context.Context,
+	transactionIdentifier *RosettaTypes.TransactionIdentifier,
+) (*RosettaTypes.Transaction, error) {
+	hash := common.HexToHash(transactionIdentifier.Hash)
+
+	var raw json.RawMessage
+	err := ec.c.CallContext(ctx, &raw, "eth_getTransactionByHash", hash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: transaction fetch failed", err)
+	}
+
+	var tracked *TrackedTransaction
+	if ec.tracker != nil {
+		if _, ok := ec.tracker.get(hash); ok {
+			tracked, err = ec.TrackedTransaction(ctx, hash)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(raw) == 0 || string(raw) == "null" {
+		if tracked != nil {
+			return nil, fmt.Errorf("%w: %s is %s", ErrTransactionNotPending, hash.Hex(), tracked.Status)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrTransactionNotPending, hash.Hex())
+	}
+
+	var body rpcTransaction
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return nil, err
+	}
+	if body.BlockHash != nil {
+		return nil, fmt.Errorf(
+			"%w: %s is included in block %s",
+			ErrTransactionNotPending,
+			hash.Hex(),
+			body.BlockHash.Hex(),
+		)
+	}
+
+	from := MustChecksum(body.From.Hex())
+	opType := CallOpType
+	if body.tx.To() == nil {
+		opType = CreateOpType
+	}
+
+	ops := []*RosettaTypes.Operation{
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{
+				Index: 0,
+			},
+			Type: opType,
+			Account: &RosettaTypes.AccountIdentifier{
+				Address: from,
+			},
+			Amount: &RosettaTypes.Amount{
+				Value:    new(big.Int).Neg(body.tx.Value()).String(),
+				Currency: Currency,
+			},
+		},
+	}
+	if body.tx.To() != nil {
+		ops = append(ops, &RosettaTypes.Operation{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{
+				Index: 1,
+			},
+			RelatedOperations: []*RosettaTypes.OperationIdentifier{
+				{
+					Index: 0,
+				},
+			},
+			Type: opType,
+			Account: &RosettaTypes.AccountIdentifier{
+				Address: MustChecksum(body.tx.To().Hex()),
+			},
+			Amount: &RosettaTypes.Amount{
+				Value:    body.tx.Value().String(),
+				Currency: Currency,
+			},
+		})
+	}
+
+	metadata := map[string]interface{}{
+		"nonce":     hexutil.EncodeUint64(body.tx.Nonce()),
+		"gas_limit": hexutil.EncodeUint64(body.tx.Gas()),
+		"gas_price": hexutil.EncodeBig(body.tx.GasPrice()),
+	}
+	if tracked != nil {
+		trackedMap, err := marshalJSONMap(tracked)
+		if err != nil {
+			return nil, err
+		}
+		metadata["tracking"] = trackedMap
+	}
+
+	return &RosettaTypes.Transaction{
+		TransactionIdentifier: &RosettaTypes.TransactionIdentifier{
+			Hash: hash.Hex(),
+		},
+		Operations: ops,
+		Metadata:   metadata,
+	}, nil
+}
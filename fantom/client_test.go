@@ -23,11 +23,13 @@ import (
 	"math/big"
 	"reflect"
 	"sort"
+	"sync"
 	"testing"
 
 	mocks "github.com/Fantom-foundation/rosetta-fantom/mocks/opera"
 
 	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -1109,6 +1111,64 @@ func TestCall_EstimateGas(t *testing.T) {
 	mockGraphQL.AssertExpectations(t)
 }
 
+func TestCall_EstimateGas_Value(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_estimateGas",
+		map[string]string{
+			"from":     "0xE550f300E477C60CE7e7172d12e5a27e9379D2e3",
+			"to":       "0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83",
+			"data":     "0xd0e30db0",
+			"gas":      "0x186a0",
+			"gasPrice": "0x3b9aca00",
+			"value":    "0xde0b6b3a7640000",
+		},
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*string)
+
+			*r = "0xafc8"
+		},
+	).Once()
+
+	resp, err := c.Call(
+		ctx,
+		&RosettaTypes.CallRequest{
+			Method: "eth_estimateGas",
+			Parameters: map[string]interface{}{
+				"from":      "0xE550f300E477C60CE7e7172d12e5a27e9379D2e3",
+				"to":        "0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83",
+				"data":      "0xd0e30db0",
+				"gas":       100000,
+				"gas_price": "0x3b9aca00",
+				"value":     "1000000000000000000",
+			},
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, &RosettaTypes.CallResponse{
+		Result: map[string]interface{}{"data": "0xafc8"},
+	}, resp)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
 func TestCall_EstimateGas_InvalidArgs(t *testing.T) {
 	mockJSONRPC := &mocks.JSONRPC{}
 	mockGraphQL := &mocks.GraphQL{}
@@ -2765,3 +2825,1683 @@ func TestGetMempool(t *testing.T) {
 
 	mockJSONRPC.AssertExpectations(t)
 }
+
+func TestMempoolTransaction(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	txHash := common.HexToHash("0xf5aaf8c5c14fc1e7ad200e3d3b5ce64fc6a211c204194a9585ac38417921ad27")
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getTransactionByHash",
+		txHash,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+
+			file, err := ioutil.ReadFile(
+				"testdata/mempool_transaction_0xf5aaf8c5c14fc1e7ad200e3d3b5ce64fc6a211c204194a9585ac38417921ad27.json", // nolint
+			)
+			assert.NoError(t, err)
+
+			*r = json.RawMessage(file)
+		},
+	).Once()
+
+	tx, err := c.MempoolTransaction(ctx, &RosettaTypes.TransactionIdentifier{
+		Hash: txHash.Hex(),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &RosettaTypes.Transaction{
+		TransactionIdentifier: &RosettaTypes.TransactionIdentifier{
+			Hash: txHash.Hex(),
+		},
+		Operations: []*RosettaTypes.Operation{
+			{
+				OperationIdentifier: &RosettaTypes.OperationIdentifier{
+					Index: 0,
+				},
+				Type: CallOpType,
+				Account: &RosettaTypes.AccountIdentifier{
+					Address: "0xFb8aF5814b2c10746d76a0da99bBDE1D9E2b6a2B",
+				},
+				Amount: &RosettaTypes.Amount{
+					Value:    "-10648452716970333",
+					Currency: Currency,
+				},
+			},
+			{
+				OperationIdentifier: &RosettaTypes.OperationIdentifier{
+					Index: 1,
+				},
+				RelatedOperations: []*RosettaTypes.OperationIdentifier{
+					{
+						Index: 0,
+					},
+				},
+				Type: CallOpType,
+				Account: &RosettaTypes.AccountIdentifier{
+					Address: "0x1fF502f9fE838cd772874cb67D0d96B93FD1d6D7",
+				},
+				Amount: &RosettaTypes.Amount{
+					Value:    "10648452716970333",
+					Currency: Currency,
+				},
+			},
+		},
+		Metadata: map[string]interface{}{
+			"nonce":     "0x0",
+			"gas_limit": "0x5208",
+			"gas_price": "0x3b9aca00",
+		},
+	}, tx)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestMempoolTransaction_NotPending(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	txHash := common.HexToHash("0xf5aaf8c5c14fc1e7ad200e3d3b5ce64fc6a211c204194a9585ac38417921ad27")
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getTransactionByHash",
+		txHash,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+
+			*r = json.RawMessage("null")
+		},
+	).Once()
+
+	tx, err := c.MempoolTransaction(ctx, &RosettaTypes.TransactionIdentifier{
+		Hash: txHash.Hex(),
+	})
+	assert.Nil(t, tx)
+	assert.True(t, errors.Is(err, ErrTransactionNotPending))
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestCall_TransactionStatus(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+		tracker:        newTxTracker(),
+	}
+
+	ctx := context.Background()
+	txHash := common.HexToHash("0xf5aaf8c5c14fc1e7ad200e3d3b5ce64fc6a211c204194a9585ac38417921ad27")
+	from := common.HexToAddress("0xFb8aF5814b2c10746d76a0da99bBDE1D9E2b6a2B")
+
+	// Transactions which were never submitted are not tracked
+	resp, err := c.Call(ctx, &RosettaTypes.CallRequest{
+		Method:     "transaction_status",
+		Parameters: map[string]interface{}{"tx_hash": txHash.Hex()},
+	})
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrTransactionNotTracked))
+
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_sendRawTransaction",
+		mock.Anything,
+	).Return(
+		nil,
+	).Once()
+
+	rawTx, err := ioutil.ReadFile("testdata/submitted_tx.json")
+	assert.NoError(t, err)
+
+	tx := new(types.Transaction)
+	assert.NoError(t, tx.UnmarshalJSON(rawTx))
+	assert.NoError(t, c.SendTransaction(ctx, tx))
+
+	// Pending in the txpool
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getTransactionReceipt",
+		txHash,
+	).Return(
+		nil,
+	).Twice()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getTransactionByHash",
+		txHash,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+
+			*r = json.RawMessage(rawTx)
+		},
+	).Once()
+
+	resp, err = c.Call(ctx, &RosettaTypes.CallRequest{
+		Method:     "transaction_status",
+		Parameters: map[string]interface{}{"tx_hash": txHash.Hex()},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, TxStatusPending, resp.Result["status"])
+	assert.Equal(t, from.Hex(), resp.Result["from"])
+	assert.Equal(t, "0x0", resp.Result["nonce"])
+	assert.Nil(t, resp.Result["block_number"])
+
+	// Left the txpool after another transaction used the nonce
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getTransactionByHash",
+		txHash,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+
+			*r = json.RawMessage("null")
+		},
+	).Once()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getTransactionCount",
+		from,
+		"latest",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*hexutil.Uint64)
+
+			*r = hexutil.Uint64(1)
+		},
+	).Once()
+
+	resp, err = c.Call(ctx, &RosettaTypes.CallRequest{
+		Method:     "transaction_status",
+		Parameters: map[string]interface{}{"tx_hash": txHash.Hex()},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, TxStatusReplaced, resp.Result["status"])
+
+	// Replaced is a final status, no further lookups are done
+	resp, err = c.Call(ctx, &RosettaTypes.CallRequest{
+		Method:     "transaction_status",
+		Parameters: map[string]interface{}{"tx_hash": txHash.Hex()},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, TxStatusReplaced, resp.Result["status"])
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestCall_TransactionStatus_Included(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+		tracker:        newTxTracker(),
+	}
+
+	ctx := context.Background()
+	txHash := common.HexToHash("0xf5aaf8c5c14fc1e7ad200e3d3b5ce64fc6a211c204194a9585ac38417921ad27")
+	blockHash := common.HexToHash("0xc10a51a3898a85c7165a9d883acc9a68f139934d0cb91dfad4c7d3a7c1a1960d")
+
+	rawTx, err := ioutil.ReadFile("testdata/submitted_tx.json")
+	assert.NoError(t, err)
+
+	tx := new(types.Transaction)
+	assert.NoError(t, tx.UnmarshalJSON(rawTx))
+
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_sendRawTransaction",
+		mock.Anything,
+	).Return(
+		nil,
+	).Once()
+	assert.NoError(t, c.SendTransaction(ctx, tx))
+
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getTransactionReceipt",
+		txHash,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(**types.Receipt)
+
+			*r = &types.Receipt{
+				TxHash:      txHash,
+				BlockHash:   blockHash,
+				BlockNumber: big.NewInt(10991),
+			}
+		},
+	).Once()
+
+	resp, err := c.Call(ctx, &RosettaTypes.CallRequest{
+		Method:     "transaction_status",
+		Parameters: map[string]interface{}{"tx_hash": txHash.Hex()},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, TxStatusIncluded, resp.Result["status"])
+	assert.Equal(t, float64(10991), resp.Result["block_number"])
+	assert.Equal(t, blockHash.Hex(), resp.Result["block_hash"])
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestCall_Call_AllFields(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_call",
+		map[string]string{
+			"from":     "0xb5e5d0f8c0cba267cd3d7035d6adc8eba7df7cdd",
+			"to":       "0xB5E5D0F8C0cbA267CD3D7035d6AdC8eBA7Df7Cdd",
+			"data":     "0x70a08231000000000000000000000000b5e5d0f8c0cba267cd3d7035d6adc8eba7df7cdd",
+			"gas":      "0x5208",
+			"gasPrice": "0x3b9aca00",
+			"value":    "0xde0b6b3a7640000",
+		},
+		"latest",
+		map[string]interface{}{
+			"0xB5E5D0F8C0cbA267CD3D7035d6AdC8eBA7Df7Cdd": map[string]interface{}{
+				"balance": "0x56bc75e2d63100000",
+				"stateDiff": map[string]string{
+					"0x0000000000000000000000000000000000000000000000000000000000000001": "0x0000000000000000000000000000000000000000000000000000000000000002", // nolint
+				},
+			},
+		},
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*string)
+
+			*r = "0x000000000000000000000000000000000000000000000036518b1b2d2d680000"
+		},
+	).Once()
+
+	resp, err := c.Call(
+		ctx,
+		&RosettaTypes.CallRequest{
+			Method: "eth_call",
+			Parameters: map[string]interface{}{
+				"from":      "0xb5e5d0f8c0cba267cd3d7035d6adc8eba7df7cdd",
+				"to":        "0xB5E5D0F8C0cbA267CD3D7035d6AdC8eBA7Df7Cdd",
+				"data":      "0x70a08231000000000000000000000000b5e5d0f8c0cba267cd3d7035d6adc8eba7df7cdd",
+				"gas":       21000,
+				"gas_price": 1000000000,
+				"value":     "1000000000000000000",
+				"state_overrides": map[string]interface{}{
+					"0xb5e5d0f8c0cba267cd3d7035d6adc8eba7df7cdd": map[string]interface{}{
+						"balance": "0x56bc75e2d63100000",
+						"state_diff": map[string]interface{}{
+							"0x0000000000000000000000000000000000000000000000000000000000000001": "0x0000000000000000000000000000000000000000000000000000000000000002", // nolint
+						},
+					},
+				},
+			},
+		},
+	)
+	assert.Equal(t, &RosettaTypes.CallResponse{
+		Result: map[string]interface{}{
+			"data": "0x000000000000000000000000000000000000000000000036518b1b2d2d680000",
+		},
+	}, resp)
+	assert.NoError(t, err)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestCall_Call_InvalidStateOverrides(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	resp, err := c.Call(
+		ctx,
+		&RosettaTypes.CallRequest{
+			Method: "eth_call",
+			Parameters: map[string]interface{}{
+				"to":   "0xB5E5D0F8C0cbA267CD3D7035d6AdC8eBA7Df7Cdd",
+				"data": "0x70a08231000000000000000000000000b5e5d0f8c0cba267cd3d7035d6adc8eba7df7cdd",
+				"state_overrides": map[string]interface{}{
+					"0xb5e5d0f8c0cba267cd3d7035d6adc8eba7df7cdd": map[string]interface{}{
+						"state": map[string]interface{}{
+							"0x01": "0x02",
+						},
+					},
+				},
+			},
+		},
+	)
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrCallParametersInvalid))
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestCall_GetLogs(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	transferTopic := "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	log := map[string]interface{}{
+		"address":         "0x21be370d5312f44cb42ce377bc9b8a0cef1a4c83",
+		"blockNumber":     "0x2ab9",
+		"logIndex":        "0x0",
+		"removed":         false,
+		"topics":          []interface{}{transferTopic},
+		"transactionHash": "0x0046a7c3ca126864a3e851235ca6bf030300f9138f035f5f190e59ff9a4b22ff",
+	}
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getLogs",
+		map[string]interface{}{
+			"fromBlock": "0x2aaf",
+			"toBlock":   "0x2ab9",
+			"address":   []string{"0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83"},
+			"topics":    []interface{}{[]string{transferTopic}, nil},
+		},
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*[]interface{})
+
+			*r = []interface{}{log}
+		},
+	).Once()
+
+	resp, err := c.Call(
+		ctx,
+		&RosettaTypes.CallRequest{
+			Method: "eth_getLogs",
+			Parameters: map[string]interface{}{
+				"from_block": 10927,
+				"to_block":   10937,
+				"addresses":  []string{"0x21be370d5312f44cb42ce377bc9b8a0cef1a4c83"},
+				"topics":     [][]string{{transferTopic}, {}},
+			},
+		},
+	)
+	assert.Equal(t, &RosettaTypes.CallResponse{
+		Result: map[string]interface{}{
+			"logs": []interface{}{log},
+		},
+	}, resp)
+	assert.NoError(t, err)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestCall_GetLogs_InvalidArgs(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	tests := map[string]map[string]interface{}{
+		"missing from_block": {},
+		"range too large": {
+			"from_block": 0,
+			"to_block":   1000,
+		},
+		"inverted range": {
+			"from_block": 10,
+			"to_block":   9,
+		},
+		"hash with range": {
+			"block_hash": "0xc10a51a3898a85c7165a9d883acc9a68f139934d0cb91dfad4c7d3a7c1a1960d",
+			"from_block": 10,
+		},
+		"invalid topic": {
+			"from_block": 10,
+			"to_block":   10,
+			"topics":     [][]string{{"0x1234"}},
+		},
+	}
+
+	for name, params := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := c.Call(ctx, &RosettaTypes.CallRequest{
+				Method:     "eth_getLogs",
+				Parameters: params,
+			})
+			assert.Nil(t, resp)
+			assert.True(t, errors.Is(err, ErrCallParametersInvalid))
+		})
+	}
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestCall_GetCode(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getCode",
+		"0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83",
+		toBlockNumArg(big.NewInt(10937)),
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*string)
+
+			*r = "0x6080604052"
+		},
+	).Once()
+
+	resp, err := c.Call(ctx, &RosettaTypes.CallRequest{
+		Method: "eth_getCode",
+		Parameters: map[string]interface{}{
+			"address": "0x21be370d5312f44cb42ce377bc9b8a0cef1a4c83",
+			"index":   10937,
+		},
+	})
+	assert.Equal(t, &RosettaTypes.CallResponse{
+		Result: map[string]interface{}{
+			"code": "0x6080604052",
+		},
+	}, resp)
+	assert.NoError(t, err)
+
+	resp, err = c.Call(ctx, &RosettaTypes.CallRequest{
+		Method: "eth_getCode",
+		Parameters: map[string]interface{}{
+			"address": "not valid",
+		},
+	})
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrCallParametersInvalid))
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestCall_GetStorageAt(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	blockHash := "0xc10a51a3898a85c7165a9d883acc9a68f139934d0cb91dfad4c7d3a7c1a1960d"
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getStorageAt",
+		"0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83",
+		"0x2",
+		blockHash,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*string)
+
+			*r = "0x0000000000000000000000000000000000000000000000000000000000000012"
+		},
+	).Once()
+
+	resp, err := c.Call(ctx, &RosettaTypes.CallRequest{
+		Method: "eth_getStorageAt",
+		Parameters: map[string]interface{}{
+			"address":  "0x21be370d5312f44cb42ce377bc9b8a0cef1a4c83",
+			"position": "0x02",
+			"hash":     blockHash,
+		},
+	})
+	assert.Equal(t, &RosettaTypes.CallResponse{
+		Result: map[string]interface{}{
+			"data": "0x0000000000000000000000000000000000000000000000000000000000000012",
+		},
+	}, resp)
+	assert.NoError(t, err)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestCall_GetBalance(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getBalance",
+		"0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83",
+		"latest",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*hexutil.Big)
+
+			*r = *(*hexutil.Big)(big.NewInt(1000000000000000000))
+		},
+	).Once()
+
+	resp, err := c.Call(ctx, &RosettaTypes.CallRequest{
+		Method: "eth_getBalance",
+		Parameters: map[string]interface{}{
+			"address": "0x21be370d5312f44cb42ce377bc9b8a0cef1a4c83",
+		},
+	})
+	assert.Equal(t, &RosettaTypes.CallResponse{
+		Result: map[string]interface{}{
+			"balance": "0xde0b6b3a7640000",
+		},
+	}, resp)
+	assert.NoError(t, err)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestCall_ChainID(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_chainId",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*hexutil.Big)
+
+			*r = *(*hexutil.Big)(big.NewInt(0xFA))
+		},
+	).Once()
+
+	resp, err := c.Call(ctx, &RosettaTypes.CallRequest{
+		Method: "eth_chainId",
+	})
+	assert.Equal(t, &RosettaTypes.CallResponse{
+		Result: map[string]interface{}{
+			"chain_id": "0xfa",
+		},
+		Idempotent: true,
+	}, resp)
+	assert.NoError(t, err)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestCall_FeeHistory(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_feeHistory",
+		"0x2",
+		"latest",
+		[]float64{25, 75},
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+
+			*r = json.RawMessage(`{"oldestBlock":"0x2ab8","baseFeePerGas":["0x1","0x1","0x1"],"gasUsedRatio":[0.5,0.25]}`) // nolint
+		},
+	).Once()
+
+	resp, err := c.Call(ctx, &RosettaTypes.CallRequest{
+		Method: "eth_feeHistory",
+		Parameters: map[string]interface{}{
+			"block_count":        2,
+			"reward_percentiles": []float64{25, 75},
+		},
+	})
+	assert.Equal(t, &RosettaTypes.CallResponse{
+		Result: map[string]interface{}{
+			"oldestBlock":   "0x2ab8",
+			"baseFeePerGas": []interface{}{"0x1", "0x1", "0x1"},
+			"gasUsedRatio":  []interface{}{0.5, 0.25},
+		},
+	}, resp)
+	assert.NoError(t, err)
+
+	resp, err = c.Call(ctx, &RosettaTypes.CallRequest{
+		Method: "eth_feeHistory",
+		Parameters: map[string]interface{}{
+			"block_count":        2,
+			"reward_percentiles": []float64{75, 25},
+		},
+	})
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrCallParametersInvalid))
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestCall_CurrentEpoch(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"ftm_currentEpoch",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*hexutil.Uint64)
+
+			*r = hexutil.Uint64(0x1a2b)
+		},
+	).Once()
+
+	resp, err := c.Call(ctx, &RosettaTypes.CallRequest{
+		Method: "ftm_currentEpoch",
+	})
+	assert.Equal(t, &RosettaTypes.CallResponse{
+		Result: map[string]interface{}{
+			"epoch": "0x1a2b",
+		},
+	}, resp)
+	assert.NoError(t, err)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestCall_GetEpochStats(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	stats := map[string]interface{}{
+		"epoch":                 "0x1a2a",
+		"start":                 "0x16d3a1f1c6e2b3d0",
+		"end":                   "0x16d3a20b8f0a9c40",
+		"totalFee":              "0x2c68af0bb140000",
+		"totalBaseRewardWeight": "0x0",
+		"totalTxRewardWeight":   "0x0",
+	}
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"ftm_getEpochStats",
+		"0x1a2a",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*map[string]interface{})
+
+			*r = stats
+		},
+	).Once()
+
+	resp, err := c.Call(ctx, &RosettaTypes.CallRequest{
+		Method: "ftm_getEpochStats",
+		Parameters: map[string]interface{}{
+			"epoch": 0x1a2a,
+		},
+	})
+	assert.Equal(t, &RosettaTypes.CallResponse{
+		Result: stats,
+	}, resp)
+	assert.NoError(t, err)
+
+	resp, err = c.Call(ctx, &RosettaTypes.CallRequest{
+		Method: "ftm_getEpochStats",
+		Parameters: map[string]interface{}{
+			"epoch": -1,
+		},
+	})
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrCallParametersInvalid))
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestCall_GetValidator(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	auth := common.HexToAddress("0x541e408443A592C38e01Bed0cB31f9De8c1322d0")
+	output, err := sfcABI.Methods["getValidator"].Outputs.Pack(
+		big.NewInt(0),
+		big.NewInt(0),
+		big.NewInt(0),
+		new(big.Int).Mul(big.NewInt(3175000), big.NewInt(1e18)),
+		big.NewInt(1),
+		big.NewInt(1577419000),
+		auth,
+	)
+	assert.NoError(t, err)
+
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_call",
+		map[string]string{
+			"to":   "0xFC00FACE00000000000000000000000000000000",
+			"data": "0xb5d896270000000000000000000000000000000000000000000000000000000000000001",
+		},
+		"latest",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*string)
+
+			*r = hexutil.Encode(output)
+		},
+	).Once()
+
+	resp, err := c.Call(ctx, &RosettaTypes.CallRequest{
+		Method: "sfc_getValidator",
+		Parameters: map[string]interface{}{
+			"validator_id": 1,
+		},
+	})
+	assert.Equal(t, &RosettaTypes.CallResponse{
+		Result: map[string]interface{}{
+			"validator_id":      int64(1),
+			"status":            "0",
+			"deactivated_time":  "0",
+			"deactivated_epoch": "0",
+			"received_stake":    "3175000000000000000000000",
+			"created_epoch":     "1",
+			"created_time":      "1577419000",
+			"auth":              auth.Hex(),
+		},
+	}, resp)
+	assert.NoError(t, err)
+
+	resp, err = c.Call(ctx, &RosettaTypes.CallRequest{
+		Method:     "sfc_getValidator",
+		Parameters: map[string]interface{}{},
+	})
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrCallParametersInvalid))
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestCall_GetEvent(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	eventID := "0x00001a2b0000054c3c4f5aa2e6e11e1dcd7bd7d1b4f2c46e3cbf3f06b6d38e06"
+	event := map[string]interface{}{
+		"id":      eventID,
+		"epoch":   "0x1a2b",
+		"seq":     "0x5",
+		"frame":   "0x3",
+		"creator": "0xb",
+		"parents": []interface{}{},
+	}
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"dag_getEvent",
+		eventID,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*map[string]interface{})
+
+			*r = event
+		},
+	).Once()
+
+	resp, err := c.Call(ctx, &RosettaTypes.CallRequest{
+		Method: "dag_getEvent",
+		Parameters: map[string]interface{}{
+			"event_id": eventID,
+		},
+	})
+	assert.Equal(t, &RosettaTypes.CallResponse{
+		Result:     event,
+		Idempotent: true,
+	}, resp)
+	assert.NoError(t, err)
+
+	resp, err = c.Call(ctx, &RosettaTypes.CallRequest{
+		Method: "dag_getEvent",
+		Parameters: map[string]interface{}{
+			"event_id": "0x1234",
+		},
+	})
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrCallParametersInvalid))
+
+	mockJSONRPC.AssertExpectations(t)
+	mockGraphQL.AssertExpectations(t)
+}
+
+func TestCall_ContractRead_Signature(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_call",
+		map[string]string{
+			"to":   "0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83",
+			"data": "0x70a08231000000000000000000000000fb8af5814b2c10746d76a0da99bbde1d9e2b6a2b",
+		},
+		"0x2af8",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*string)
+
+			*r = "0x00000000000000000000000000000000000000000000003635c9adc5dea00000"
+		},
+	).Once()
+
+	index := int64(11000)
+	resp, err := c.Call(ctx, &RosettaTypes.CallRequest{
+		Method: "contract_read",
+		Parameters: map[string]interface{}{
+			"index":  index,
+			"to":     "0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83",
+			"method": "function balanceOf(address owner) external view returns (uint balance)",
+			"args":   []interface{}{"0xFb8aF5814b2c10746d76a0da99bBDE1D9E2b6a2B"},
+		},
+	})
+	assert.Equal(t, &RosettaTypes.CallResponse{
+		Result: map[string]interface{}{
+			"method": "balanceOf(address)",
+			"data":   "0x00000000000000000000000000000000000000000000003635c9adc5dea00000",
+			"outputs": []interface{}{
+				map[string]interface{}{
+					"name":  "balance",
+					"type":  "uint256",
+					"value": "1000000000000000000000",
+				},
+			},
+		},
+	}, resp)
+	assert.NoError(t, err)
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestCall_ContractRead_ABI(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	output, err := abi.Arguments{
+		{Type: mustNewType(t, "string")},
+		{Type: mustNewType(t, "uint8")},
+	}.Pack("WFTM", uint8(18))
+	assert.NoError(t, err)
+
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_call",
+		map[string]string{
+			"to":   "0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83",
+			"from": "0xFb8aF5814b2c10746d76a0da99bBDE1D9E2b6a2B",
+			"data": "0x95d89b41",
+		},
+		"latest",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*string)
+
+			*r = hexutil.Encode(output)
+		},
+	).Once()
+
+	resp, err := c.Call(ctx, &RosettaTypes.CallRequest{
+		Method: "contract_read",
+		Parameters: map[string]interface{}{
+			"to":   "0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83",
+			"from": "0xFb8aF5814b2c10746d76a0da99bBDE1D9E2b6a2B",
+			"abi": map[string]interface{}{
+				"type":            "function",
+				"name":            "symbol",
+				"stateMutability": "view",
+				"inputs":          []interface{}{},
+				"outputs": []interface{}{
+					map[string]interface{}{"name": "symbol", "type": "string"},
+					map[string]interface{}{"name": "decimals", "type": "uint8"},
+				},
+			},
+		},
+	})
+	assert.Equal(t, &RosettaTypes.CallResponse{
+		Result: map[string]interface{}{
+			"method": "symbol()",
+			"data":   hexutil.Encode(output),
+			"outputs": []interface{}{
+				map[string]interface{}{
+					"name":  "symbol",
+					"type":  "string",
+					"value": "WFTM",
+				},
+				map[string]interface{}{
+					"name":  "decimals",
+					"type":  "uint8",
+					"value": "18",
+				},
+			},
+		},
+	}, resp)
+	assert.NoError(t, err)
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestCall_ContractRead_InvalidArgs(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	tests := map[string]map[string]interface{}{
+		"invalid contract": {
+			"to":     "0x123",
+			"method": "totalSupply()(uint256)",
+		},
+		"missing method": {
+			"to": "0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83",
+		},
+		"invalid signature": {
+			"to":     "0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83",
+			"method": "balanceOf(address",
+		},
+		"wrong number of args": {
+			"to":     "0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83",
+			"method": "balanceOf(address)(uint256)",
+		},
+		"invalid arg": {
+			"to":     "0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83",
+			"method": "allowance(address,address)(uint256)",
+			"args":   []interface{}{"0xFb8aF5814b2c10746d76a0da99bBDE1D9E2b6a2B", 12},
+		},
+		"overflow": {
+			"to":     "0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83",
+			"method": "get(uint8)(uint256)",
+			"args":   []interface{}{"256"},
+		},
+	}
+
+	for name, params := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := c.Call(ctx, &RosettaTypes.CallRequest{
+				Method:     "contract_read",
+				Parameters: params,
+			})
+			assert.Nil(t, resp)
+			assert.True(t, errors.Is(err, ErrCallParametersInvalid))
+		})
+	}
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func mustNewType(t *testing.T, typeName string) abi.Type {
+	typ, err := abi.NewType(typeName, "", nil)
+	assert.NoError(t, err)
+
+	return typ
+}
+
+func TestDecodeRevertReason(t *testing.T) {
+	tests := map[string]struct {
+		data     string
+		expected string
+	}{
+		"error": {
+			data: "0x08c379a0" +
+				"0000000000000000000000000000000000000000000000000000000000000020" +
+				"000000000000000000000000000000000000000000000000000000000000001a" +
+				"4e6f7420656e6f7567682045746865722070726f76696465642e000000000000",
+			expected: "Not enough Ether provided.",
+		},
+		"panic": {
+			data:     "0x4e487b710000000000000000000000000000000000000000000000000000000000000011",
+			expected: "Panic(0x11): arithmetic underflow or overflow",
+		},
+		"unknown panic": {
+			data:     "0x4e487b710000000000000000000000000000000000000000000000000000000000000099",
+			expected: "Panic(0x99): unknown panic code",
+		},
+		"custom error": {
+			data: "0xe450d38c" +
+				"000000000000000000000000fb8af5814b2c10746d76a0da99bbde1d9e2b6a2b" +
+				"0000000000000000000000000000000000000000000000000000000000000001" +
+				"0000000000000000000000000000000000000000000000000000000000000002",
+			expected: "ERC20InsufficientBalance(sender=0xFb8aF5814b2c10746d76a0da99bBDE1D9E2b6a2B, balance=1, needed=2)",
+		},
+		"unknown custom error": {
+			data:     "0xdeadbeef",
+			expected: "",
+		},
+		"empty": {
+			data:     "0x",
+			expected: "",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, decodeRevertReason(hexutil.MustDecode(test.data)))
+		})
+	}
+}
+
+func TestTraceOps_RevertReason(t *testing.T) {
+	raw := `{
+		"type": "CALL",
+		"from": "0xfb8af5814b2c10746d76a0da99bbde1d9e2b6a2b",
+		"to": "0x21be370d5312f44cb42ce377bc9b8a0cef1a4c83",
+		"value": "0x1",
+		"gas": "0x5208",
+		"gasUsed": "0x5208",
+		"input": "0x",
+		"output": "0x4e487b710000000000000000000000000000000000000000000000000000000000000001",
+		"error": "execution reverted",
+		"calls": [
+			{
+				"type": "CALL",
+				"from": "0x21be370d5312f44cb42ce377bc9b8a0cef1a4c83",
+				"to": "0x1ff502f9fe838cd772874cb67d0d96b93fd1d6d7",
+				"value": "0x1",
+				"gas": "0x5208",
+				"gasUsed": "0x5208",
+				"input": "0x",
+				"output": "0x"
+			}
+		]
+	}`
+
+	var call Call
+	assert.NoError(t, json.Unmarshal([]byte(raw), &call))
+	assert.True(t, call.Revert)
+	assert.Equal(t, "Panic(0x01): assert(false)", call.RevertReason)
+
+	ops := traceOps(flattenTraces(&call, []*flatCall{}), 0, &BlockOptions{})
+	assert.Len(t, ops, 4)
+	for _, op := range ops {
+		assert.Equal(t, FailureStatus, *op.Status)
+		assert.Equal(t, "execution reverted", op.Metadata["error"])
+		assert.Equal(t, "Panic(0x01): assert(false)", op.Metadata["revert_reason"])
+	}
+}
+
+func TestReady(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	ctx := context.Background()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_blockNumber",
+	).Return(
+		errors.New("connection refused"),
+	).Once()
+	assert.True(t, errors.Is(c.Ready(ctx), ErrOperaNotReady))
+
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_blockNumber",
+	).Return(
+		nil,
+	).Once()
+	assert.True(t, errors.Is(c.Ready(ctx), ErrOperaNotReady))
+
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_blockNumber",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*hexutil.Uint64)
+
+			*r = hexutil.Uint64(10)
+		},
+	).Twice()
+	assert.NoError(t, c.Ready(ctx))
+
+	// Readiness is cached
+	assert.NoError(t, c.Ready(ctx))
+
+	c.MarkNotReady()
+	assert.NoError(t, c.Ready(ctx))
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestReady_MarkNotReady(t *testing.T) {
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockGraphQL := &mocks.GraphQL{}
+
+	c := &Client{
+		c:              mockJSONRPC,
+		g:              mockGraphQL,
+		traceSemaphore: semaphore.NewWeighted(100),
+	}
+
+	// Opera is restarted while readiness is checked
+	ctx := context.Background()
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_blockNumber",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			c.MarkNotReady()
+
+			r := args.Get(1).(*hexutil.Uint64)
+			*r = hexutil.Uint64(10)
+		},
+	).Once()
+	assert.True(t, errors.Is(c.Ready(ctx), ErrOperaNotReady))
+
+	mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_blockNumber",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*hexutil.Uint64)
+			*r = hexutil.Uint64(10)
+		},
+	)
+	assert.NoError(t, c.Ready(ctx))
+
+	// Readiness is reset while requests are in flight
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = c.Ready(ctx)
+		}()
+		go func() {
+			defer wg.Done()
+			c.MarkNotReady()
+		}()
+	}
+	wg.Wait()
+
+	c.MarkNotReady()
+	assert.NoError(t, c.Ready(ctx))
+	assert.NoError(t, c.Ready(ctx))
+}
+
+func TestTraceOps_ZeroValueCalls(t *testing.T) {
+	raw := `{
+		"type": "CALL",
+		"from": "0xfb8af5814b2c10746d76a0da99bbde1d9e2b6a2b",
+		"to": "0x21be370d5312f44cb42ce377bc9b8a0cef1a4c83",
+		"value": "0x1",
+		"gas": "0x15f90",
+		"gasUsed": "0xa410",
+		"input": "0xd0e30db0",
+		"output": "0x",
+		"calls": [
+			{
+				"type": "DELEGATECALL",
+				"from": "0x21be370d5312f44cb42ce377bc9b8a0cef1a4c83",
+				"to": "0x1ff502f9fe838cd772874cb67d0d96b93fd1d6d7",
+				"gas": "0xea60",
+				"gasUsed": "0x5208",
+				"input": "0xa9059cbb000000000000000000000000fb8af5814b2c10746d76a0da99bbde1d9e2b6a2b",
+				"output": "0x",
+				"calls": [
+					{
+						"type": "STATICCALL",
+						"from": "0x21be370d5312f44cb42ce377bc9b8a0cef1a4c83",
+						"to": "0xfb8af5814b2c10746d76a0da99bbde1d9e2b6a2b",
+						"gas": "0x7530",
+						"gasUsed": "0x3e8",
+						"input": "0x",
+						"output": "0x"
+					}
+				]
+			}
+		]
+	}`
+
+	var call Call
+	assert.NoError(t, json.Unmarshal([]byte(raw), &call))
+	assert.Equal(t, big.NewInt(0xa410), call.GasUsed)
+
+	// Zero-value calls are skipped by default
+	ops := traceOps(flattenTraces(&call, []*flatCall{}), 0, &BlockOptions{})
+	assert.Len(t, ops, 2)
+	for _, op := range ops {
+		assert.Equal(t, map[string]interface{}{
+			"call_type":     CallOpType,
+			"selector":      "0xd0e30db0",
+			"depth":         0,
+			"trace_address": []int{},
+		}, op.Metadata)
+	}
+
+	ops = traceOps(flattenTraces(&call, []*flatCall{}), 1, &BlockOptions{IncludeZeroValueCalls: true})
+	assert.Len(t, ops, 6)
+
+	expected := []struct {
+		opType   string
+		amount   string
+		related  []int64
+		metadata map[string]interface{}
+	}{
+		{CallOpType, "-1", nil, map[string]interface{}{
+			"call_type": CallOpType, "selector": "0xd0e30db0", "gas_used": "42000", "depth": 0, "trace_address": []int{},
+		}},
+		{CallOpType, "1", []int64{1}, map[string]interface{}{
+			"call_type": CallOpType, "selector": "0xd0e30db0", "gas_used": "42000", "depth": 0, "trace_address": []int{},
+		}},
+		{DelegateCallOpType, "", []int64{2}, map[string]interface{}{
+			"call_type": DelegateCallOpType, "selector": "0xa9059cbb", "gas_used": "21000", "depth": 1, "trace_address": []int{0},
+		}},
+		{DelegateCallOpType, "", []int64{3}, map[string]interface{}{
+			"call_type": DelegateCallOpType, "selector": "0xa9059cbb", "gas_used": "21000", "depth": 1, "trace_address": []int{0},
+		}},
+		{StaticCallOpType, "", []int64{4}, map[string]interface{}{
+			"call_type": StaticCallOpType, "gas_used": "1000", "depth": 2, "trace_address": []int{0, 0},
+		}},
+		{StaticCallOpType, "", []int64{5}, map[string]interface{}{
+			"call_type": StaticCallOpType, "gas_used": "1000", "depth": 2, "trace_address": []int{0, 0},
+		}},
+	}
+	for i, op := range ops {
+		assert.Equal(t, int64(i+1), op.OperationIdentifier.Index)
+		assert.Equal(t, expected[i].opType, op.Type)
+		assert.Equal(t, SuccessStatus, *op.Status)
+		assert.Equal(t, expected[i].metadata, op.Metadata)
+		if len(expected[i].amount) == 0 {
+			assert.Nil(t, op.Amount)
+		} else {
+			assert.Equal(t, expected[i].amount, op.Amount.Value)
+		}
+
+		var related []int64
+		for _, relatedOp := range op.RelatedOperations {
+			related = append(related, relatedOp.Index)
+		}
+		assert.Equal(t, expected[i].related, related)
+	}
+}
+
+func TestTraceOps_CallTree(t *testing.T) {
+	raw := `{
+		"type": "CALL",
+		"from": "0xfb8af5814b2c10746d76a0da99bbde1d9e2b6a2b",
+		"to": "0x21be370d5312f44cb42ce377bc9b8a0cef1a4c83",
+		"value": "0x0",
+		"gasUsed": "0xa410",
+		"input": "0x2e1a7d4d",
+		"calls": [
+			{
+				"type": "STATICCALL",
+				"from": "0x21be370d5312f44cb42ce377bc9b8a0cef1a4c83",
+				"to": "0x1ff502f9fe838cd772874cb67d0d96b93fd1d6d7",
+				"gasUsed": "0x3e8",
+				"input": "0x70a08231"
+			},
+			{
+				"type": "CALL",
+				"from": "0x21be370d5312f44cb42ce377bc9b8a0cef1a4c83",
+				"to": "0x1ff502f9fe838cd772874cb67d0d96b93fd1d6d7",
+				"value": "0x2",
+				"gasUsed": "0x5208",
+				"input": "0x",
+				"calls": [
+					{
+						"type": "CALL",
+						"from": "0x1ff502f9fe838cd772874cb67d0d96b93fd1d6d7",
+						"to": "0xfb8af5814b2c10746d76a0da99bbde1d9e2b6a2b",
+						"value": "0x1",
+						"gasUsed": "0x5208",
+						"input": "0x"
+					}
+				]
+			}
+		]
+	}`
+
+	var call Call
+	assert.NoError(t, json.Unmarshal([]byte(raw), &call))
+
+	traces := flattenTraces(&call, []*flatCall{})
+	assert.Len(t, traces, 4)
+	assert.Equal(t, []int{}, traces[0].TraceAddress)
+	assert.Equal(t, []int{0}, traces[1].TraceAddress)
+	assert.Equal(t, []int{1}, traces[2].TraceAddress)
+	assert.Equal(t, []int{1, 0}, traces[3].TraceAddress)
+
+	// Calls relate to the operation of the account making
+	// them, which is skipped for zero-value parents.
+	ops := traceOps(traces, 0, &BlockOptions{})
+	assert.Len(t, ops, 4)
+	assert.Nil(t, ops[0].RelatedOperations)
+	assert.Equal(t, []int{1}, ops[0].Metadata["trace_address"])
+	assert.Equal(t, []*RosettaTypes.OperationIdentifier{{Index: 0}}, ops[1].RelatedOperations)
+	assert.Equal(t, []*RosettaTypes.OperationIdentifier{{Index: 1}}, ops[2].RelatedOperations)
+	assert.Equal(t, []int{1, 0}, ops[2].Metadata["trace_address"])
+	assert.Equal(t, 2, ops[2].Metadata["depth"])
+	assert.Equal(t, []*RosettaTypes.OperationIdentifier{{Index: 2}}, ops[3].RelatedOperations)
+
+	ops = traceOps(traces, 0, &BlockOptions{IncludeZeroValueCalls: true})
+	assert.Len(t, ops, 8)
+	assert.Equal(t, "0x2e1a7d4d", ops[0].Metadata["selector"])
+	assert.Equal(t, []*RosettaTypes.OperationIdentifier{{Index: 1}}, ops[2].RelatedOperations)
+	assert.Equal(t, "0x70a08231", ops[2].Metadata["selector"])
+	assert.Equal(t, []*RosettaTypes.OperationIdentifier{{Index: 1}}, ops[4].RelatedOperations)
+	assert.Equal(t, []*RosettaTypes.OperationIdentifier{{Index: 5}}, ops[6].RelatedOperations)
+}
+
+func TestAddTransactionMetadata(t *testing.T) {
+	txHash := "0x9cc8e6a09ae9cbdb7da77515110a8e343a945df4269c53842dd26969d32c6cc4"
+	receiptFile, err := ioutil.ReadFile("testdata/tx_receipt_" + txHash + ".json")
+	assert.NoError(t, err)
+	receipt := new(types.Receipt)
+	assert.NoError(t, receipt.UnmarshalJSON(receiptFile))
+	rawTrace, err := ioutil.ReadFile("testdata/transaction_trace_" + txHash + ".json")
+	assert.NoError(t, err)
+
+	tx := &loadedTransaction{
+		RawTrace: rawTrace,
+		Receipt:  receipt,
+	}
+
+	tests := map[MetadataProfile][]string{
+		"":                     {"receipt", "trace"},
+		MetadataProfileFull:    {"receipt", "trace"},
+		MetadataProfileCompact: {"receipt"},
+		MetadataProfileNone:    nil,
+	}
+	for profile, keys := range tests {
+		t.Run(string(profile), func(t *testing.T) {
+			metadata := map[string]interface{}{}
+			assert.NoError(t, addTransactionMetadata(metadata, tx, profile))
+
+			var metadataKeys []string
+			for key := range metadata {
+				metadataKeys = append(metadataKeys, key)
+			}
+			assert.ElementsMatch(t, keys, metadataKeys)
+
+			if receiptMap, ok := metadata["receipt"].(map[string]interface{}); ok {
+				_, logs := receiptMap["logs"]
+				assert.Equal(t, profile != MetadataProfileCompact, logs)
+				_, bloom := receiptMap["logsBloom"]
+				assert.Equal(t, profile != MetadataProfileCompact, bloom)
+				assert.Contains(t, receiptMap, "status")
+				assert.Contains(t, receiptMap, "gasUsed")
+				assert.Contains(t, receiptMap, "contractAddress")
+			}
+		})
+	}
+}
+
+func TestAddFeeMetadata(t *testing.T) {
+	to := common.HexToAddress("0x57b414a0332b5cab885a451c2a28a07d1e9b8a8d")
+	baseFee := big.NewInt(100)
+	tx := &loadedTransaction{
+		Transaction: types.NewTx(&types.DynamicFeeTx{
+			To:        &to,
+			Gas:       30000,
+			GasTipCap: big.NewInt(20),
+			GasFeeCap: big.NewInt(150),
+		}),
+		FeeBurned: big.NewInt(2100000),
+		BaseFee:   baseFee,
+		Receipt:   &types.Receipt{GasUsed: 21000},
+	}
+
+	metadata := map[string]interface{}{}
+	assert.NoError(t, addFeeMetadata(metadata, tx))
+	assert.Equal(t, map[string]interface{}{
+		"type":                "0x2",
+		"gas_used":            "0x5208",
+		"effective_gas_price": "0x78",
+		"base_fee":            "0x64",
+		"priority_fee":        "0x14",
+		"fee_burned":          "0x200b20",
+	}, metadata)
+
+	// Legacy transactions before EIP-1559 burn no fees
+	tx = &loadedTransaction{
+		Transaction: types.NewTransaction(0, to, new(big.Int), 21000, big.NewInt(120), nil),
+		Receipt:     &types.Receipt{GasUsed: 21000},
+	}
+	metadata = map[string]interface{}{}
+	assert.NoError(t, addFeeMetadata(metadata, tx))
+	assert.Equal(t, map[string]interface{}{
+		"type":                "0x0",
+		"gas_used":            "0x5208",
+		"effective_gas_price": "0x78",
+		"fee_burned":          "0x0",
+	}, metadata)
+}
+
+func TestParseMetadataProfile(t *testing.T) {
+	profile, err := ParseMetadataProfile("COMPACT")
+	assert.NoError(t, err)
+	assert.Equal(t, MetadataProfileCompact, profile)
+
+	_, err = ParseMetadataProfile("tiny")
+	assert.EqualError(t, err, "tiny is not a valid metadata profile")
+}
@@ -0,0 +1,227 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ContractReadInput is the input to the call
+// method "contract_read".
+//
+// The method to call is either described by a human-readable
+// signature in Method (e.g. "balanceOf(address)(uint256)") or
+// by a JSON ABI fragment in ABI. When ABI contains several
+// methods, Method selects one of them by name, or by its full
+// signature (e.g. "transfer(address,uint256)") if it is overloaded.
+type ContractReadInput struct {
+	BlockIndex *int64        `json:"index,omitempty"`
+	BlockHash  string        `json:"hash,omitempty"`
+	To         string        `json:"to"`
+	From       string        `json:"from,omitempty"`
+	Method     string        `json:"method,omitempty"`
+	ABI        interface{}   `json:"abi,omitempty"`
+	Args       []interface{} `json:"args,omitempty"`
+}
+
+// contractMethod resolves the method described by the input.
+func contractMethod(input *ContractReadInput) (*abi.Method, error) {
+	if input.ABI == nil {
+		if len(input.Method) == 0 {
+			return nil, fmt.Errorf("%w: method or abi missing from params", ErrCallParametersInvalid)
+		}
+
+		method, err := parseMethodSignature(input.Method)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+		}
+
+		return method, nil
+	}
+
+	// The ABI may be provided as a JSON string, a single
+	// fragment or a list of fragments.
+	var definition []byte
+	if s, ok := input.ABI.(string); ok {
+		definition = []byte(s)
+	} else {
+		encoded, err := json.Marshal(input.ABI)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+		}
+		definition = encoded
+	}
+
+	definition = bytes.TrimSpace(definition)
+	if bytes.HasPrefix(definition, []byte("{")) {
+		definition = append(append([]byte("["), definition...), ']')
+	}
+
+	parsed, err := abi.JSON(bytes.NewReader(definition))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+	}
+
+	if len(input.Method) > 0 {
+		return abiMethod(parsed, input.Method)
+	}
+
+	if len(parsed.Methods) != 1 {
+		return nil, fmt.Errorf(
+			"%w: abi contains %d methods, method must be provided",
+			ErrCallParametersInvalid,
+			len(parsed.Methods),
+		)
+	}
+
+	for _, method := range parsed.Methods {
+		m := method
+		return &m, nil
+	}
+
+	return nil, nil
+}
+
+// abiMethod returns the method of parsed selected by method, either
+// a name or a full signature (e.g. "transfer(address,uint256)").
+// Overloaded methods can only be selected by their full signature.
+func abiMethod(parsed abi.ABI, method string) (*abi.Method, error) {
+	var matches []abi.Method
+	if strings.Contains(method, "(") {
+		// Signatures with tuples are not parsed and must
+		// match the canonical signature
+		sig := strings.Join(strings.Fields(method), "")
+		if signature, err := parseMethodSignature(method); err == nil {
+			sig = signature.Sig
+		}
+
+		for _, m := range parsed.Methods {
+			if m.Sig == sig {
+				matches = append(matches, m)
+			}
+		}
+	} else {
+		for _, m := range parsed.Methods {
+			if m.RawName == strings.TrimSpace(method) {
+				matches = append(matches, m)
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: method %s not found in abi", ErrCallParametersInvalid, method)
+	case 1:
+		return &matches[0], nil
+	default:
+		return nil, fmt.Errorf(
+			"%w: method %s is overloaded, a full signature must be provided",
+			ErrCallParametersInvalid,
+			method,
+		)
+	}
+}
+
+// contractRead calls a read-only contract method, encoding the
+// arguments and decoding the outputs with the method ABI.
+func (ec *Client) contractRead(
+	ctx context.Context,
+	params map[string]interface{},
+) (map[string]interface{}, error) {
+	var input ContractReadInput
+	if err := RosettaTypes.UnmarshalMap(params, &input); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+	}
+
+	if !common.IsHexAddress(input.To) {
+		return nil, fmt.Errorf("%w: %s is not a valid contract address", ErrCallParametersInvalid, input.To)
+	}
+
+	if len(input.From) > 0 && !common.IsHexAddress(input.From) {
+		return nil, fmt.Errorf("%w: %s is not a valid address", ErrCallParametersInvalid, input.From)
+	}
+
+	if err := validateBlockQuery(input.BlockIndex, input.BlockHash); err != nil {
+		return nil, err
+	}
+
+	method, err := contractMethod(&input)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(input.Args) != len(method.Inputs) {
+		return nil, fmt.Errorf(
+			"%w: %s expects %d args but got %d",
+			ErrCallParametersInvalid,
+			method.Sig,
+			len(method.Inputs),
+			len(input.Args),
+		)
+	}
+
+	args := make([]interface{}, len(input.Args))
+	for i, arg := range input.Args {
+		value, err := abiValue(method.Inputs[i].Type, arg)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid arg %d: %s", ErrCallParametersInvalid, i, err.Error())
+		}
+		args[i] = value.Interface()
+	}
+
+	packed, err := method.Inputs.Pack(args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallParametersInvalid, err.Error())
+	}
+
+	callParams := map[string]string{
+		"to":   input.To,
+		"data": hexutil.Encode(append(method.ID, packed...)),
+	}
+	if len(input.From) > 0 {
+		callParams["from"] = input.From
+	}
+
+	var resp string
+	query := blockArg(input.BlockIndex, input.BlockHash)
+	if err := ec.c.CallContext(ctx, &resp, "eth_call", callParams, query); err != nil {
+		return nil, err
+	}
+
+	output, err := hexutil.Decode(resp)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallOutputMarshal, err.Error())
+	}
+
+	values, err := method.Outputs.Unpack(output)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCallOutputMarshal, err.Error())
+	}
+
+	return map[string]interface{}{
+		"method":  method.Sig,
+		"data":    resp,
+		"outputs": abiArgumentsJSON(method.Outputs, values),
+	}, nil
+}
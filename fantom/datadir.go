@@ -0,0 +1,482 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/md5"  // #nosec G501
+	"crypto/sha1" // #nosec G505
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Exit codes of the datadir preparation. These are the
+// exit codes historically used by run.sh.
+const (
+	ExitCodeGenesisDownload  = 51
+	ExitCodeGenesisChecksum  = 52
+	ExitCodeInvalidConfig    = 53
+	ExitCodeSnapshotDownload = 54
+	ExitCodeSnapshotChecksum = 55
+	ExitCodeSnapshotExtract  = 56
+)
+
+const (
+	// DefaultGenesisBaseURL is where genesis files
+	// are downloaded from by default.
+	DefaultGenesisBaseURL = "https://opera.fantom.network/"
+
+	// DefaultSnapshotBaseURL is where snapshot archives
+	// are downloaded from by default.
+	DefaultSnapshotBaseURL = "https://download.fantom.network/snap/"
+
+	// chaindataDir is the directory created by Opera
+	// (or extracted from a snapshot) in the datadir.
+	chaindataDir = "chaindata"
+
+	// partialSuffix is appended to files being downloaded
+	// so that interrupted downloads can be resumed.
+	partialSuffix = ".part"
+
+	// downloadProgressInterval is how often the progress
+	// of a download is logged.
+	downloadProgressInterval = 10 * time.Second
+)
+
+// DatadirFile is a genesis file or a snapshot archive
+// used to initialize the Opera datadir.
+type DatadirFile struct {
+	// Name is the name of the file in the datadir and
+	// at BaseURL. An absolute path refers to a locally
+	// provided file that is never downloaded.
+	Name string
+
+	// Checksum is the expected digest of the file, either
+	// prefixed with its algorithm (e.g. "sha256:<hex>")
+	// or bare, in which case the algorithm is inferred
+	// from its length (md5, sha1, sha256 or sha512).
+	Checksum string
+
+	// BaseURL is the URL the file is downloaded from.
+	BaseURL string
+}
+
+// DatadirConfig describes how to prepare the Opera datadir.
+type DatadirConfig struct {
+	Dir      string
+	Genesis  *DatadirFile
+	Snapshot *DatadirFile
+}
+
+// DatadirError is returned when the preparation of the
+// datadir fails. Code is used as the process exit code.
+type DatadirError struct {
+	Code int
+	Err  error
+}
+
+// Error returns the message of the underlying error.
+func (e *DatadirError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *DatadirError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code of the error.
+func (e *DatadirError) ExitCode() int {
+	return e.Code
+}
+
+// PrepareDatadir downloads (if needed) and verifies the genesis
+// file and, when the chain database does not exist yet, downloads,
+// verifies and extracts the snapshot archive.
+func PrepareDatadir(ctx context.Context, cfg *DatadirConfig) error {
+	if err := os.MkdirAll(cfg.Dir, 0750); err != nil { // nolint:gomnd
+		return &DatadirError{
+			Code: ExitCodeInvalidConfig,
+			Err:  fmt.Errorf("%w: unable to create datadir %s", err, cfg.Dir),
+		}
+	}
+
+	if cfg.Genesis != nil && len(cfg.Genesis.Name) > 0 {
+		path, err := fetchDatadirFile(ctx, cfg.Dir, cfg.Genesis)
+		if err != nil {
+			return &DatadirError{Code: ExitCodeGenesisDownload, Err: err}
+		}
+
+		log.Printf("verifying genesis file %s\n", path)
+		if err := verifyDatadirFile(path, cfg.Genesis); err != nil {
+			return &DatadirError{Code: ExitCodeGenesisChecksum, Err: err}
+		}
+	}
+
+	if cfg.Snapshot == nil || len(cfg.Snapshot.Name) == 0 {
+		return nil
+	}
+
+	if _, err := os.Stat(filepath.Join(cfg.Dir, chaindataDir)); err == nil {
+		log.Println("chain database exists, skipping snapshot")
+		return nil
+	}
+
+	path, err := fetchDatadirFile(ctx, cfg.Dir, cfg.Snapshot)
+	if err != nil {
+		return &DatadirError{Code: ExitCodeSnapshotDownload, Err: err}
+	}
+
+	log.Printf("verifying snapshot archive %s\n", path)
+	if err := verifyDatadirFile(path, cfg.Snapshot); err != nil {
+		return &DatadirError{Code: ExitCodeSnapshotChecksum, Err: err}
+	}
+
+	log.Printf("extracting snapshot archive %s\n", path)
+	if err := extractArchive(path, cfg.Dir); err != nil {
+		// Do not leave a partial chain database behind,
+		// Opera would start from it.
+		_ = os.RemoveAll(filepath.Join(cfg.Dir, chaindataDir))
+		return &DatadirError{Code: ExitCodeSnapshotExtract, Err: err}
+	}
+
+	// The archive is only removed when it was downloaded.
+	if !filepath.IsAbs(cfg.Snapshot.Name) {
+		log.Printf("extracted, removing snapshot archive %s\n", path)
+		if err := os.Remove(path); err != nil {
+			log.Printf("unable to remove snapshot archive %s: %s\n", path, err)
+		}
+	}
+
+	return nil
+}
+
+// fetchDatadirFile returns the path of the file,
+// downloading it first if it does not exist.
+func fetchDatadirFile(ctx context.Context, dir string, file *DatadirFile) (string, error) {
+	if filepath.IsAbs(file.Name) {
+		if _, err := os.Stat(file.Name); err != nil {
+			return "", fmt.Errorf("%w: local file %s not found", err, file.Name)
+		}
+		return file.Name, nil
+	}
+
+	path := filepath.Join(dir, filepath.Base(file.Name))
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	url := strings.TrimSuffix(file.BaseURL, "/") + "/" + filepath.Base(file.Name)
+	if err := download(ctx, url, path); err != nil {
+		return "", fmt.Errorf("%w: unable to download %s", err, url)
+	}
+
+	return path, nil
+}
+
+// verifyDatadirFile verifies the checksum of the file at path.
+// A downloaded file with an invalid checksum is removed so that
+// it is downloaded again on the next attempt.
+func verifyDatadirFile(path string, file *DatadirFile) error {
+	err := verifyChecksum(path, file.Checksum)
+	if err != nil && !filepath.IsAbs(file.Name) {
+		if removeErr := os.Remove(path); removeErr != nil {
+			log.Printf("unable to remove %s: %s\n", path, removeErr)
+		}
+	}
+
+	return err
+}
+
+// download fetches url into path. The data is first written to
+// a partial file which is resumed with a range request if it
+// already exists (e.g. after an interrupted download).
+func download(ctx context.Context, url string, path string) error {
+	partial := path + partialSuffix
+	var offset int64
+	if info, err := os.Stat(partial); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		log.Printf("resuming download of %s at %d bytes\n", url, offset)
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		offset = 0
+		flags |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is either complete or larger
+		// than the remote file, in which case it is invalid
+		size, err := remoteSize(ctx, url)
+		if err != nil {
+			return err
+		}
+		if size == offset {
+			return os.Rename(partial, path)
+		}
+
+		log.Printf("partial download of %s has %d bytes but %d are expected, restarting\n", url, offset, size)
+		if err := os.Remove(partial); err != nil {
+			return err
+		}
+		return download(ctx, url, path)
+	default:
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	out, err := os.OpenFile(partial, flags, 0640) // nolint:gomnd
+	if err != nil {
+		return err
+	}
+
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+
+	progress := &progressWriter{
+		name:       filepath.Base(path),
+		written:    offset,
+		total:      total,
+		lastLogged: time.Now(),
+	}
+	if _, err := io.Copy(io.MultiWriter(out, progress), resp.Body); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	log.Printf("downloaded %s (%d bytes)\n", filepath.Base(path), progress.written)
+	return os.Rename(partial, path)
+}
+
+// remoteSize returns the size of the file at url,
+// or -1 if the server does not provide it.
+func remoteSize(ctx context.Context, url string) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return -1, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return -1, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return resp.ContentLength, nil
+}
+
+// progressWriter periodically logs the progress of a download.
+type progressWriter struct {
+	name       string
+	written    int64
+	total      int64
+	lastLogged time.Time
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.written += int64(len(b))
+	if time.Since(p.lastLogged) < downloadProgressInterval {
+		return len(b), nil
+	}
+
+	p.lastLogged = time.Now()
+	if p.total > 0 {
+		log.Printf(
+			"downloading %s: %d/%d bytes (%.1f%%)\n",
+			p.name,
+			p.written,
+			p.total,
+			float64(p.written)*100/float64(p.total), // nolint:gomnd
+		)
+	} else {
+		log.Printf("downloading %s: %d bytes\n", p.name, p.written)
+	}
+
+	return len(b), nil
+}
+
+// checksumHash returns the hash function and
+// the expected digest of a checksum.
+func checksumHash(checksum string) (hash.Hash, string, error) {
+	algorithm := ""
+	digest := strings.ToLower(strings.TrimSpace(checksum))
+	if idx := strings.Index(digest, ":"); idx >= 0 {
+		algorithm = digest[:idx]
+		digest = digest[idx+1:]
+	} else {
+		switch len(digest) {
+		case md5.Size * 2: // nolint:gomnd
+			algorithm = "md5"
+		case sha1.Size * 2: // nolint:gomnd
+			algorithm = "sha1"
+		case sha256.Size * 2: // nolint:gomnd
+			algorithm = "sha256"
+		case sha512.Size * 2: // nolint:gomnd
+			algorithm = "sha512"
+		}
+	}
+
+	switch algorithm {
+	case "md5":
+		return md5.New(), digest, nil // #nosec G401
+	case "sha1":
+		return sha1.New(), digest, nil // #nosec G401
+	case "sha256":
+		return sha256.New(), digest, nil
+	case "sha512":
+		return sha512.New(), digest, nil
+	}
+
+	return nil, "", fmt.Errorf("unsupported checksum %s", checksum)
+}
+
+// verifyChecksum verifies the digest of the file at path.
+// The verification is skipped if checksum is empty.
+func verifyChecksum(path string, checksum string) error {
+	if len(checksum) == 0 {
+		log.Printf("no checksum provided for %s, skipping verification\n", path)
+		return nil
+	}
+
+	h, expected, err := checksumHash(checksum)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path) // #nosec G304
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+
+	actual := hex.EncodeToString(h.Sum(nil))
+	if actual != expected {
+		return fmt.Errorf("invalid checksum of %s: expected %s but got %s", path, expected, actual)
+	}
+
+	return nil
+}
+
+// extractArchive extracts a gzipped tar archive into dir, dropping
+// the top-level directory of each entry (e.g. ".opera/chaindata"
+// is extracted into "<dir>/chaindata").
+func extractArchive(path string, dir string) error { // nolint:gocognit
+	f, err := os.Open(path) // #nosec G304
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		parts := strings.SplitN(strings.TrimPrefix(filepath.ToSlash(header.Name), "./"), "/", 2) // nolint:gomnd
+		if len(parts) < 2 || len(parts[1]) == 0 {                                                // nolint:gomnd
+			continue
+		}
+
+		target := filepath.Join(root, filepath.FromSlash(parts[1]))
+		if !strings.HasPrefix(target, root+string(os.PathSeparator)) {
+			return fmt.Errorf("archive entry %s is outside of %s", header.Name, dir)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0750); err != nil { // nolint:gomnd
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil { // nolint:gomnd
+				return err
+			}
+
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640) // nolint:gomnd
+			if err != nil {
+				return err
+			}
+
+			if _, err := io.Copy(out, reader); err != nil { // #nosec G110
+				out.Close()
+				return err
+			}
+
+			if err := out.Close(); err != nil {
+				return err
+			}
+		default:
+			log.Printf("skipping unsupported archive entry %s\n", header.Name)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func snapshotArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0600,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+
+	return buf.Bytes()
+}
+
+func serveFiles(t *testing.T, files map[string][]byte) (*httptest.Server, *[]string) {
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[strings.TrimPrefix(r.URL.Path, "/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		if r.Method == http.MethodHead {
+			ranges = append(ranges, http.MethodHead)
+		} else {
+			ranges = append(ranges, r.Header.Get("Range"))
+		}
+		http.ServeContent(w, r, r.URL.Path, time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &ranges
+}
+
+func sha256Checksum(content []byte) string {
+	digest := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(digest[:])
+}
+
+func TestPrepareDatadir(t *testing.T) {
+	genesis := []byte("genesis")
+	snapshot := snapshotArchive(t, map[string]string{
+		".opera/chaindata/000001.ldb": "chaindata",
+	})
+	server, ranges := serveFiles(t, map[string][]byte{
+		"testnet.g":    genesis,
+		"snapshot.tgz": snapshot,
+	})
+
+	dir, err := ioutil.TempDir("", "datadir")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Simulate an interrupted download of the genesis file
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "testnet.g"+partialSuffix), genesis[:3], 0600))
+
+	cfg := &DatadirConfig{
+		Dir: dir,
+		Genesis: &DatadirFile{
+			Name:     "testnet.g",
+			Checksum: "sha1:" + "9f6e2a5ac2d0ea6a7f2d14dbbd8a0f4fdac4ea2f",
+			BaseURL:  server.URL,
+		},
+		Snapshot: &DatadirFile{
+			Name:     "snapshot.tgz",
+			Checksum: sha256Checksum(snapshot),
+			BaseURL:  server.URL + "/",
+		},
+	}
+
+	err = PrepareDatadir(context.Background(), cfg)
+	var datadirErr *DatadirError
+	assert.True(t, errors.As(err, &datadirErr))
+	assert.Equal(t, ExitCodeGenesisChecksum, datadirErr.ExitCode())
+	assert.Equal(t, []string{"bytes=3-"}, *ranges)
+
+	// The invalid genesis file is downloaded again
+	cfg.Genesis.Checksum = sha256Checksum(genesis)
+	assert.NoError(t, PrepareDatadir(context.Background(), cfg))
+	assert.Equal(t, []string{"bytes=3-", "", ""}, *ranges)
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "testnet.g"))
+	assert.NoError(t, err)
+	assert.Equal(t, genesis, content)
+
+	content, err = ioutil.ReadFile(filepath.Join(dir, "chaindata", "000001.ldb"))
+	assert.NoError(t, err)
+	assert.Equal(t, "chaindata", string(content))
+
+	_, err = os.Stat(filepath.Join(dir, "snapshot.tgz"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Nothing is downloaded once the datadir is prepared
+	*ranges = nil
+	assert.NoError(t, PrepareDatadir(context.Background(), cfg))
+	assert.Empty(t, *ranges)
+}
+
+func TestDownload_CompletePartial(t *testing.T) {
+	genesis := []byte("genesis")
+	server, ranges := serveFiles(t, map[string][]byte{"testnet.g": genesis})
+
+	tests := map[string]struct {
+		partial []byte
+		ranges  []string
+	}{
+		"complete": {
+			partial: genesis,
+			ranges:  []string{"bytes=7-", http.MethodHead},
+		},
+		"larger than remote file": {
+			partial: []byte("genesis and more"),
+			ranges:  []string{"bytes=16-", http.MethodHead, ""},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "download")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "testnet.g")
+			assert.NoError(t, ioutil.WriteFile(path+partialSuffix, test.partial, 0600))
+
+			*ranges = nil
+			assert.NoError(t, download(context.Background(), server.URL+"/testnet.g", path))
+			assert.Equal(t, test.ranges, *ranges)
+
+			content, err := ioutil.ReadFile(path)
+			assert.NoError(t, err)
+			assert.Equal(t, genesis, content)
+
+			_, err = os.Stat(path + partialSuffix)
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+}
+
+func TestPrepareDatadir_Errors(t *testing.T) {
+	snapshot := snapshotArchive(t, map[string]string{
+		".opera/../../escape": "escape",
+	})
+	server, _ := serveFiles(t, map[string][]byte{
+		"snapshot.tgz": snapshot,
+	})
+
+	tests := map[string]struct {
+		cfg  func(dir string) *DatadirConfig
+		code int
+	}{
+		"missing genesis": {
+			cfg: func(dir string) *DatadirConfig {
+				return &DatadirConfig{
+					Dir:     dir,
+					Genesis: &DatadirFile{Name: "missing.g", BaseURL: server.URL},
+				}
+			},
+			code: ExitCodeGenesisDownload,
+		},
+		"missing local genesis": {
+			cfg: func(dir string) *DatadirConfig {
+				return &DatadirConfig{
+					Dir:     dir,
+					Genesis: &DatadirFile{Name: filepath.Join(dir, "missing.g")},
+				}
+			},
+			code: ExitCodeGenesisDownload,
+		},
+		"missing snapshot": {
+			cfg: func(dir string) *DatadirConfig {
+				return &DatadirConfig{
+					Dir:      dir,
+					Snapshot: &DatadirFile{Name: "missing.tgz", BaseURL: server.URL},
+				}
+			},
+			code: ExitCodeSnapshotDownload,
+		},
+		"invalid snapshot checksum": {
+			cfg: func(dir string) *DatadirConfig {
+				return &DatadirConfig{
+					Dir: dir,
+					Snapshot: &DatadirFile{
+						Name:     "snapshot.tgz",
+						Checksum: "md5:3981d701e47ec9fd8a71dbbdc01f1cde",
+						BaseURL:  server.URL,
+					},
+				}
+			},
+			code: ExitCodeSnapshotChecksum,
+		},
+		"unsupported checksum": {
+			cfg: func(dir string) *DatadirConfig {
+				return &DatadirConfig{
+					Dir: dir,
+					Snapshot: &DatadirFile{
+						Name:     "snapshot.tgz",
+						Checksum: "crc32:1234",
+						BaseURL:  server.URL,
+					},
+				}
+			},
+			code: ExitCodeSnapshotChecksum,
+		},
+		"snapshot outside of datadir": {
+			cfg: func(dir string) *DatadirConfig {
+				return &DatadirConfig{
+					Dir: dir,
+					Snapshot: &DatadirFile{
+						Name:     "snapshot.tgz",
+						Checksum: sha256Checksum(snapshot),
+						BaseURL:  server.URL,
+					},
+				}
+			},
+			code: ExitCodeSnapshotExtract,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "datadir")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			err = PrepareDatadir(context.Background(), test.cfg(dir))
+			var datadirErr *DatadirError
+			assert.True(t, errors.As(err, &datadirErr))
+			assert.Equal(t, test.code, datadirErr.ExitCode())
+		})
+	}
+}
@@ -18,8 +18,12 @@ import "errors"
 
 // Client errors
 var (
-	ErrBlockOrphaned         = errors.New("block orphaned")
-	ErrCallParametersInvalid = errors.New("call parameters invalid")
-	ErrCallOutputMarshal     = errors.New("call output marshal")
-	ErrCallMethodInvalid     = errors.New("call method invalid")
+	ErrBlockOrphaned          = errors.New("block orphaned")
+	ErrFinalizedBlockMismatch = errors.New("finalized block mismatch")
+	ErrCallParametersInvalid  = errors.New("call parameters invalid")
+	ErrCallOutputMarshal      = errors.New("call output marshal")
+	ErrCallMethodInvalid      = errors.New("call method invalid")
+	ErrTransactionNotTracked  = errors.New("transaction not tracked")
+	ErrTransactionNotPending  = errors.New("transaction not pending")
+	ErrOperaNotReady          = errors.New("opera not ready")
 )
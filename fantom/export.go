@@ -0,0 +1,395 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"time"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// ExportFormatBlocks exports each block as
+	// a line of JSON.
+	ExportFormatBlocks = "blocks"
+
+	// ExportFormatOperations exports each operation as a
+	// flat line of JSON, suited for columnar formats.
+	ExportFormatOperations = "operations"
+
+	// DefaultExportConcurrency is the default number
+	// of blocks fetched in parallel.
+	DefaultExportConcurrency = 8
+
+	// DefaultExportPollInterval is the default interval at
+	// which the head is polled when following the tip.
+	DefaultExportPollInterval = 5 * time.Second
+)
+
+// BlockFetcher fetches blocks and the current head.
+// *Client implements BlockFetcher.
+type BlockFetcher interface {
+	Status(context.Context) (
+		*RosettaTypes.BlockIdentifier,
+		int64,
+		*RosettaTypes.SyncStatus,
+		[]*RosettaTypes.Peer,
+		error,
+	)
+
+	Block(
+		context.Context,
+		*RosettaTypes.PartialBlockIdentifier,
+	) (*RosettaTypes.Block, error)
+}
+
+// ExportConfig configures ExportBlocks.
+type ExportConfig struct {
+	// Start is the index of the first block to export.
+	Start int64
+
+	// End is the index of the last block to export. If End
+	// is negative, blocks are exported up to the current head.
+	End int64
+
+	// Follow keeps exporting new blocks once the
+	// head is reached. End is ignored.
+	Follow bool
+
+	// Concurrency is the number of blocks fetched in parallel.
+	Concurrency int
+
+	// Format is ExportFormatBlocks or ExportFormatOperations.
+	Format string
+
+	// Checkpoint is the file storing the index of the last
+	// exported block. If it exists, the export resumes after
+	// this block.
+	Checkpoint string
+
+	// PollInterval is the interval at which the head is
+	// polled when following the tip.
+	PollInterval time.Duration
+}
+
+// ExportedOperation is an operation flattened
+// with its block and transaction.
+type ExportedOperation struct {
+	BlockIndex        int64  `json:"block_index"`
+	BlockHash         string `json:"block_hash"`
+	BlockTimestamp    int64  `json:"block_timestamp"`
+	TransactionHash   string `json:"transaction_hash"`
+	OperationIndex    int64  `json:"operation_index"`
+	Type              string `json:"type"`
+	Status            string `json:"status"`
+	Address           string `json:"address"`
+	SubAccountAddress string `json:"sub_account_address"`
+	Value             string `json:"value"`
+	Symbol            string `json:"symbol"`
+	Decimals          int32  `json:"decimals"`
+}
+
+type exportCheckpoint struct {
+	Index int64 `json:"index"`
+}
+
+// ExportBlocks writes the blocks of the configured range to output
+// as newline-delimited JSON. Blocks are fetched in parallel but
+// written in order, and each batch of blocks is written to output
+// with a single write of complete lines before the checkpoint is
+// updated. An interrupted export may therefore repeat the blocks of
+// the last batch but never skips any block. If the export is killed
+// while writing, the output may end with a partial line, which
+// TruncateExportOutput removes before resuming.
+func ExportBlocks( // nolint:gocognit
+	ctx context.Context,
+	fetcher BlockFetcher,
+	output io.Writer,
+	cfg *ExportConfig,
+) error {
+	if cfg.Format != ExportFormatBlocks && cfg.Format != ExportFormatOperations {
+		return fmt.Errorf("export format %s is not supported", cfg.Format)
+	}
+
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultExportConcurrency
+	}
+
+	pollInterval := cfg.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultExportPollInterval
+	}
+
+	next := cfg.Start
+	if len(cfg.Checkpoint) > 0 {
+		last, err := loadExportCheckpoint(cfg.Checkpoint)
+		if err != nil {
+			return fmt.Errorf("%w: unable to load checkpoint", err)
+		}
+
+		if last != nil && *last >= next {
+			next = *last + 1
+			log.Printf("resuming export at block %d\n", next)
+		}
+	}
+
+	end := cfg.End
+	if end < 0 || cfg.Follow {
+		head, err := fetchHead(ctx, fetcher)
+		if err != nil {
+			return err
+		}
+		end = head
+	}
+
+	var batch bytes.Buffer
+	for {
+		if next > end {
+			if !cfg.Follow {
+				return nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(pollInterval):
+			}
+
+			head, err := fetchHead(ctx, fetcher)
+			if err != nil {
+				return err
+			}
+			end = head
+			continue
+		}
+
+		size := int64(concurrency)
+		if remaining := end - next + 1; remaining < size {
+			size = remaining
+		}
+
+		blocks, err := fetchBlocks(ctx, fetcher, next, size)
+		if err != nil {
+			return err
+		}
+
+		batch.Reset()
+		for _, block := range blocks {
+			if err := writeExportedBlock(&batch, block, cfg.Format); err != nil {
+				return fmt.Errorf("%w: unable to write block %d", err, block.BlockIdentifier.Index)
+			}
+		}
+
+		if _, err := output.Write(batch.Bytes()); err != nil {
+			return fmt.Errorf("%w: unable to write output", err)
+		}
+
+		next += size
+		if len(cfg.Checkpoint) > 0 {
+			if err := storeExportCheckpoint(cfg.Checkpoint, next-1); err != nil {
+				return fmt.Errorf("%w: unable to store checkpoint", err)
+			}
+		}
+	}
+}
+
+// fetchBlocks fetches count blocks starting at start in parallel.
+func fetchBlocks(
+	ctx context.Context,
+	fetcher BlockFetcher,
+	start int64,
+	count int64,
+) ([]*RosettaTypes.Block, error) {
+	blocks := make([]*RosettaTypes.Block, count)
+	g, gctx := errgroup.WithContext(ctx)
+	for i := range blocks {
+		i := i
+		index := start + int64(i)
+		g.Go(func() error {
+			block, err := fetcher.Block(gctx, &RosettaTypes.PartialBlockIdentifier{
+				Index: &index,
+			})
+			if err != nil {
+				return fmt.Errorf("%w: unable to fetch block %d", err, index)
+			}
+
+			blocks[i] = block
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+func fetchHead(ctx context.Context, fetcher BlockFetcher) (int64, error) {
+	head, _, _, _, err := fetcher.Status(ctx)
+	if err != nil {
+		return -1, fmt.Errorf("%w: unable to fetch head", err)
+	}
+
+	return head.Index, nil
+}
+
+func writeExportedBlock(writer io.Writer, block *RosettaTypes.Block, format string) error {
+	encoder := json.NewEncoder(writer)
+	if format == ExportFormatBlocks {
+		return encoder.Encode(block)
+	}
+
+	for _, operation := range ExportedOperations(block) {
+		if err := encoder.Encode(operation); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExportedOperations flattens the operations of a block.
+func ExportedOperations(block *RosettaTypes.Block) []*ExportedOperation {
+	operations := []*ExportedOperation{}
+	for _, tx := range block.Transactions {
+		for _, op := range tx.Operations {
+			exported := &ExportedOperation{
+				BlockIndex:      block.BlockIdentifier.Index,
+				BlockHash:       block.BlockIdentifier.Hash,
+				BlockTimestamp:  block.Timestamp,
+				TransactionHash: tx.TransactionIdentifier.Hash,
+				OperationIndex:  op.OperationIdentifier.Index,
+				Type:            op.Type,
+			}
+
+			if op.Status != nil {
+				exported.Status = *op.Status
+			}
+
+			if op.Account != nil {
+				exported.Address = op.Account.Address
+				if op.Account.SubAccount != nil {
+					exported.SubAccountAddress = op.Account.SubAccount.Address
+				}
+			}
+
+			if op.Amount != nil {
+				exported.Value = op.Amount.Value
+				if op.Amount.Currency != nil {
+					exported.Symbol = op.Amount.Currency.Symbol
+					exported.Decimals = op.Amount.Currency.Decimals
+				}
+			}
+
+			operations = append(operations, exported)
+		}
+	}
+
+	return operations
+}
+
+// TruncateExportOutput truncates the output file of an interrupted
+// export after its last complete line, so that the export resumes
+// on a new line. The file is left as is if it does not exist.
+func TruncateExportOutput(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0) // #nosec G304
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	// Look for the last newline from the end of the file
+	size := info.Size()
+	chunk := make([]byte, 4096) // nolint:gomnd
+	for end := size; end > 0; {
+		start := end - int64(len(chunk))
+		if start < 0 {
+			start = 0
+		}
+
+		n, err := f.ReadAt(chunk[:end-start], start)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		if idx := bytes.LastIndexByte(chunk[:n], '\n'); idx >= 0 {
+			return truncateExportOutput(f, size, start+int64(idx)+1)
+		}
+		end = start
+	}
+
+	return truncateExportOutput(f, size, 0)
+}
+
+func truncateExportOutput(f *os.File, size int64, length int64) error {
+	if length == size {
+		return nil
+	}
+
+	log.Printf("truncating partial line of %d bytes at the end of %s\n", size-length, f.Name())
+	return f.Truncate(length)
+}
+
+func loadExportCheckpoint(path string) (*int64, error) {
+	contents, err := ioutil.ReadFile(path) // #nosec G304
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoint exportCheckpoint
+	if err := json.Unmarshal(contents, &checkpoint); err != nil {
+		return nil, err
+	}
+
+	return &checkpoint.Index, nil
+}
+
+// storeExportCheckpoint atomically replaces the checkpoint.
+func storeExportCheckpoint(path string, index int64) error {
+	contents, err := json.Marshal(&exportCheckpoint{Index: index})
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, contents, 0600); err != nil { // nolint:gomnd
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,260 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type testBlockFetcher struct {
+	head   int64
+	failAt int64
+}
+
+func (f *testBlockFetcher) Status(context.Context) (
+	*RosettaTypes.BlockIdentifier,
+	int64,
+	*RosettaTypes.SyncStatus,
+	[]*RosettaTypes.Peer,
+	error,
+) {
+	head := atomic.LoadInt64(&f.head)
+	return &RosettaTypes.BlockIdentifier{Index: head, Hash: fmt.Sprintf("0x%x", head)}, 0, nil, nil, nil
+}
+
+func (f *testBlockFetcher) Block(
+	ctx context.Context,
+	blockIdentifier *RosettaTypes.PartialBlockIdentifier,
+) (*RosettaTypes.Block, error) {
+	index := *blockIdentifier.Index
+	if f.failAt > 0 && index == f.failAt {
+		return nil, errors.New("unavailable")
+	}
+
+	status := SuccessStatus
+	return &RosettaTypes.Block{
+		BlockIdentifier: &RosettaTypes.BlockIdentifier{Index: index, Hash: fmt.Sprintf("0x%x", index)},
+		Timestamp:       1000 + index,
+		Transactions: []*RosettaTypes.Transaction{
+			{
+				TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: fmt.Sprintf("0xt%x", index)},
+				Operations: []*RosettaTypes.Operation{
+					{
+						OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 0},
+						Type:                FeeOpType,
+						Status:              &status,
+						Account:             &RosettaTypes.AccountIdentifier{Address: "0xabc"},
+						Amount:              &RosettaTypes.Amount{Value: "-1", Currency: Currency},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func exportedIndexes(t *testing.T, output *bytes.Buffer) []int64 {
+	indexes := []int64{}
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		var line struct {
+			BlockIdentifier *RosettaTypes.BlockIdentifier `json:"block_identifier"`
+			BlockIndex      int64                         `json:"block_index"`
+		}
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		if line.BlockIdentifier != nil {
+			indexes = append(indexes, line.BlockIdentifier.Index)
+		} else {
+			indexes = append(indexes, line.BlockIndex)
+		}
+	}
+
+	return indexes
+}
+
+type recordingWriter struct {
+	writes [][]byte
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, append([]byte{}, p...))
+	return len(p), nil
+}
+
+func TestExportBlocks(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("range", func(t *testing.T) {
+		var output bytes.Buffer
+		err := ExportBlocks(ctx, &testBlockFetcher{head: 100}, &output, &ExportConfig{
+			Start:       3,
+			End:         12,
+			Concurrency: 4,
+			Format:      ExportFormatBlocks,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, exportedIndexes(t, &output))
+	})
+
+	t.Run("operations up to head", func(t *testing.T) {
+		var output bytes.Buffer
+		err := ExportBlocks(ctx, &testBlockFetcher{head: 2}, &output, &ExportConfig{
+			End:    -1,
+			Format: ExportFormatOperations,
+		})
+		assert.NoError(t, err)
+
+		var operation ExportedOperation
+		assert.NoError(t, json.Unmarshal(bytes.Split(output.Bytes(), []byte("\n"))[1], &operation))
+		assert.Equal(t, ExportedOperation{
+			BlockIndex:      1,
+			BlockHash:       "0x1",
+			BlockTimestamp:  1001,
+			TransactionHash: "0xt1",
+			Type:            FeeOpType,
+			Status:          SuccessStatus,
+			Address:         "0xabc",
+			Value:           "-1",
+			Symbol:          Symbol,
+			Decimals:        Decimals,
+		}, operation)
+		assert.Equal(t, []int64{0, 1, 2}, exportedIndexes(t, &output))
+	})
+
+	t.Run("resume from checkpoint", func(t *testing.T) {
+		checkpoint := filepath.Join(t.TempDir(), "checkpoint")
+		cfg := &ExportConfig{
+			Start:       0,
+			End:         9,
+			Concurrency: 2,
+			Format:      ExportFormatBlocks,
+			Checkpoint:  checkpoint,
+		}
+
+		var output bytes.Buffer
+		err := ExportBlocks(ctx, &testBlockFetcher{head: 100, failAt: 6}, &output, cfg)
+		assert.Error(t, err)
+		assert.Equal(t, []int64{0, 1, 2, 3, 4, 5}, exportedIndexes(t, &output))
+
+		last, err := loadExportCheckpoint(checkpoint)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), *last)
+
+		output.Reset()
+		assert.NoError(t, ExportBlocks(ctx, &testBlockFetcher{head: 100}, &output, cfg))
+		assert.Equal(t, []int64{6, 7, 8, 9}, exportedIndexes(t, &output))
+	})
+
+	t.Run("follow", func(t *testing.T) {
+		fetcher := &testBlockFetcher{head: 1}
+		followCtx, cancel := context.WithCancel(ctx)
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			atomic.StoreInt64(&fetcher.head, 4)
+			time.Sleep(100 * time.Millisecond)
+			cancel()
+		}()
+
+		var output bytes.Buffer
+		err := ExportBlocks(followCtx, fetcher, &output, &ExportConfig{
+			Follow:       true,
+			Format:       ExportFormatBlocks,
+			PollInterval: 10 * time.Millisecond,
+		})
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Equal(t, []int64{0, 1, 2, 3, 4}, exportedIndexes(t, &output))
+	})
+
+	t.Run("complete lines per write", func(t *testing.T) {
+		output := &recordingWriter{}
+		err := ExportBlocks(ctx, &testBlockFetcher{head: 100}, output, &ExportConfig{
+			Start:       0,
+			End:         9,
+			Concurrency: 4,
+			Format:      ExportFormatOperations,
+		})
+		assert.NoError(t, err)
+
+		// One write per batch, each ending on a complete line
+		assert.Len(t, output.writes, 3)
+		for _, write := range output.writes {
+			assert.True(t, bytes.HasSuffix(write, []byte("\n")))
+		}
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		var output bytes.Buffer
+		err := ExportBlocks(ctx, &testBlockFetcher{}, &output, &ExportConfig{Format: "parquet"})
+		assert.Error(t, err)
+	})
+}
+
+func TestTruncateExportOutput(t *testing.T) {
+	tests := map[string]struct {
+		contents string
+		expected string
+	}{
+		"complete lines": {
+			contents: "{\"index\":1}\n{\"index\":2}\n",
+			expected: "{\"index\":1}\n{\"index\":2}\n",
+		},
+		"partial line": {
+			contents: "{\"index\":1}\n{\"index\":2}\n{\"ind",
+			expected: "{\"index\":1}\n{\"index\":2}\n",
+		},
+		"partial line longer than a chunk": {
+			contents: "{\"index\":1}\n" + strings.Repeat("x", 10000),
+			expected: "{\"index\":1}\n",
+		},
+		"only a partial line": {
+			contents: "{\"ind",
+			expected: "",
+		},
+		"empty": {
+			contents: "",
+			expected: "",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "blocks.ndjson")
+			assert.NoError(t, ioutil.WriteFile(path, []byte(test.contents), 0600))
+
+			assert.NoError(t, TruncateExportOutput(path))
+
+			contents, err := ioutil.ReadFile(path)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, string(contents))
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		assert.NoError(t, TruncateExportOutput(filepath.Join(t.TempDir(), "missing.ndjson")))
+	})
+}
@@ -0,0 +1,88 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Lachesis, the aBFT consensus of Opera, only produces a block once
+// its Atropos event is decided, and decided events are never reverted.
+// Every block served by Opera is therefore final: blocks are never
+// orphaned and the head of Opera is its finalized head.
+
+const (
+	// Finality is the finality of Opera blocks
+	// advertised in /network/options.
+	Finality = "instant"
+)
+
+// FinalityMetadata documents the finality of Opera blocks in the
+// version metadata of /network/options.
+var FinalityMetadata = map[string]interface{}{
+	"finality": Finality,
+	"finality_guarantee": "Lachesis aBFT consensus finalizes blocks as they are produced: " +
+		"blocks at or below the current head are never reverted or orphaned",
+}
+
+// blockEpoch returns the epoch of the block with hash. The
+// hash of an Opera block is the ID of its Atropos event, whose
+// first 4 bytes are the big-endian epoch of the event.
+func blockEpoch(hash common.Hash) uint64 {
+	return uint64(binary.BigEndian.Uint32(hash[:4]))
+}
+
+// blockHashMismatch returns the error of a receipt of the transaction
+// txHash included in the block receiptBlockHash instead of the block
+// blockNumber with hash blockHash.
+//
+// Blocks at or below the head are final, so the mismatch is an
+// ErrFinalizedBlockMismatch that retrying cannot resolve. A block
+// above the head, e.g. when requests are load balanced across nodes
+// at different heights, is reported as a retriable ErrBlockOrphaned.
+func (ec *Client) blockHashMismatch(
+	ctx context.Context,
+	blockNumber *big.Int,
+	blockHash common.Hash,
+	txHash common.Hash,
+	receiptBlockHash common.Hash,
+) error {
+	head, err := ec.blockHeaderByNumber(ctx, nil)
+	if err != nil || blockNumber.Cmp(head.Number) > 0 {
+		return fmt.Errorf(
+			"%w: expected block hash %s for transaction but got %s",
+			ErrBlockOrphaned,
+			blockHash.Hex(),
+			receiptBlockHash.Hex(),
+		)
+	}
+
+	return fmt.Errorf(
+		"%w: receipt of transaction %s is in block %s but the transaction is in block %d (%s), "+
+			"which is final at finalized head %d (%s)",
+		ErrFinalizedBlockMismatch,
+		txHash.Hex(),
+		receiptBlockHash.Hex(),
+		blockNumber.Int64(),
+		blockHash.Hex(),
+		head.Number.Int64(),
+		head.Hash.Hex(),
+	)
+}
@@ -0,0 +1,95 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	mocks "github.com/Fantom-foundation/rosetta-fantom/mocks/opera"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBlockEpoch(t *testing.T) {
+	hash := common.HexToHash("0x0000a0b1000000ad5bb4d2a2e0b4dd14bd7bab3d9a8eca6e45ed1e05b7d48c0b")
+	assert.Equal(t, uint64(0xa0b1), blockEpoch(hash))
+}
+
+func TestBlockHashMismatch(t *testing.T) {
+	blockHash := common.HexToHash("0x01")
+	txHash := common.HexToHash("0x02")
+	receiptBlockHash := common.HexToHash("0x03")
+
+	// The head of basic_header.json is 0x880eb0
+	tests := map[string]struct {
+		blockNumber *big.Int
+		headErr     error
+		expected    error
+	}{
+		"finalized block": {
+			blockNumber: big.NewInt(0x880eb0),
+			expected:    ErrFinalizedBlockMismatch,
+		},
+		"block above head": {
+			blockNumber: big.NewInt(0x880eb1),
+			expected:    ErrBlockOrphaned,
+		},
+		"head unavailable": {
+			blockNumber: big.NewInt(1),
+			headErr:     errors.New("unavailable"),
+			expected:    ErrBlockOrphaned,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockJSONRPC := &mocks.JSONRPC{}
+			c := &Client{c: mockJSONRPC}
+
+			ctx := context.Background()
+			mockJSONRPC.On(
+				"CallContext",
+				ctx,
+				mock.Anything,
+				"eth_getBlockByNumber",
+				"latest",
+				false,
+			).Return(
+				test.headErr,
+			).Run(
+				func(args mock.Arguments) {
+					header := args.Get(1).(**blockHeader)
+					file, err := ioutil.ReadFile("testdata/basic_header.json")
+					assert.NoError(t, err)
+
+					*header = new(blockHeader)
+
+					assert.NoError(t, (*header).UnmarshalJSON(file))
+				},
+			).Once()
+
+			err := c.blockHashMismatch(ctx, test.blockNumber, blockHash, txHash, receiptBlockHash)
+			assert.ErrorIs(t, err, test.expected)
+			assert.Contains(t, err.Error(), receiptBlockHash.Hex())
+			mockJSONRPC.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,308 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// ERC721Standard is the symbol of the currencies
+	// of ERC-721 tokens.
+	ERC721Standard = "ERC721"
+
+	// ERC1155Standard is the symbol of the currencies
+	// of ERC-1155 tokens.
+	ERC1155Standard = "ERC1155"
+)
+
+var (
+	// ErrInvalidNFTCurrency is returned when a currency
+	// does not identify an ERC-721 or ERC-1155 token.
+	ErrInvalidNFTCurrency = errors.New("invalid NFT currency")
+
+	// ErrUnknownSafeTransferFrom is returned when call data
+	// is not a safeTransferFrom call of an NFT contract.
+	ErrUnknownSafeTransferFrom = errors.New("unknown safeTransferFrom call")
+)
+
+// NFTCurrency returns the *RosettaTypes.Currency of the token
+// tokenID of the ERC-721 or ERC-1155 contract. NFTs are not
+// divisible, so amounts are whole tokens.
+func NFTCurrency(standard string, contract common.Address, tokenID *big.Int) *RosettaTypes.Currency {
+	return &RosettaTypes.Currency{
+		Symbol:   standard,
+		Decimals: 0,
+		Metadata: map[string]interface{}{
+			"contract_address": contract.Hex(),
+			"token_id":         tokenID.String(),
+		},
+	}
+}
+
+// ParseNFTCurrency returns the contract and token ID of
+// the NFTCurrency of the standard.
+func ParseNFTCurrency(standard string, currency *RosettaTypes.Currency) (common.Address, *big.Int, error) {
+	if currency == nil || currency.Symbol != standard || currency.Decimals != 0 {
+		return common.Address{}, nil, fmt.Errorf("%w: expected %s currency", ErrInvalidNFTCurrency, standard)
+	}
+
+	contract, ok := currency.Metadata["contract_address"].(string)
+	if !ok || !common.IsHexAddress(contract) {
+		return common.Address{}, nil, fmt.Errorf("%w: contract_address is invalid", ErrInvalidNFTCurrency)
+	}
+
+	rawTokenID, ok := currency.Metadata["token_id"].(string)
+	if !ok {
+		return common.Address{}, nil, fmt.Errorf("%w: token_id is missing", ErrInvalidNFTCurrency)
+	}
+
+	tokenID, ok := new(big.Int).SetString(rawTokenID, 10) // nolint:gomnd
+	if !ok || tokenID.Sign() < 0 {
+		return common.Address{}, nil, fmt.Errorf("%w: token_id %s is invalid", ErrInvalidNFTCurrency, rawTokenID)
+	}
+
+	return common.HexToAddress(contract), tokenID, nil
+}
+
+// NFTStandard returns the token standard transferred by
+// operations of opType, or false if opType is not an NFT
+// transfer operation type.
+func NFTStandard(opType string) (string, bool) {
+	switch opType {
+	case ERC721TransferOpType:
+		return ERC721Standard, true
+	case ERC1155TransferOpType:
+		return ERC1155Standard, true
+	default:
+		return "", false
+	}
+}
+
+// nftOpType returns the operation type of
+// transfers of tokens of the standard.
+func nftOpType(standard string) string {
+	if standard == ERC721Standard {
+		return ERC721TransferOpType
+	}
+
+	return ERC1155TransferOpType
+}
+
+// SafeTransferFromData returns the call data of the safeTransferFrom
+// call transferring amount tokenID tokens of the standard from from
+// to to. ERC-721 tokens are unique, so amount must be 1.
+func SafeTransferFromData(
+	standard string,
+	from common.Address,
+	to common.Address,
+	tokenID *big.Int,
+	amount *big.Int,
+) ([]byte, error) {
+	switch standard {
+	case ERC721Standard:
+		if amount.Cmp(big.NewInt(1)) != 0 {
+			return nil, fmt.Errorf("ERC-721 transfers must move 1 token, not %s", amount.String())
+		}
+
+		return erc721ABI.Pack("safeTransferFrom", from, to, tokenID)
+	case ERC1155Standard:
+		return erc1155ABI.Pack("safeTransferFrom", from, to, tokenID, amount, []byte{})
+	default:
+		return nil, fmt.Errorf("%s is not an NFT standard", standard)
+	}
+}
+
+// NFTTransfer is a transfer of NFTs decoded
+// from a safeTransferFrom call.
+type NFTTransfer struct {
+	Standard string
+	From     common.Address
+	To       common.Address
+	TokenID  *big.Int
+	Amount   *big.Int
+}
+
+// ParseSafeTransferFrom decodes the safeTransferFrom call
+// data of an ERC-721 or ERC-1155 contract.
+func ParseSafeTransferFrom(data []byte) (*NFTTransfer, error) {
+	if len(data) < 4 { // nolint:gomnd
+		return nil, ErrUnknownSafeTransferFrom
+	}
+
+	erc721Method := erc721ABI.Methods["safeTransferFrom"]
+	erc1155Method := erc1155ABI.Methods["safeTransferFrom"]
+	switch {
+	case bytes.Equal(data[:4], erc721Method.ID):
+		values, err := erc721Method.Inputs.Unpack(data[4:])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownSafeTransferFrom, err.Error())
+		}
+
+		return &NFTTransfer{
+			Standard: ERC721Standard,
+			From:     values[0].(common.Address),
+			To:       values[1].(common.Address),
+			TokenID:  values[2].(*big.Int),
+			Amount:   big.NewInt(1),
+		}, nil
+	case bytes.Equal(data[:4], erc1155Method.ID):
+		values, err := erc1155Method.Inputs.Unpack(data[4:])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownSafeTransferFrom, err.Error())
+		}
+
+		return &NFTTransfer{
+			Standard: ERC1155Standard,
+			From:     values[0].(common.Address),
+			To:       values[1].(common.Address),
+			TokenID:  values[2].(*big.Int),
+			Amount:   values[3].(*big.Int),
+		}, nil
+	default:
+		return nil, ErrUnknownSafeTransferFrom
+	}
+}
+
+// nftOps returns the ERC721_TRANSFER and ERC1155_TRANSFER
+// operations of the Transfer, TransferSingle and TransferBatch
+// logs of NFT contracts, indexed from startIndex. Each transfer
+// debits the sender and credits the recipient, skipping the
+// zero address side of mints and burns.
+func nftOps(logs []*types.Log, startIndex int) []*RosettaTypes.Operation {
+	var ops []*RosettaTypes.Operation
+	for _, log := range logs {
+		for _, transfer := range decodeNFTTransfers(log) {
+			currency := NFTCurrency(transfer.Standard, log.Address, transfer.TokenID)
+			opType := nftOpType(transfer.Standard)
+
+			var debitIndex *int64
+			for _, side := range []struct {
+				account common.Address
+				amount  *big.Int
+			}{
+				{transfer.From, new(big.Int).Neg(transfer.Amount)},
+				{transfer.To, transfer.Amount},
+			} {
+				if side.account == (common.Address{}) {
+					continue
+				}
+
+				op := &RosettaTypes.Operation{
+					OperationIdentifier: &RosettaTypes.OperationIdentifier{
+						Index: int64(startIndex + len(ops)),
+					},
+					Type:   opType,
+					Status: RosettaTypes.String(SuccessStatus),
+					Account: &RosettaTypes.AccountIdentifier{
+						Address: MustChecksum(side.account.Hex()),
+					},
+					Amount: &RosettaTypes.Amount{
+						Value:    side.amount.String(),
+						Currency: currency,
+					},
+					Metadata: map[string]interface{}{
+						"log_index": log.Index,
+					},
+				}
+
+				if debitIndex != nil {
+					op.RelatedOperations = []*RosettaTypes.OperationIdentifier{
+						{
+							Index: *debitIndex,
+						},
+					}
+				} else {
+					debitIndex = &op.OperationIdentifier.Index
+				}
+
+				ops = append(ops, op)
+			}
+		}
+	}
+
+	return ops
+}
+
+// decodeNFTTransfers returns the transfers of an ERC-721
+// Transfer or ERC-1155 TransferSingle or TransferBatch log,
+// or nil if log is none of these.
+func decodeNFTTransfers(log *types.Log) []*NFTTransfer {
+	// All NFT transfer events have 3 indexed arguments
+	if len(log.Topics) != 4 { // nolint:gomnd
+		return nil
+	}
+
+	switch log.Topics[0] {
+	case erc721ABI.Events["Transfer"].ID:
+		return []*NFTTransfer{
+			{
+				Standard: ERC721Standard,
+				From:     common.BytesToAddress(log.Topics[1].Bytes()),
+				To:       common.BytesToAddress(log.Topics[2].Bytes()),
+				TokenID:  log.Topics[3].Big(),
+				Amount:   big.NewInt(1),
+			},
+		}
+	case erc1155ABI.Events["TransferSingle"].ID:
+		values, err := erc1155ABI.Events["TransferSingle"].Inputs.NonIndexed().Unpack(log.Data)
+		if err != nil {
+			return nil
+		}
+
+		return []*NFTTransfer{
+			{
+				Standard: ERC1155Standard,
+				From:     common.BytesToAddress(log.Topics[2].Bytes()),
+				To:       common.BytesToAddress(log.Topics[3].Bytes()),
+				TokenID:  values[0].(*big.Int),
+				Amount:   values[1].(*big.Int),
+			},
+		}
+	case erc1155ABI.Events["TransferBatch"].ID:
+		values, err := erc1155ABI.Events["TransferBatch"].Inputs.NonIndexed().Unpack(log.Data)
+		if err != nil {
+			return nil
+		}
+
+		ids := values[0].([]*big.Int)
+		amounts := values[1].([]*big.Int)
+		if len(ids) != len(amounts) {
+			return nil
+		}
+
+		transfers := make([]*NFTTransfer, len(ids))
+		for i := range ids {
+			transfers[i] = &NFTTransfer{
+				Standard: ERC1155Standard,
+				From:     common.BytesToAddress(log.Topics[2].Bytes()),
+				To:       common.BytesToAddress(log.Topics[3].Bytes()),
+				TokenID:  ids[i],
+				Amount:   amounts[i],
+			}
+		}
+
+		return transfers
+	default:
+		return nil
+	}
+}
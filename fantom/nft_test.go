@@ -0,0 +1,179 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"math/big"
+	"testing"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNFTOps(t *testing.T) {
+	from := MustChecksum(testFromAddress.Hex())
+	to := MustChecksum(testToAddress.Hex())
+	erc721Currency := NFTCurrency(ERC721Standard, testTokenAddress, big.NewInt(42))
+
+	batchData, err := erc1155ABI.Events["TransferBatch"].Inputs.NonIndexed().Pack(
+		[]*big.Int{big.NewInt(1), big.NewInt(2)},
+		[]*big.Int{big.NewInt(10), big.NewInt(20)},
+	)
+	assert.NoError(t, err)
+
+	logs := []*types.Log{
+		// ERC-721 transfer
+		{
+			Address: testTokenAddress,
+			Topics: []common.Hash{
+				eventTopic("Transfer(address,address,uint256)"),
+				addressTopic(testFromAddress),
+				addressTopic(testToAddress),
+				common.BigToHash(big.NewInt(42)),
+			},
+			Index: 0,
+		},
+		// ERC-20 transfers are not NFT transfers
+		{
+			Address: testTokenAddress,
+			Topics: []common.Hash{
+				eventTopic("Transfer(address,address,uint256)"),
+				addressTopic(testFromAddress),
+				addressTopic(testToAddress),
+			},
+			Index: 1,
+		},
+		// ERC-1155 batch mint
+		{
+			Address: testTokenAddress,
+			Topics: []common.Hash{
+				eventTopic("TransferBatch(address,address,address,uint256[],uint256[])"),
+				addressTopic(testFromAddress),
+				{},
+				addressTopic(testToAddress),
+			},
+			Data:  batchData,
+			Index: 2,
+		},
+	}
+
+	ops := nftOps(logs, 3)
+	assert.Equal(t, []*RosettaTypes.Operation{
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 3},
+			Type:                ERC721TransferOpType,
+			Status:              RosettaTypes.String(SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: from},
+			Amount:              &RosettaTypes.Amount{Value: "-1", Currency: erc721Currency},
+			Metadata:            map[string]interface{}{"log_index": uint(0)},
+		},
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 4},
+			RelatedOperations:   []*RosettaTypes.OperationIdentifier{{Index: 3}},
+			Type:                ERC721TransferOpType,
+			Status:              RosettaTypes.String(SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: to},
+			Amount:              &RosettaTypes.Amount{Value: "1", Currency: erc721Currency},
+			Metadata:            map[string]interface{}{"log_index": uint(0)},
+		},
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 5},
+			Type:                ERC1155TransferOpType,
+			Status:              RosettaTypes.String(SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: to},
+			Amount: &RosettaTypes.Amount{
+				Value:    "10",
+				Currency: NFTCurrency(ERC1155Standard, testTokenAddress, big.NewInt(1)),
+			},
+			Metadata: map[string]interface{}{"log_index": uint(2)},
+		},
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 6},
+			Type:                ERC1155TransferOpType,
+			Status:              RosettaTypes.String(SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: to},
+			Amount: &RosettaTypes.Amount{
+				Value:    "20",
+				Currency: NFTCurrency(ERC1155Standard, testTokenAddress, big.NewInt(2)),
+			},
+			Metadata: map[string]interface{}{"log_index": uint(2)},
+		},
+	}, ops)
+}
+
+func TestSafeTransferFrom(t *testing.T) {
+	t.Run("erc721", func(t *testing.T) {
+		data, err := SafeTransferFromData(ERC721Standard, testFromAddress, testToAddress, big.NewInt(42), big.NewInt(1))
+		assert.NoError(t, err)
+		assert.Equal(t, "0x42842e0e", hexutil.Encode(data[:4]))
+
+		transfer, err := ParseSafeTransferFrom(data)
+		assert.NoError(t, err)
+		assert.Equal(t, &NFTTransfer{
+			Standard: ERC721Standard,
+			From:     testFromAddress,
+			To:       testToAddress,
+			TokenID:  big.NewInt(42),
+			Amount:   big.NewInt(1),
+		}, transfer)
+
+		_, err = SafeTransferFromData(ERC721Standard, testFromAddress, testToAddress, big.NewInt(42), big.NewInt(2))
+		assert.Error(t, err)
+	})
+
+	t.Run("erc1155", func(t *testing.T) {
+		data, err := SafeTransferFromData(ERC1155Standard, testFromAddress, testToAddress, big.NewInt(7), big.NewInt(5))
+		assert.NoError(t, err)
+		assert.Equal(t, "0xf242432a", hexutil.Encode(data[:4]))
+
+		transfer, err := ParseSafeTransferFrom(data)
+		assert.NoError(t, err)
+		assert.Equal(t, &NFTTransfer{
+			Standard: ERC1155Standard,
+			From:     testFromAddress,
+			To:       testToAddress,
+			TokenID:  big.NewInt(7),
+			Amount:   big.NewInt(5),
+		}, transfer)
+	})
+
+	t.Run("unknown call", func(t *testing.T) {
+		_, err := ParseSafeTransferFrom(nil)
+		assert.ErrorIs(t, err, ErrUnknownSafeTransferFrom)
+
+		_, err = ParseSafeTransferFrom(hexutil.MustDecode("0xa9059cbb"))
+		assert.ErrorIs(t, err, ErrUnknownSafeTransferFrom)
+	})
+}
+
+func TestParseNFTCurrency(t *testing.T) {
+	currency := NFTCurrency(ERC1155Standard, testTokenAddress, big.NewInt(7))
+	contract, tokenID, err := ParseNFTCurrency(ERC1155Standard, currency)
+	assert.NoError(t, err)
+	assert.Equal(t, testTokenAddress, contract)
+	assert.Equal(t, big.NewInt(7), tokenID)
+
+	_, _, err = ParseNFTCurrency(ERC721Standard, currency)
+	assert.ErrorIs(t, err, ErrInvalidNFTCurrency)
+
+	_, _, err = ParseNFTCurrency(ERC1155Standard, &RosettaTypes.Currency{
+		Symbol:   ERC1155Standard,
+		Metadata: map[string]interface{}{"contract_address": testTokenAddress.Hex(), "token_id": "0x7"},
+	})
+	assert.ErrorIs(t, err, ErrInvalidNFTCurrency)
+}
@@ -17,43 +17,102 @@ package fantom
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/exec"
 	"strings"
-
-	"golang.org/x/sync/errgroup"
+	"sync"
+	"time"
 )
 
 const (
 	operaLogger       = "opera"
 	operaStdErrLogger = "opera err"
+
+	// operaMinRestartBackoff is the delay before Opera
+	// is restarted after its first unexpected exit.
+	operaMinRestartBackoff = 1 * time.Second
+
+	// operaMaxRestartBackoff is the maximum delay
+	// between two restarts of Opera.
+	operaMaxRestartBackoff = 2 * time.Minute
+
+	// operaStableRuntime is how long Opera must run
+	// before the restart backoff is reset.
+	operaStableRuntime = 10 * time.Minute
+
+	// operaDefaultLogLevel is used for lines without
+	// a recognizable log level.
+	operaDefaultLogLevel = "INFO"
 )
 
-// logPipe prints out logs from Opera. We don't end when context
-// is canceled because there are often logs printed after this.
+// operaLogLevels are the log levels printed by Opera.
+var operaLogLevels = map[string]string{
+	"TRACE": "TRACE",
+	"DEBUG": "DEBUG",
+	"INFO":  "INFO",
+	"WARN":  "WARN",
+	"ERROR": "ERROR",
+	"EROR":  "ERROR",
+	"CRIT":  "CRIT",
+}
+
+// parseOperaLogLine extracts the log level from a line printed by
+// Opera. Both the terminal format ("INFO [10-19|12:00:00.000] msg")
+// and the logfmt format ("t=... lvl=info msg=...") are supported.
+// The returned message excludes the log level.
+func parseOperaLogLine(line string) (string, string) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return operaDefaultLogLevel, line
+	}
+
+	if level, ok := operaLogLevels[strings.ToUpper(fields[0])]; ok {
+		return level, strings.TrimSpace(line[len(fields[0]):])
+	}
+
+	for _, field := range fields {
+		if !strings.HasPrefix(field, "lvl=") {
+			continue
+		}
+
+		if level, ok := operaLogLevels[strings.ToUpper(strings.TrimPrefix(field, "lvl="))]; ok {
+			message := strings.Join(strings.Fields(strings.Replace(line, field, "", 1)), " ")
+			return level, message
+		}
+	}
+
+	return operaDefaultLogLevel, line
+}
+
+// logPipe prints out logs from Opera with their log level. We
+// don't end when context is canceled because there are often
+// logs printed after this.
 func logPipe(pipe io.ReadCloser, identifier string) error {
 	reader := bufio.NewReader(pipe)
 	for {
 		str, err := reader.ReadString('\n')
+		if len(str) > 0 {
+			level, message := parseOperaLogLine(strings.ReplaceAll(str, "\n", ""))
+			log.Printf("%s level=%s %s\n", identifier, level, message)
+		}
+
 		if err != nil {
 			log.Println("closing", identifier, err)
 			return err
 		}
-
-		message := strings.ReplaceAll(str, "\n", "")
-		log.Println(identifier, message)
 	}
 }
 
-// StartOpera starts Opera daemon in another goroutine
-// and logs the results to the console.
-func StartOpera(ctx context.Context, arguments string, g *errgroup.Group) error {
+// runOpera runs the Opera daemon until it exits. An interrupt
+// is sent to the process when ctx is canceled.
+func runOpera(ctx context.Context, binary string, arguments string) error {
 	parsedArgs := strings.Split(arguments, " ")
 	cmd := exec.Command(
-		"/app/opera",
+		binary,
 		parsedArgs...,
 	) // #nosec G204
 
@@ -67,24 +126,84 @@ func StartOpera(ctx context.Context, arguments string, g *errgroup.Group) error
 		return err
 	}
 
-	g.Go(func() error {
-		return logPipe(stdout, operaLogger)
-	})
-
-	g.Go(func() error {
-		return logPipe(stderr, operaStdErrLogger)
-	})
-
 	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("%w: unable to start Opera", err)
 	}
 
-	g.Go(func() error {
-		<-ctx.Done()
+	var pipes sync.WaitGroup
+	pipes.Add(2) // nolint:gomnd
+	go func() {
+		defer pipes.Done()
+		_ = logPipe(stdout, operaLogger)
+	}()
+	go func() {
+		defer pipes.Done()
+		_ = logPipe(stderr, operaStdErrLogger)
+	}()
 
-		log.Println("sending interrupt to Opera")
-		return cmd.Process.Signal(os.Interrupt)
-	})
+	exited := make(chan struct{})
+	defer close(exited)
+	go func() {
+		select {
+		case <-ctx.Done():
+			log.Println("sending interrupt to Opera")
+			if err := cmd.Process.Signal(os.Interrupt); err != nil {
+				log.Println("unable to interrupt Opera", err)
+			}
+		case <-exited:
+		}
+	}()
 
+	// All output must be read before calling Wait.
+	pipes.Wait()
 	return cmd.Wait()
 }
+
+// StartOpera starts the Opera daemon and logs the results to the
+// console. If Opera exits before ctx is canceled, it is restarted
+// with an exponential backoff. onExit (if not nil) is called every
+// time the process exits so that callers can stop relying on it
+// until it is ready again.
+func StartOpera(
+	ctx context.Context,
+	binary string,
+	arguments string,
+	onExit func(),
+) error {
+	backoff := operaMinRestartBackoff
+	for {
+		started := time.Now()
+		err := runOpera(ctx, binary, arguments)
+		if onExit != nil {
+			onExit()
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// A binary that cannot be started will not
+		// start on a later attempt either.
+		var execErr *exec.Error
+		var pathErr *os.PathError
+		if errors.As(err, &execErr) || errors.As(err, &pathErr) {
+			return err
+		}
+
+		if time.Since(started) >= operaStableRuntime {
+			backoff = operaMinRestartBackoff
+		}
+
+		log.Printf("Opera exited unexpectedly (%v), restarting in %s\n", err, backoff)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > operaMaxRestartBackoff {
+			backoff = operaMaxRestartBackoff
+		}
+	}
+}
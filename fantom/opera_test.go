@@ -0,0 +1,87 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOperaLogLine(t *testing.T) {
+	tests := map[string]struct {
+		line    string
+		level   string
+		message string
+	}{
+		"terminal format": {
+			line:    "INFO [10-19|12:00:00.000] New LLR summary  last_epoch=1",
+			level:   "INFO",
+			message: "[10-19|12:00:00.000] New LLR summary  last_epoch=1",
+		},
+		"terminal format (short error)": {
+			line:    "EROR [10-19|12:00:00.000] Failed to connect",
+			level:   "ERROR",
+			message: "[10-19|12:00:00.000] Failed to connect",
+		},
+		"logfmt format": {
+			line:    "t=2022-10-19T12:00:00+0000 lvl=warn msg=\"Dropping peer\"",
+			level:   "WARN",
+			message: "t=2022-10-19T12:00:00+0000 msg=\"Dropping peer\"",
+		},
+		"no level": {
+			line:    "Fatal: unable to open datadir",
+			level:   "INFO",
+			message: "Fatal: unable to open datadir",
+		},
+		"empty": {
+			line:    "",
+			level:   "INFO",
+			message: "",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			level, message := parseOperaLogLine(test.line)
+			assert.Equal(t, test.level, level)
+			assert.Equal(t, test.message, message)
+		})
+	}
+}
+
+func TestStartOpera_MissingBinary(t *testing.T) {
+	var exits int32
+	err := StartOpera(context.Background(), "/nonexistent/opera", "--", func() {
+		atomic.AddInt32(&exits, 1)
+	})
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&exits))
+}
+
+func TestStartOpera_Restart(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), operaMinRestartBackoff+500*time.Millisecond)
+	defer cancel()
+
+	var exits int32
+	err := StartOpera(ctx, "true", "--", func() {
+		atomic.AddInt32(&exits, 1)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&exits))
+}
@@ -0,0 +1,97 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// MetadataProfile determines the data included in
+// the metadata of transactions.
+type MetadataProfile string
+
+const (
+	// MetadataProfileFull includes the receipt, with its
+	// logs, the decoded logs (if an ABIRegistry is set) and
+	// the raw trace of transactions.
+	MetadataProfileFull MetadataProfile = "full"
+
+	// MetadataProfileCompact includes only the status, gas
+	// used and contract address of the receipt, and omits its
+	// logs, its logs bloom, the decoded logs and the raw trace.
+	MetadataProfileCompact MetadataProfile = "compact"
+
+	// MetadataProfileNone omits the receipt, the decoded
+	// logs and the raw trace, leaving the gas limit, gas
+	// price, fee breakdown and revert reason of transactions.
+	MetadataProfileNone MetadataProfile = "none"
+)
+
+// ParseMetadataProfile returns the MetadataProfile
+// named value, ignoring case.
+func ParseMetadataProfile(value string) (MetadataProfile, error) {
+	profile := MetadataProfile(strings.ToLower(value))
+	switch profile {
+	case MetadataProfileFull, MetadataProfileCompact, MetadataProfileNone:
+		return profile, nil
+	default:
+		return "", fmt.Errorf("%s is not a valid metadata profile", value)
+	}
+}
+
+// BlockOptions determine the operations returned by
+// Client.Block and Client.Transaction. The zero value
+// returns the operations used for reconciliation.
+type BlockOptions struct {
+	// IncludeZeroValueCalls includes the zero-value CALL,
+	// CALLCODE, DELEGATECALL and STATICCALL frames of traces
+	// as operations without amount and adds the gas used of
+	// all call frames to the metadata of their operations.
+	IncludeZeroValueCalls bool
+
+	// MetadataProfile determines the data included in the
+	// metadata of transactions. Defaults to MetadataProfileFull.
+	MetadataProfile MetadataProfile
+
+	// IncludeWFTMOperations includes the WRAP and UNWRAP
+	// operations of the deposits to and withdrawals from the
+	// wrapped FTM contract, in the WFTMCurrency.
+	IncludeWFTMOperations bool
+
+	// IncludeNFTOperations includes the ERC721_TRANSFER and
+	// ERC1155_TRANSFER operations of the transfers of ERC-721
+	// and ERC-1155 tokens, in their NFTCurrency.
+	IncludeNFTOperations bool
+}
+
+type blockOptionsContextKey struct{}
+
+// WithBlockOptions returns a copy of ctx making Client.Block
+// and Client.Transaction use options.
+func WithBlockOptions(ctx context.Context, options *BlockOptions) context.Context {
+	return context.WithValue(ctx, blockOptionsContextKey{}, options)
+}
+
+// BlockOptionsFromContext returns the BlockOptions of ctx,
+// or the default options if none are set.
+func BlockOptionsFromContext(ctx context.Context) *BlockOptions {
+	if options, ok := ctx.Value(blockOptionsContextKey{}).(*BlockOptions); ok && options != nil {
+		return options
+	}
+
+	return &BlockOptions{}
+}
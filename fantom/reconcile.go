@@ -0,0 +1,495 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net/http"
+	"time"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// DefaultReconcileRestartDelay is the delay before the
+	// background reconciler restarts after an error.
+	DefaultReconcileRestartDelay = 30 * time.Second
+
+	// reconcileAlertTimeout is the timeout of
+	// requests posting mismatches to the alert URL.
+	reconcileAlertTimeout = 10 * time.Second
+)
+
+// BalanceFetcher fetches blocks, the current head and
+// balances. *Client implements BalanceFetcher.
+type BalanceFetcher interface {
+	BlockFetcher
+
+	Balance(
+		context.Context,
+		*RosettaTypes.AccountIdentifier,
+		*RosettaTypes.PartialBlockIdentifier,
+	) (*RosettaTypes.AccountBalanceResponse, error)
+}
+
+// ReconcileConfig configures Reconcile.
+type ReconcileConfig struct {
+	// Accounts are the accounts to reconcile.
+	Accounts []*RosettaTypes.AccountCurrency
+
+	// Exempt are the accounts whose mismatches are reported
+	// but not alerted on, in the format of the exempt accounts
+	// of rosetta-cli.
+	Exempt []*RosettaTypes.AccountCurrency
+
+	// Start is the block at which the computed balances are
+	// initialized to the balances of the node. If Start is
+	// negative, the current head is used.
+	Start int64
+
+	// End is the last block to reconcile. If End is
+	// negative, blocks are reconciled up to the current head.
+	End int64
+
+	// Follow keeps reconciling new blocks once the
+	// head is reached. End is ignored.
+	Follow bool
+
+	// Concurrency is the number of blocks fetched in parallel.
+	Concurrency int
+
+	// CheckInterval is the number of blocks between
+	// balance comparisons. The last block is always compared.
+	CheckInterval int64
+
+	// PollInterval is the interval at which the head is
+	// polled when following the tip.
+	PollInterval time.Duration
+
+	// AlertURL optionally receives each mismatch of a
+	// non-exempt account as a JSON POST request.
+	AlertURL string
+}
+
+// ReconcileMismatch is a difference between the balance computed
+// from the operations of an account and its balance on the node.
+// The operations causing it are in the blocks (StartIndex, EndIndex].
+type ReconcileMismatch struct {
+	Account    *RosettaTypes.AccountIdentifier `json:"account_identifier"`
+	Currency   *RosettaTypes.Currency          `json:"currency"`
+	StartIndex int64                           `json:"start_index"`
+	EndIndex   int64                           `json:"end_index"`
+	Computed   string                          `json:"computed_balance"`
+	Live       string                          `json:"live_balance"`
+	Exempt     bool                            `json:"exempt"`
+}
+
+// ReconcileResult summarizes a reconciliation.
+type ReconcileResult struct {
+	StartIndex int64                `json:"start_index"`
+	EndIndex   int64                `json:"end_index"`
+	Mismatches []*ReconcileMismatch `json:"mismatches"`
+}
+
+// Failed returns true if any non-exempt account mismatched.
+func (r *ReconcileResult) Failed() bool {
+	for _, mismatch := range r.Mismatches {
+		if !mismatch.Exempt {
+			return true
+		}
+	}
+
+	return false
+}
+
+// reconciledAccount is the state of an account being reconciled.
+type reconciledAccount struct {
+	accountCurrency *RosettaTypes.AccountCurrency
+	exempt          bool
+	computed        *big.Int
+	checkedIndex    int64
+}
+
+// LoadAccountCurrencies loads a list of accounts and currencies
+// in the format of the exempt accounts of rosetta-cli.
+func LoadAccountCurrencies(path string) ([]*RosettaTypes.AccountCurrency, error) {
+	contents, err := ioutil.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := []*RosettaTypes.AccountCurrency{}
+	if err := json.Unmarshal(contents, &accounts); err != nil {
+		return nil, fmt.Errorf("%w: unable to parse %s", err, path)
+	}
+
+	for _, account := range accounts {
+		if account.Account == nil {
+			return nil, fmt.Errorf("account identifier missing in %s", path)
+		}
+
+		if account.Currency == nil {
+			account.Currency = Currency
+		}
+	}
+
+	return accounts, nil
+}
+
+// Reconcile replays the operations of the configured accounts
+// and compares the computed balances with the balances of the node
+// at the same block. Each mismatch is logged, posted to AlertURL if
+// the account is not exempt, and the computed balance is reset to
+// the balance of the node. Reconcile only returns when all blocks
+// are reconciled, ctx is cancelled or a request fails.
+func Reconcile( // nolint:gocognit
+	ctx context.Context,
+	fetcher BalanceFetcher,
+	cfg *ReconcileConfig,
+) (*ReconcileResult, error) {
+	if len(cfg.Accounts) == 0 {
+		return nil, errors.New("no accounts to reconcile")
+	}
+
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultExportConcurrency
+	}
+
+	checkInterval := cfg.CheckInterval
+	if checkInterval <= 0 {
+		checkInterval = 1
+	}
+
+	pollInterval := cfg.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultExportPollInterval
+	}
+
+	start := cfg.Start
+	end := cfg.End
+	if start < 0 || end < 0 || cfg.Follow {
+		head, err := fetchHead(ctx, fetcher)
+		if err != nil {
+			return nil, err
+		}
+
+		if start < 0 {
+			start = head
+		}
+		end = head
+	}
+
+	exempt := map[string]bool{}
+	for _, account := range cfg.Exempt {
+		exempt[RosettaTypes.Hash(normalizeAccountCurrency(account))] = true
+	}
+
+	accounts := []*reconciledAccount{}
+	for _, account := range cfg.Accounts {
+		accountCurrency := normalizeAccountCurrency(account)
+		computed, err := liveBalance(ctx, fetcher, accountCurrency, start)
+		if err != nil {
+			return nil, err
+		}
+
+		accounts = append(accounts, &reconciledAccount{
+			accountCurrency: accountCurrency,
+			exempt:          exempt[RosettaTypes.Hash(accountCurrency)],
+			computed:        computed,
+			checkedIndex:    start,
+		})
+	}
+
+	result := &ReconcileResult{
+		StartIndex: start,
+		EndIndex:   start,
+		Mismatches: []*ReconcileMismatch{},
+	}
+
+	// Only the operations of blocks are reconciled, so
+	// the metadata of their transactions is omitted.
+	blocksCtx := WithBlockOptions(ctx, &BlockOptions{MetadataProfile: MetadataProfileNone})
+
+	next := start + 1
+	for {
+		if next > end {
+			if !cfg.Follow {
+				return result, nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			case <-time.After(pollInterval):
+			}
+
+			head, err := fetchHead(ctx, fetcher)
+			if err != nil {
+				return result, err
+			}
+			end = head
+			continue
+		}
+
+		size := int64(concurrency)
+		if remaining := end - next + 1; remaining < size {
+			size = remaining
+		}
+
+		blocks, err := fetchBlocks(blocksCtx, fetcher, next, size)
+		if err != nil {
+			return result, err
+		}
+
+		for _, block := range blocks {
+			if err := applyBlock(accounts, block); err != nil {
+				return result, err
+			}
+
+			index := block.BlockIdentifier.Index
+			result.EndIndex = index
+			if (index-start)%checkInterval != 0 && index != end {
+				continue
+			}
+
+			mismatches, err := checkBalances(ctx, fetcher, accounts, index)
+			if err != nil {
+				return result, err
+			}
+
+			for _, mismatch := range mismatches {
+				reportMismatch(ctx, cfg.AlertURL, mismatch)
+			}
+			result.Mismatches = append(result.Mismatches, mismatches...)
+		}
+
+		next += size
+	}
+}
+
+// RunReconciler reconciles the configured accounts from the
+// current head until ctx is cancelled. After any error, the
+// reconciliation restarts from the new head.
+func RunReconciler(ctx context.Context, fetcher BalanceFetcher, cfg *ReconcileConfig) error {
+	for {
+		followCfg := *cfg
+		followCfg.Start = -1
+		followCfg.Follow = true
+
+		_, err := Reconcile(ctx, fetcher, &followCfg)
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.Printf("%s: reconciliation stopped, restarting in %s\n", err.Error(), DefaultReconcileRestartDelay)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(DefaultReconcileRestartDelay):
+		}
+	}
+}
+
+// normalizeAccountCurrency checksums the address of the account
+// so that it matches the accounts of operations. The currency
+// defaults to FTM.
+func normalizeAccountCurrency(account *RosettaTypes.AccountCurrency) *RosettaTypes.AccountCurrency {
+	normalized := *account.Account
+	if checksum, ok := ChecksumAddress(normalized.Address); ok {
+		normalized.Address = checksum
+	}
+
+	currency := account.Currency
+	if currency == nil {
+		currency = Currency
+	}
+
+	return &RosettaTypes.AccountCurrency{
+		Account:  &normalized,
+		Currency: currency,
+	}
+}
+
+// applyBlock adds the successful operations
+// of block to the computed balances.
+func applyBlock(accounts []*reconciledAccount, block *RosettaTypes.Block) error {
+	tracked := map[string]*reconciledAccount{}
+	for _, account := range accounts {
+		tracked[RosettaTypes.Hash(account.accountCurrency)] = account
+	}
+
+	for _, tx := range block.Transactions {
+		for _, op := range tx.Operations {
+			if op.Account == nil || op.Amount == nil {
+				continue
+			}
+
+			if op.Status == nil || *op.Status != SuccessStatus {
+				continue
+			}
+
+			account, ok := tracked[RosettaTypes.Hash(normalizeAccountCurrency(&RosettaTypes.AccountCurrency{
+				Account:  op.Account,
+				Currency: op.Amount.Currency,
+			}))]
+			if !ok {
+				continue
+			}
+
+			value, ok := new(big.Int).SetString(op.Amount.Value, 10) // nolint:gomnd
+			if !ok {
+				return fmt.Errorf(
+					"invalid amount %s in transaction %s",
+					op.Amount.Value,
+					tx.TransactionIdentifier.Hash,
+				)
+			}
+			account.computed.Add(account.computed, value)
+		}
+	}
+
+	return nil
+}
+
+// checkBalances compares the computed balances with the balances
+// of the node at index. The computed balances of mismatching
+// accounts are reset to the balances of the node.
+func checkBalances(
+	ctx context.Context,
+	fetcher BalanceFetcher,
+	accounts []*reconciledAccount,
+	index int64,
+) ([]*ReconcileMismatch, error) {
+	mismatches := []*ReconcileMismatch{}
+	for _, account := range accounts {
+		live, err := liveBalance(ctx, fetcher, account.accountCurrency, index)
+		if err != nil {
+			return nil, err
+		}
+
+		if live.Cmp(account.computed) != 0 {
+			mismatches = append(mismatches, &ReconcileMismatch{
+				Account:    account.accountCurrency.Account,
+				Currency:   account.accountCurrency.Currency,
+				StartIndex: account.checkedIndex,
+				EndIndex:   index,
+				Computed:   account.computed.String(),
+				Live:       live.String(),
+				Exempt:     account.exempt,
+			})
+			account.computed = live
+		}
+		account.checkedIndex = index
+	}
+
+	return mismatches, nil
+}
+
+// liveBalance returns the balance of the node at index.
+func liveBalance(
+	ctx context.Context,
+	fetcher BalanceFetcher,
+	account *RosettaTypes.AccountCurrency,
+	index int64,
+) (*big.Int, error) {
+	response, err := fetcher.Balance(ctx, account.Account, &RosettaTypes.PartialBlockIdentifier{
+		Index: &index,
+	})
+	if err != nil {
+		return nil, fmt.Errorf(
+			"%w: unable to fetch balance of %s at block %d",
+			err,
+			account.Account.Address,
+			index,
+		)
+	}
+
+	for _, amount := range response.Balances {
+		if RosettaTypes.Hash(amount.Currency) != RosettaTypes.Hash(account.Currency) {
+			continue
+		}
+
+		value, ok := new(big.Int).SetString(amount.Value, 10) // nolint:gomnd
+		if !ok {
+			return nil, fmt.Errorf("invalid balance %s of %s", amount.Value, account.Account.Address)
+		}
+		return value, nil
+	}
+
+	return big.NewInt(0), nil
+}
+
+// reportMismatch logs mismatch and posts it
+// to alertURL if the account is not exempt.
+func reportMismatch(ctx context.Context, alertURL string, mismatch *ReconcileMismatch) {
+	label := "RECONCILIATION MISMATCH"
+	if mismatch.Exempt {
+		label = "exempt reconciliation mismatch"
+	}
+	log.Printf(
+		"%s: %s %s computed %s but node has %s in blocks (%d, %d]\n",
+		label,
+		mismatch.Account.Address,
+		mismatch.Currency.Symbol,
+		mismatch.Computed,
+		mismatch.Live,
+		mismatch.StartIndex,
+		mismatch.EndIndex,
+	)
+
+	if mismatch.Exempt || len(alertURL) == 0 {
+		return
+	}
+
+	if err := postReconcileAlert(ctx, alertURL, mismatch); err != nil {
+		log.Printf("%s: unable to post reconciliation alert\n", err.Error())
+	}
+}
+
+func postReconcileAlert(ctx context.Context, alertURL string, mismatch *ReconcileMismatch) error {
+	body, err := json.Marshal(mismatch)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reconcileAlertTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, alertURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("alert rejected with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
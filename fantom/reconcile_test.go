@@ -0,0 +1,232 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	reconcileAlice = "0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d"
+	reconcileBob   = "0x881d953652933937186BDf0680eD3c3c8a0162Ab"
+)
+
+// testBalanceFetcher serves blocks transferring 1 from alice to bob.
+// Block 0 contains no operations and failed transfers are included
+// in the blocks listed in failed.
+type testBalanceFetcher struct {
+	head   int64
+	failed map[int64]bool
+
+	// offsets are added to the live balances
+	// of an address from a block on.
+	offsets map[string]map[int64]int64
+}
+
+func (f *testBalanceFetcher) Status(context.Context) (
+	*RosettaTypes.BlockIdentifier,
+	int64,
+	*RosettaTypes.SyncStatus,
+	[]*RosettaTypes.Peer,
+	error,
+) {
+	return &RosettaTypes.BlockIdentifier{Index: f.head, Hash: fmt.Sprintf("0x%x", f.head)}, 0, nil, nil, nil
+}
+
+func (f *testBalanceFetcher) Block(
+	ctx context.Context,
+	blockIdentifier *RosettaTypes.PartialBlockIdentifier,
+) (*RosettaTypes.Block, error) {
+	index := *blockIdentifier.Index
+	block := &RosettaTypes.Block{
+		BlockIdentifier: &RosettaTypes.BlockIdentifier{Index: index, Hash: fmt.Sprintf("0x%x", index)},
+		Transactions:    []*RosettaTypes.Transaction{},
+	}
+	if index == 0 {
+		return block, nil
+	}
+
+	status := SuccessStatus
+	if f.failed[index] {
+		status = FailureStatus
+	}
+	block.Transactions = append(block.Transactions, &RosettaTypes.Transaction{
+		TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: fmt.Sprintf("0xt%x", index)},
+		Operations: []*RosettaTypes.Operation{
+			{
+				OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 0},
+				Type:                CallOpType,
+				Status:              &status,
+				Account:             &RosettaTypes.AccountIdentifier{Address: reconcileAlice},
+				Amount:              &RosettaTypes.Amount{Value: "-1", Currency: Currency},
+			},
+			{
+				OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 1},
+				Type:                CallOpType,
+				Status:              &status,
+				Account:             &RosettaTypes.AccountIdentifier{Address: reconcileBob},
+				Amount:              &RosettaTypes.Amount{Value: "1", Currency: Currency},
+			},
+		},
+	})
+
+	return block, nil
+}
+
+func (f *testBalanceFetcher) Balance(
+	ctx context.Context,
+	account *RosettaTypes.AccountIdentifier,
+	block *RosettaTypes.PartialBlockIdentifier,
+) (*RosettaTypes.AccountBalanceResponse, error) {
+	index := *block.Index
+	transfers := int64(0)
+	for i := int64(1); i <= index; i++ {
+		if !f.failed[i] {
+			transfers++
+		}
+	}
+
+	balance := 1000 - transfers
+	if strings.EqualFold(account.Address, reconcileBob) {
+		balance = transfers
+	}
+
+	for from, offset := range f.offsets[strings.ToLower(account.Address)] {
+		if index >= from {
+			balance += offset
+		}
+	}
+
+	return &RosettaTypes.AccountBalanceResponse{
+		BlockIdentifier: &RosettaTypes.BlockIdentifier{Index: index, Hash: fmt.Sprintf("0x%x", index)},
+		Balances: []*RosettaTypes.Amount{
+			{Value: big.NewInt(balance).String(), Currency: Currency},
+		},
+	}, nil
+}
+
+func TestReconcile(t *testing.T) {
+	ctx := context.Background()
+	accounts := []*RosettaTypes.AccountCurrency{
+		{Account: &RosettaTypes.AccountIdentifier{Address: strings.ToLower(reconcileAlice)}},
+		{Account: &RosettaTypes.AccountIdentifier{Address: reconcileBob}, Currency: Currency},
+	}
+
+	t.Run("balanced", func(t *testing.T) {
+		result, err := Reconcile(ctx, &testBalanceFetcher{
+			head:   20,
+			failed: map[int64]bool{3: true, 7: true},
+		}, &ReconcileConfig{
+			Accounts:    accounts,
+			Start:       0,
+			End:         -1,
+			Concurrency: 3,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, &ReconcileResult{
+			StartIndex: 0,
+			EndIndex:   20,
+			Mismatches: []*ReconcileMismatch{},
+		}, result)
+		assert.False(t, result.Failed())
+	})
+
+	t.Run("mismatches", func(t *testing.T) {
+		var lock sync.Mutex
+		alerts := []*ReconcileMismatch{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var mismatch ReconcileMismatch
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&mismatch))
+			lock.Lock()
+			alerts = append(alerts, &mismatch)
+			lock.Unlock()
+		}))
+		defer server.Close()
+
+		result, err := Reconcile(ctx, &testBalanceFetcher{
+			head: 20,
+			offsets: map[string]map[int64]int64{
+				strings.ToLower(reconcileAlice): {7: 5},
+				strings.ToLower(reconcileBob):   {12: -2},
+			},
+		}, &ReconcileConfig{
+			Accounts: accounts,
+			Exempt: []*RosettaTypes.AccountCurrency{
+				{Account: &RosettaTypes.AccountIdentifier{Address: strings.ToLower(reconcileBob)}},
+			},
+			Start:         2,
+			End:           18,
+			CheckInterval: 4,
+			AlertURL:      server.URL,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, &ReconcileResult{
+			StartIndex: 2,
+			EndIndex:   18,
+			Mismatches: []*ReconcileMismatch{
+				{
+					Account:    &RosettaTypes.AccountIdentifier{Address: reconcileAlice},
+					Currency:   Currency,
+					StartIndex: 6,
+					EndIndex:   10,
+					Computed:   "990",
+					Live:       "995",
+				},
+				{
+					Account:    &RosettaTypes.AccountIdentifier{Address: reconcileBob},
+					Currency:   Currency,
+					StartIndex: 10,
+					EndIndex:   14,
+					Computed:   "14",
+					Live:       "12",
+					Exempt:     true,
+				},
+			},
+		}, result)
+		assert.True(t, result.Failed())
+		assert.Equal(t, result.Mismatches[:1], alerts)
+	})
+
+	t.Run("no accounts", func(t *testing.T) {
+		result, err := Reconcile(ctx, &testBalanceFetcher{}, &ReconcileConfig{})
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+}
+
+func TestLoadAccountCurrencies(t *testing.T) {
+	accounts, err := LoadAccountCurrencies("../rosetta-cli-conf/mainnet/exempt_accounts.json")
+	assert.NoError(t, err)
+	assert.Equal(t, []*RosettaTypes.AccountCurrency{
+		{
+			Account:  &RosettaTypes.AccountIdentifier{Address: "0xFC00FACE00000000000000000000000000000000"},
+			Currency: Currency,
+		},
+	}, accounts)
+
+	_, err = LoadAccountCurrencies("testdata/missing.json")
+	assert.Error(t, err)
+}
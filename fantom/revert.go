@@ -0,0 +1,150 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// revertErrorsABIJSON contains the custom errors decoded
+	// in revert reasons. These are the standard token errors
+	// of ERC-6093 used by OpenZeppelin contracts.
+	revertErrorsABIJSON = `[
+	{"type": "error", "name": "ERC20InsufficientBalance", "inputs": [
+		{"name": "sender", "type": "address"},
+		{"name": "balance", "type": "uint256"},
+		{"name": "needed", "type": "uint256"}
+	]},
+	{"type": "error", "name": "ERC20InvalidSender", "inputs": [{"name": "sender", "type": "address"}]},
+	{"type": "error", "name": "ERC20InvalidReceiver", "inputs": [{"name": "receiver", "type": "address"}]},
+	{"type": "error", "name": "ERC20InsufficientAllowance", "inputs": [
+		{"name": "spender", "type": "address"},
+		{"name": "allowance", "type": "uint256"},
+		{"name": "needed", "type": "uint256"}
+	]},
+	{"type": "error", "name": "ERC20InvalidApprover", "inputs": [{"name": "approver", "type": "address"}]},
+	{"type": "error", "name": "ERC20InvalidSpender", "inputs": [{"name": "spender", "type": "address"}]},
+	{"type": "error", "name": "ERC721InvalidOwner", "inputs": [{"name": "owner", "type": "address"}]},
+	{"type": "error", "name": "ERC721NonexistentToken", "inputs": [{"name": "tokenId", "type": "uint256"}]},
+	{"type": "error", "name": "ERC721IncorrectOwner", "inputs": [
+		{"name": "sender", "type": "address"},
+		{"name": "tokenId", "type": "uint256"},
+		{"name": "owner", "type": "address"}
+	]},
+	{"type": "error", "name": "ERC721InvalidSender", "inputs": [{"name": "sender", "type": "address"}]},
+	{"type": "error", "name": "ERC721InvalidReceiver", "inputs": [{"name": "receiver", "type": "address"}]},
+	{"type": "error", "name": "ERC721InsufficientApproval", "inputs": [
+		{"name": "operator", "type": "address"},
+		{"name": "tokenId", "type": "uint256"}
+	]},
+	{"type": "error", "name": "ERC1155InsufficientBalance", "inputs": [
+		{"name": "sender", "type": "address"},
+		{"name": "balance", "type": "uint256"},
+		{"name": "needed", "type": "uint256"},
+		{"name": "tokenId", "type": "uint256"}
+	]},
+	{"type": "error", "name": "ERC1155InvalidSender", "inputs": [{"name": "sender", "type": "address"}]},
+	{"type": "error", "name": "ERC1155InvalidReceiver", "inputs": [{"name": "receiver", "type": "address"}]},
+	{"type": "error", "name": "ERC1155MissingApprovalForAll", "inputs": [
+		{"name": "operator", "type": "address"},
+		{"name": "owner", "type": "address"}
+	]}
+]`
+)
+
+var (
+	// revertErrorSelector is the selector of Error(string).
+	revertErrorSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+
+	// revertPanicSelector is the selector of Panic(uint256).
+	revertPanicSelector = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+
+	// panicReasons are the descriptions of the Solidity panic codes.
+	panicReasons = map[uint64]string{
+		0x00: "generic panic",
+		0x01: "assert(false)",
+		0x11: "arithmetic underflow or overflow",
+		0x12: "division or modulo by zero",
+		0x21: "enum overflow",
+		0x22: "invalid encoded storage byte array accessed",
+		0x31: "out-of-bounds array access; popping on an empty array",
+		0x32: "out-of-bounds access of an array or bytesN",
+		0x41: "out of memory",
+		0x51: "uninitialized function",
+	}
+
+	// revertErrorABIs are the whitelisted ABIs whose custom
+	// errors are decoded in revert reasons.
+	revertErrorABIs = []abi.ABI{mustParseABI(revertErrorsABIJSON)}
+)
+
+// decodeRevertReason decodes the revert data of a call into a
+// human-readable reason. It supports Error(string), Panic(uint256)
+// and the custom errors of revertErrorABIs. An empty string is
+// returned if the revert data cannot be decoded.
+func decodeRevertReason(data []byte) string {
+	if len(data) < 4 { // nolint:gomnd
+		return ""
+	}
+
+	switch {
+	case bytes.Equal(data[:4], revertErrorSelector):
+		reason, err := abi.UnpackRevert(data)
+		if err != nil {
+			return ""
+		}
+		return reason
+	case bytes.Equal(data[:4], revertPanicSelector):
+		if len(data) != 4+32 { // nolint:gomnd
+			return ""
+		}
+		code := new(big.Int).SetBytes(data[4:])
+		reason, ok := panicReasons[code.Uint64()]
+		if !code.IsUint64() || !ok {
+			reason = "unknown panic code"
+		}
+		return fmt.Sprintf("Panic(0x%02x): %s", code, reason)
+	}
+
+	for _, parsed := range revertErrorABIs {
+		for _, customError := range parsed.Errors {
+			if !bytes.Equal(data[:4], customError.ID[:4]) {
+				continue
+			}
+
+			values, err := customError.Inputs.Unpack(data[4:])
+			if err != nil {
+				continue
+			}
+
+			fields := make([]string, len(values))
+			for i, value := range values {
+				input := customError.Inputs[i]
+				fields[i] = fmt.Sprintf("%s=%v", input.Name, abiJSON(input.Type, value))
+			}
+
+			return fmt.Sprintf("%s(%s)", customError.Name, strings.Join(fields, ", "))
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,114 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// sfcABIJSON is the subset of the Special Fee Contract (SFC)
+	// ABI used by rosetta-fantom, including the staking events
+	// decoded in transaction metadata.
+	sfcABIJSON = `[
+	{
+		"type": "function",
+		"name": "getValidator",
+		"stateMutability": "view",
+		"inputs": [{"name": "", "type": "uint256"}],
+		"outputs": [
+			{"name": "status", "type": "uint256"},
+			{"name": "deactivatedTime", "type": "uint256"},
+			{"name": "deactivatedEpoch", "type": "uint256"},
+			{"name": "receivedStake", "type": "uint256"},
+			{"name": "createdEpoch", "type": "uint256"},
+			{"name": "createdTime", "type": "uint256"},
+			{"name": "auth", "type": "address"}
+		]
+	},
+	{"type": "event", "name": "CreatedValidator", "inputs": [
+		{"name": "validatorID", "type": "uint256", "indexed": true},
+		{"name": "auth", "type": "address", "indexed": true},
+		{"name": "createdEpoch", "type": "uint256", "indexed": false},
+		{"name": "createdTime", "type": "uint256", "indexed": false}
+	]},
+	{"type": "event", "name": "Delegated", "inputs": [
+		{"name": "delegator", "type": "address", "indexed": true},
+		{"name": "toValidatorID", "type": "uint256", "indexed": true},
+		{"name": "amount", "type": "uint256", "indexed": false}
+	]},
+	{"type": "event", "name": "Undelegated", "inputs": [
+		{"name": "delegator", "type": "address", "indexed": true},
+		{"name": "toValidatorID", "type": "uint256", "indexed": true},
+		{"name": "wrID", "type": "uint256", "indexed": true},
+		{"name": "amount", "type": "uint256", "indexed": false}
+	]},
+	{"type": "event", "name": "Withdrawn", "inputs": [
+		{"name": "delegator", "type": "address", "indexed": true},
+		{"name": "toValidatorID", "type": "uint256", "indexed": true},
+		{"name": "wrID", "type": "uint256", "indexed": true},
+		{"name": "amount", "type": "uint256", "indexed": false}
+	]},
+	{"type": "event", "name": "ClaimedRewards", "inputs": [
+		{"name": "delegator", "type": "address", "indexed": true},
+		{"name": "toValidatorID", "type": "uint256", "indexed": true},
+		{"name": "lockupExtraReward", "type": "uint256", "indexed": false},
+		{"name": "lockupBaseReward", "type": "uint256", "indexed": false},
+		{"name": "unlockedReward", "type": "uint256", "indexed": false}
+	]},
+	{"type": "event", "name": "RestakedRewards", "inputs": [
+		{"name": "delegator", "type": "address", "indexed": true},
+		{"name": "toValidatorID", "type": "uint256", "indexed": true},
+		{"name": "lockupExtraReward", "type": "uint256", "indexed": false},
+		{"name": "lockupBaseReward", "type": "uint256", "indexed": false},
+		{"name": "unlockedReward", "type": "uint256", "indexed": false}
+	]},
+	{"type": "event", "name": "LockedUpStake", "inputs": [
+		{"name": "delegator", "type": "address", "indexed": true},
+		{"name": "validatorID", "type": "uint256", "indexed": true},
+		{"name": "duration", "type": "uint256", "indexed": false},
+		{"name": "amount", "type": "uint256", "indexed": false}
+	]},
+	{"type": "event", "name": "UnlockedStake", "inputs": [
+		{"name": "delegator", "type": "address", "indexed": true},
+		{"name": "validatorID", "type": "uint256", "indexed": true},
+		{"name": "amount", "type": "uint256", "indexed": false},
+		{"name": "penalty", "type": "uint256", "indexed": false}
+	]}
+]`
+)
+
+var (
+	// SFCAddress is the address of the Special Fee Contract (SFC)
+	// managing validators, delegations and rewards.
+	SFCAddress = common.HexToAddress("0xFC00FACE00000000000000000000000000000000")
+
+	sfcABI = mustParseABI(sfcABIJSON)
+)
+
+// mustParseABI parses a JSON ABI. It panics if the
+// ABI is invalid, so it is only suitable for ABIs
+// embedded in the binary.
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+
+	return parsed
+}
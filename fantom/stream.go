@@ -0,0 +1,92 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"context"
+	"time"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// DefaultStreamPollInterval is the default interval at
+	// which the head is polled when streaming blocks.
+	DefaultStreamPollInterval = time.Second
+)
+
+// StreamBlocks calls send with each block from start, in order,
+// following the head as new blocks are produced, until ctx is
+// done or send fails. If start is negative, streaming starts with
+// the first block after the current head. Blocks behind the head
+// are fetched in parallel batches of DefaultExportConcurrency.
+//
+// Opera blocks are final once they are produced, so every streamed
+// block is final and no block is ever streamed twice.
+func StreamBlocks(
+	ctx context.Context,
+	fetcher BlockFetcher,
+	start int64,
+	pollInterval time.Duration,
+	send func(*RosettaTypes.Block) error,
+) error {
+	if pollInterval <= 0 {
+		pollInterval = DefaultStreamPollInterval
+	}
+
+	head, err := fetchHead(ctx, fetcher)
+	if err != nil {
+		return err
+	}
+
+	next := start
+	if next < 0 {
+		next = head + 1
+	}
+
+	for {
+		if next > head {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(pollInterval):
+			}
+
+			head, err = fetchHead(ctx, fetcher)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		size := int64(DefaultExportConcurrency)
+		if remaining := head - next + 1; remaining < size {
+			size = remaining
+		}
+
+		blocks, err := fetchBlocks(ctx, fetcher, next, size)
+		if err != nil {
+			return err
+		}
+
+		for _, block := range blocks {
+			if err := send(block); err != nil {
+				return err
+			}
+		}
+
+		next += size
+	}
+}
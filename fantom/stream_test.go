@@ -0,0 +1,77 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamBlocks(t *testing.T) {
+	errDone := errors.New("done")
+
+	t.Run("resume and follow", func(t *testing.T) {
+		fetcher := &testBlockFetcher{head: 12}
+		indexes := []int64{}
+		err := StreamBlocks(context.Background(), fetcher, 3, time.Millisecond, func(block *RosettaTypes.Block) error {
+			indexes = append(indexes, block.BlockIdentifier.Index)
+			if block.BlockIdentifier.Index == 12 {
+				atomic.StoreInt64(&fetcher.head, 14)
+			}
+			if block.BlockIdentifier.Index == 14 {
+				return errDone
+			}
+
+			return nil
+		})
+		assert.ErrorIs(t, err, errDone)
+		assert.Equal(t, []int64{3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14}, indexes)
+	})
+
+	t.Run("from head", func(t *testing.T) {
+		fetcher := &testBlockFetcher{head: 5}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			time.Sleep(5 * time.Millisecond)
+			atomic.StoreInt64(&fetcher.head, 6)
+		}()
+
+		var streamed int64
+		err := StreamBlocks(ctx, fetcher, -1, time.Millisecond, func(block *RosettaTypes.Block) error {
+			streamed = block.BlockIdentifier.Index
+			cancel()
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, int64(6), streamed)
+	})
+
+	t.Run("fetch failure", func(t *testing.T) {
+		fetcher := &testBlockFetcher{head: 5, failAt: 4}
+		err := StreamBlocks(context.Background(), fetcher, 0, time.Millisecond, func(*RosettaTypes.Block) error {
+			return nil
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unable to fetch block 4")
+	})
+}
@@ -0,0 +1,337 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// TxStatusPending is the status of a submitted transaction
+	// still waiting in the Opera txpool.
+	TxStatusPending = "PENDING"
+
+	// TxStatusIncluded is the status of a submitted transaction
+	// included in a block.
+	TxStatusIncluded = "INCLUDED"
+
+	// TxStatusReplaced is the status of a submitted transaction
+	// whose nonce was consumed by another transaction.
+	TxStatusReplaced = "REPLACED"
+
+	// TxStatusDropped is the status of a submitted transaction
+	// that left the txpool without being included.
+	TxStatusDropped = "DROPPED"
+
+	// trackerPollInterval is how often the statuses of tracked
+	// transactions are refreshed in the background.
+	trackerPollInterval = 10 * time.Second
+
+	// trackerRetention is how long a transaction is kept
+	// after it reached a final status or was dropped.
+	trackerRetention = 1 * time.Hour
+)
+
+// TrackedTransaction is the lifecycle of a transaction submitted
+// through /construction/submit.
+type TrackedTransaction struct {
+	Hash         common.Hash
+	From         common.Address
+	Nonce        uint64
+	Status       string
+	BlockNumber  *int64
+	BlockHash    *common.Hash
+	SubmittedAt  time.Time
+	Rebroadcasts int
+
+	// UpdatedAt is the time of the last status change.
+	UpdatedAt time.Time
+
+	tx            *types.Transaction
+	lastBroadcast time.Time
+}
+
+type trackedTransactionWire struct {
+	Hash         string  `json:"hash"`
+	From         string  `json:"from"`
+	Nonce        string  `json:"nonce"`
+	Status       string  `json:"status"`
+	BlockNumber  *int64  `json:"block_number,omitempty"`
+	BlockHash    *string `json:"block_hash,omitempty"`
+	SubmittedAt  int64   `json:"submitted_at"`
+	UpdatedAt    int64   `json:"updated_at"`
+	Rebroadcasts int     `json:"rebroadcasts"`
+}
+
+// MarshalJSON is a custom marshaler for TrackedTransaction.
+func (t *TrackedTransaction) MarshalJSON() ([]byte, error) {
+	tw := &trackedTransactionWire{
+		Hash:         t.Hash.Hex(),
+		From:         MustChecksum(t.From.Hex()),
+		Nonce:        hexutil.EncodeUint64(t.Nonce),
+		Status:       t.Status,
+		BlockNumber:  t.BlockNumber,
+		SubmittedAt:  t.SubmittedAt.UnixNano() / int64(time.Millisecond),
+		UpdatedAt:    t.UpdatedAt.UnixNano() / int64(time.Millisecond),
+		Rebroadcasts: t.Rebroadcasts,
+	}
+	if t.BlockHash != nil {
+		blockHash := t.BlockHash.Hex()
+		tw.BlockHash = &blockHash
+	}
+
+	return json.Marshal(tw)
+}
+
+// final returns a boolean indicating if the status
+// of the tracked transaction can no longer change.
+func (t *TrackedTransaction) final() bool {
+	return t.Status == TxStatusIncluded || t.Status == TxStatusReplaced
+}
+
+// expired returns a boolean indicating if the tracked transaction
+// is no longer kept. Final and dropped transactions are evicted
+// trackerRetention after their last status change, so that
+// transactions which are never included do not accumulate.
+func (t *TrackedTransaction) expired() bool {
+	return (t.final() || t.Status == TxStatusDropped) && time.Since(t.UpdatedAt) > trackerRetention
+}
+
+// txTracker keeps the lifecycle of all transactions
+// submitted through the client.
+type txTracker struct {
+	mu  sync.Mutex
+	txs map[common.Hash]*TrackedTransaction
+}
+
+func newTxTracker() *txTracker {
+	return &txTracker{
+		txs: map[common.Hash]*TrackedTransaction{},
+	}
+}
+
+func (t *txTracker) track(tx *types.Transaction, from common.Address) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.txs[tx.Hash()] = &TrackedTransaction{
+		Hash:          tx.Hash(),
+		From:          from,
+		Nonce:         tx.Nonce(),
+		Status:        TxStatusPending,
+		SubmittedAt:   now,
+		UpdatedAt:     now,
+		tx:            tx,
+		lastBroadcast: now,
+	}
+}
+
+// get returns a copy of the tracked transaction so that
+// callers never race with background refreshes.
+func (t *txTracker) get(hash common.Hash) (*TrackedTransaction, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tracked, ok := t.txs[hash]
+	if !ok {
+		return nil, false
+	}
+
+	c := *tracked
+	return &c, true
+}
+
+// update stores the refreshed status of tracked. Only the status
+// fields are stored, so that concurrent refreshes never overwrite
+// the broadcasts recorded by broadcasted, and final statuses are
+// never overwritten by a refresh that started before them.
+func (t *txTracker) update(tracked *TrackedTransaction) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, ok := t.txs[tracked.Hash]
+	if !ok || entry.final() {
+		return
+	}
+
+	entry.Status = tracked.Status
+	entry.BlockNumber = tracked.BlockNumber
+	entry.BlockHash = tracked.BlockHash
+	entry.UpdatedAt = tracked.UpdatedAt
+}
+
+// broadcasted records a broadcast of the tracked transaction
+// with hash, counted as a rebroadcast if it succeeded.
+func (t *txTracker) broadcasted(hash common.Hash, succeeded bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, ok := t.txs[hash]
+	if !ok {
+		return
+	}
+
+	if succeeded {
+		entry.Rebroadcasts++
+	}
+	entry.lastBroadcast = time.Now()
+}
+
+func (t *txTracker) snapshot() []*TrackedTransaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tracked := make([]*TrackedTransaction, 0, len(t.txs))
+	for hash, tx := range t.txs {
+		if tx.expired() {
+			delete(t.txs, hash)
+			continue
+		}
+
+		c := *tx
+		tracked = append(tracked, &c)
+	}
+
+	return tracked
+}
+
+// refreshTrackedTransaction determines the current status of a tracked
+// transaction by looking for its receipt, then for the transaction
+// in the txpool and finally at the account nonce.
+func (ec *Client) refreshTrackedTransaction(
+	ctx context.Context,
+	tracked *TrackedTransaction,
+) error {
+	if tracked.final() {
+		return nil
+	}
+
+	receipt, err := ec.transactionReceipt(ctx, tracked.Hash)
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
+		return fmt.Errorf("%w: could not get tx receipt for %s", err, tracked.Hash.Hex())
+	}
+
+	status := tracked.Status
+	defer func() {
+		if tracked.Status != status {
+			tracked.UpdatedAt = time.Now()
+		}
+	}()
+
+	if receipt != nil {
+		blockNumber := receipt.BlockNumber.Int64()
+		blockHash := receipt.BlockHash
+		tracked.Status = TxStatusIncluded
+		tracked.BlockNumber = &blockNumber
+		tracked.BlockHash = &blockHash
+		return nil
+	}
+
+	var raw json.RawMessage
+	if err := ec.c.CallContext(ctx, &raw, "eth_getTransactionByHash", tracked.Hash); err != nil {
+		return fmt.Errorf("%w: transaction fetch failed", err)
+	}
+	if len(raw) > 0 && string(raw) != "null" {
+		tracked.Status = TxStatusPending
+		return nil
+	}
+
+	nonce, err := ec.nonceAt(ctx, tracked.From, nil)
+	if err != nil {
+		return fmt.Errorf("%w: could not get nonce for %s", err, tracked.From.Hex())
+	}
+
+	if nonce > tracked.Nonce {
+		tracked.Status = TxStatusReplaced
+	} else {
+		tracked.Status = TxStatusDropped
+	}
+
+	return nil
+}
+
+// TrackedTransaction returns the refreshed lifecycle of a
+// transaction submitted with SendTransaction.
+func (ec *Client) TrackedTransaction(
+	ctx context.Context,
+	hash common.Hash,
+) (*TrackedTransaction, error) {
+	if ec.tracker == nil {
+		return nil, fmt.Errorf("%w: %s", ErrTransactionNotTracked, hash.Hex())
+	}
+
+	tracked, ok := ec.tracker.get(hash)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTransactionNotTracked, hash.Hex())
+	}
+
+	if err := ec.refreshTrackedTransaction(ctx, tracked); err != nil {
+		return nil, err
+	}
+	ec.tracker.update(tracked)
+
+	return tracked, nil
+}
+
+// TrackTransactions periodically refreshes the status of all
+// transactions submitted with SendTransaction. If rebroadcastInterval
+// is not zero, pending and dropped transactions are re-submitted
+// once the interval has elapsed since their last broadcast.
+func (ec *Client) TrackTransactions(ctx context.Context, rebroadcastInterval time.Duration) error {
+	if ec.tracker == nil {
+		return nil
+	}
+
+	ticker := time.NewTicker(trackerPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		for _, tracked := range ec.tracker.snapshot() {
+			if err := ec.refreshTrackedTransaction(ctx, tracked); err != nil {
+				log.Printf("unable to refresh tracked transaction %s: %s\n", tracked.Hash.Hex(), err)
+				continue
+			}
+
+			ec.tracker.update(tracked)
+
+			if rebroadcastInterval > 0 && !tracked.final() &&
+				time.Since(tracked.lastBroadcast) >= rebroadcastInterval {
+				err := ec.sendRawTransaction(ctx, tracked.tx)
+				if err != nil {
+					log.Printf("unable to rebroadcast transaction %s: %s\n", tracked.Hash.Hex(), err)
+				}
+				ec.tracker.broadcasted(tracked.Hash, err == nil)
+			}
+		}
+	}
+}
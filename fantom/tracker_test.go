@@ -0,0 +1,89 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxTracker_Snapshot(t *testing.T) {
+	tracker := newTxTracker()
+	expired := time.Now().Add(-trackerRetention - time.Minute)
+	recent := time.Now()
+
+	statuses := map[common.Hash]struct {
+		status    string
+		updatedAt time.Time
+		kept      bool
+	}{
+		common.HexToHash("0x1"): {status: TxStatusPending, updatedAt: expired, kept: true},
+		common.HexToHash("0x2"): {status: TxStatusIncluded, updatedAt: expired},
+		common.HexToHash("0x3"): {status: TxStatusReplaced, updatedAt: expired},
+		common.HexToHash("0x4"): {status: TxStatusDropped, updatedAt: expired},
+		common.HexToHash("0x5"): {status: TxStatusDropped, updatedAt: recent, kept: true},
+		common.HexToHash("0x6"): {status: TxStatusIncluded, updatedAt: recent, kept: true},
+	}
+	for hash, test := range statuses {
+		tracker.txs[hash] = &TrackedTransaction{Hash: hash, Status: test.status, UpdatedAt: test.updatedAt}
+	}
+
+	kept := map[common.Hash]bool{}
+	for _, tracked := range tracker.snapshot() {
+		kept[tracked.Hash] = true
+	}
+
+	for hash, test := range statuses {
+		assert.Equal(t, test.kept, kept[hash], test.status)
+		_, ok := tracker.txs[hash]
+		assert.Equal(t, test.kept, ok, test.status)
+	}
+}
+
+func TestTxTracker_Update(t *testing.T) {
+	tracker := newTxTracker()
+	tx := types.NewTransaction(0, common.Address{}, nil, 21000, nil, nil)
+	tracker.track(tx, common.Address{})
+
+	// A refresh based on a copy taken before a rebroadcast
+	// does not overwrite the rebroadcast
+	refreshed, ok := tracker.get(tx.Hash())
+	assert.True(t, ok)
+	tracker.broadcasted(tx.Hash(), true)
+
+	blockNumber := int64(10)
+	refreshed.Status = TxStatusIncluded
+	refreshed.BlockNumber = &blockNumber
+	tracker.update(refreshed)
+
+	tracked, ok := tracker.get(tx.Hash())
+	assert.True(t, ok)
+	assert.Equal(t, TxStatusIncluded, tracked.Status)
+	assert.Equal(t, &blockNumber, tracked.BlockNumber)
+	assert.Equal(t, 1, tracked.Rebroadcasts)
+
+	// Final statuses are not overwritten by earlier refreshes
+	stale := *tracked
+	stale.Status = TxStatusPending
+	tracker.update(&stale)
+
+	tracked, ok = tracker.get(tx.Hash())
+	assert.True(t, ok)
+	assert.Equal(t, TxStatusIncluded, tracked.Status)
+}
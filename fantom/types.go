@@ -48,6 +48,11 @@ const (
 	// FeeOpType is used to represent fee operations.
 	FeeOpType = "FEE"
 
+	// FeeBurnSubtype is the "subtype" in the metadata of the
+	// FEE operation of the part of a fee burned by EIP-1559,
+	// telling it apart from the part paid to validators.
+	FeeBurnSubtype = "BURN"
+
 	// CallOpType is used to represent CALL trace operations.
 	CallOpType = "CALL"
 
@@ -74,6 +79,22 @@ const (
 	// of a transaction.
 	DestructOpType = "DESTRUCT"
 
+	// WrapOpType is used to represent the WFTM minted
+	// by deposits to the wrapped FTM contract.
+	WrapOpType = "WRAP"
+
+	// UnwrapOpType is used to represent the WFTM burned
+	// by withdrawals from the wrapped FTM contract.
+	UnwrapOpType = "UNWRAP"
+
+	// ERC721TransferOpType is used to represent
+	// the transfers of ERC-721 tokens.
+	ERC721TransferOpType = "ERC721_TRANSFER"
+
+	// ERC1155TransferOpType is used to represent
+	// the transfers of ERC-1155 tokens.
+	ERC1155TransferOpType = "ERC1155_TRANSFER"
+
 	// SuccessStatus is the status of any
 	// Opera operation considered successful.
 	SuccessStatus = "SUCCESS"
@@ -139,6 +160,10 @@ var (
 		DelegateCallOpType,
 		StaticCallOpType,
 		DestructOpType,
+		WrapOpType,
+		UnwrapOpType,
+		ERC721TransferOpType,
+		ERC1155TransferOpType,
 	}
 
 	// OperationStatuses are all supported operation statuses.
@@ -159,6 +184,18 @@ var (
 		"eth_getTransactionReceipt",
 		"eth_call",
 		"eth_estimateGas",
+		"eth_getLogs",
+		"eth_getCode",
+		"eth_getStorageAt",
+		"eth_getBalance",
+		"eth_chainId",
+		"eth_feeHistory",
+		"ftm_currentEpoch",
+		"ftm_getEpochStats",
+		"sfc_getValidator",
+		"dag_getEvent",
+		"contract_read",
+		"transaction_status",
 	}
 )
 
@@ -0,0 +1,166 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"math/big"
+	"strings"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// WFTMSymbol is the symbol of wrapped FTM.
+	WFTMSymbol = "WFTM"
+
+	// wftmABIJSON is the subset of the WFTM ABI
+	// used by rosetta-fantom.
+	wftmABIJSON = `[
+	{"type": "event", "name": "Deposit", "inputs": [
+		{"name": "dst", "type": "address", "indexed": true},
+		{"name": "wad", "type": "uint256", "indexed": false}
+	]},
+	{"type": "event", "name": "Withdrawal", "inputs": [
+		{"name": "src", "type": "address", "indexed": true},
+		{"name": "wad", "type": "uint256", "indexed": false}
+	]}
+]`
+)
+
+var (
+	// WFTMMainnetAddress is the address of the
+	// wrapped FTM contract on mainnet.
+	WFTMMainnetAddress = common.HexToAddress("0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83")
+
+	// WFTMTestnetAddress is the address of the
+	// wrapped FTM contract on testnet.
+	WFTMTestnetAddress = common.HexToAddress("0xf1277d1Ed8AD466beddF92ef448A132661956621")
+
+	wftmABI = mustParseABI(wftmABIJSON)
+)
+
+// WFTMCurrency returns the *RosettaTypes.Currency of
+// the wrapped FTM contract deployed at address.
+func WFTMCurrency(address common.Address) *RosettaTypes.Currency {
+	return &RosettaTypes.Currency{
+		Symbol:   WFTMSymbol,
+		Decimals: Decimals,
+		Metadata: map[string]interface{}{
+			"contract_address": address.Hex(),
+		},
+	}
+}
+
+// isWFTM returns a boolean indicating if address is
+// the wrapped FTM contract of an Opera network.
+func isWFTM(address common.Address) bool {
+	return address == WFTMMainnetAddress || address == WFTMTestnetAddress
+}
+
+// wftmOps returns the WRAP and UNWRAP operations of the
+// Deposit and Withdrawal logs of wrapped FTM, indexed after
+// ops. Each operation relates to the native operation moving
+// the wrapped or unwrapped FTM: the call crediting the contract
+// for a deposit and the call crediting the account for a
+// withdrawal.
+func wftmOps(logs []*types.Log, ops []*RosettaTypes.Operation) []*RosettaTypes.Operation {
+	var wftm []*RosettaTypes.Operation
+	paired := map[int64]bool{}
+	for _, log := range logs {
+		if !isWFTM(log.Address) || len(log.Topics) != 2 { // nolint:gomnd
+			continue
+		}
+
+		event, err := wftmABI.EventByID(log.Topics[0])
+		if err != nil {
+			continue
+		}
+
+		values, err := event.Inputs.NonIndexed().Unpack(log.Data)
+		if err != nil || len(values) != 1 {
+			continue
+		}
+		wad := values[0].(*big.Int)
+		account := common.BytesToAddress(log.Topics[1].Bytes())
+
+		opType := WrapOpType
+		value := new(big.Int).Set(wad)
+		nativeAccount := log.Address
+		if event.Name == "Withdrawal" {
+			opType = UnwrapOpType
+			value.Neg(value)
+			nativeAccount = account
+		}
+
+		op := &RosettaTypes.Operation{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{
+				Index: int64(len(ops) + len(wftm)),
+			},
+			Type:   opType,
+			Status: RosettaTypes.String(SuccessStatus),
+			Account: &RosettaTypes.AccountIdentifier{
+				Address: MustChecksum(account.Hex()),
+			},
+			Amount: &RosettaTypes.Amount{
+				Value:    value.String(),
+				Currency: WFTMCurrency(log.Address),
+			},
+			Metadata: map[string]interface{}{
+				"log_index": log.Index,
+			},
+		}
+
+		if nativeOp := findNativeCredit(ops, nativeAccount, wad, paired); nativeOp != nil {
+			paired[nativeOp.Index] = true
+			op.RelatedOperations = []*RosettaTypes.OperationIdentifier{
+				{
+					Index: nativeOp.Index,
+				},
+			}
+		}
+
+		wftm = append(wftm, op)
+	}
+
+	return wftm
+}
+
+// findNativeCredit returns the identifier of the first successful
+// call operation crediting value FTM to address that is not paired
+// yet, or nil if there is none.
+func findNativeCredit(
+	ops []*RosettaTypes.Operation,
+	address common.Address,
+	value *big.Int,
+	paired map[int64]bool,
+) *RosettaTypes.OperationIdentifier {
+	for _, op := range ops {
+		if !CallType(op.Type) || op.Status == nil || *op.Status != SuccessStatus {
+			continue
+		}
+
+		if op.Amount == nil || op.Amount.Value != value.String() || paired[op.OperationIdentifier.Index] {
+			continue
+		}
+
+		if strings.EqualFold(op.Account.Address, address.Hex()) {
+			return op.OperationIdentifier
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fantom
+
+import (
+	"math/big"
+	"testing"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func wftmLog(event string, account common.Address, wad int64, index uint) *types.Log {
+	return &types.Log{
+		Address: WFTMMainnetAddress,
+		Topics: []common.Hash{
+			wftmABI.Events[event].ID,
+			common.BytesToHash(account.Bytes()),
+		},
+		Data:  math.U256Bytes(big.NewInt(wad)),
+		Index: index,
+	}
+}
+
+func TestWFTMOps(t *testing.T) {
+	account := common.HexToAddress("0xfb8af5814b2c10746d76a0da99bbde1d9e2b6a2b")
+	wftm := MustChecksum(WFTMMainnetAddress.Hex())
+	sender := MustChecksum(account.Hex())
+
+	// Deposit of 5 and withdrawal of 3 in the same transaction
+	calls := []*flatCall{
+		{
+			Type:         CallOpType,
+			From:         account,
+			To:           WFTMMainnetAddress,
+			Value:        big.NewInt(5),
+			GasUsed:      new(big.Int),
+			TraceAddress: []int{},
+		},
+		{
+			Type:         CallOpType,
+			From:         WFTMMainnetAddress,
+			To:           account,
+			Value:        big.NewInt(3),
+			GasUsed:      new(big.Int),
+			TraceAddress: []int{0},
+		},
+	}
+	ops := traceOps(calls, 0, &BlockOptions{})
+	assert.Len(t, ops, 4)
+
+	logs := []*types.Log{
+		wftmLog("Deposit", account, 5, 0),
+		{
+			Address: common.HexToAddress("0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"),
+			Topics: []common.Hash{
+				wftmABI.Events["Deposit"].ID,
+				common.BytesToHash(account.Bytes()),
+			},
+			Data:  math.U256Bytes(big.NewInt(5)),
+			Index: 1,
+		},
+		wftmLog("Withdrawal", account, 3, 2),
+	}
+
+	wrapOps := wftmOps(logs, ops)
+	assert.Equal(t, []*RosettaTypes.Operation{
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 4},
+			RelatedOperations:   []*RosettaTypes.OperationIdentifier{{Index: 1}},
+			Type:                WrapOpType,
+			Status:              RosettaTypes.String(SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: sender},
+			Amount: &RosettaTypes.Amount{
+				Value:    "5",
+				Currency: WFTMCurrency(WFTMMainnetAddress),
+			},
+			Metadata: map[string]interface{}{"log_index": uint(0)},
+		},
+		{
+			OperationIdentifier: &RosettaTypes.OperationIdentifier{Index: 5},
+			RelatedOperations:   []*RosettaTypes.OperationIdentifier{{Index: 3}},
+			Type:                UnwrapOpType,
+			Status:              RosettaTypes.String(SuccessStatus),
+			Account:             &RosettaTypes.AccountIdentifier{Address: sender},
+			Amount: &RosettaTypes.Amount{
+				Value:    "-3",
+				Currency: WFTMCurrency(WFTMMainnetAddress),
+			},
+			Metadata: map[string]interface{}{"log_index": uint(2)},
+		},
+	}, wrapOps)
+	assert.Equal(t, wftm, ops[1].Account.Address)
+
+	// Operations are not paired without matching native calls
+	wrapOps = wftmOps(logs[:1], nil)
+	assert.Len(t, wrapOps, 1)
+	assert.Equal(t, int64(0), wrapOps[0].OperationIdentifier.Index)
+	assert.Nil(t, wrapOps[0].RelatedOperations)
+}
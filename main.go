@@ -15,6 +15,7 @@
 package main
 
 import (
+	"errors"
 	"os"
 
 	"github.com/Fantom-foundation/rosetta-fantom/cmd"
@@ -26,6 +27,11 @@ func main() {
 	err := cmd.Execute()
 	if err != nil {
 		color.Red(err.Error())
+
+		var exitErr interface{ ExitCode() int }
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
 		os.Exit(1)
 	}
 }
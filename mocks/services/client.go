@@ -112,6 +112,29 @@ func (_m *Client) GetMempool(ctx context.Context) (*types.MempoolResponse, error
 	return r0, r1
 }
 
+// MempoolTransaction provides a mock function with given fields: ctx, transactionIdentifier
+func (_m *Client) MempoolTransaction(ctx context.Context, transactionIdentifier *types.TransactionIdentifier) (*types.Transaction, error) {
+	ret := _m.Called(ctx, transactionIdentifier)
+
+	var r0 *types.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, *types.TransactionIdentifier) *types.Transaction); ok {
+		r0 = rf(ctx, transactionIdentifier)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *types.TransactionIdentifier) error); ok {
+		r1 = rf(ctx, transactionIdentifier)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // PendingNonceAt provides a mock function with given fields: _a0, _a1
 func (_m *Client) PendingNonceAt(_a0 context.Context, _a1 common.Address) (uint64, error) {
 	ret := _m.Called(_a0, _a1)
@@ -133,6 +156,20 @@ func (_m *Client) PendingNonceAt(_a0 context.Context, _a1 common.Address) (uint6
 	return r0, r1
 }
 
+// Ready provides a mock function with given fields: ctx
+func (_m *Client) Ready(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SendTransaction provides a mock function with given fields: ctx, tx
 func (_m *Client) SendTransaction(ctx context.Context, tx *coretypes.Transaction) error {
 	ret := _m.Called(ctx, tx)
@@ -0,0 +1,666 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operatest
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+const (
+	// GenesisTime is the timestamp of the genesis block.
+	// Each block is one second after its parent.
+	GenesisTime = 1600000000
+
+	// DefaultGasPrice is the gas price of the chain.
+	DefaultGasPrice = 1000000000
+
+	// blockGasLimit is the gas limit of blocks.
+	blockGasLimit = 0xffffffffffff
+)
+
+// SyncStatus is the progress reported by eth_syncing.
+type SyncStatus struct {
+	StartingBlock uint64
+	CurrentBlock  uint64
+	HighestBlock  uint64
+}
+
+// chainBlock is a block of a Chain with the
+// balances and nonces after its execution.
+type chainBlock struct {
+	header   *ethTypes.Header
+	txs      []*ethTypes.Transaction
+	senders  []common.Address
+	receipts []*ethTypes.Receipt
+	balances map[common.Address]*big.Int
+	nonces   map[common.Address]uint64
+}
+
+// Chain is a Backend serving a scripted in-memory chain. Blocks
+// only contain legacy value transfers, which pay the full gas
+// price as fee (there is no base fee). Blocks are produced with
+// Mine and the last blocks can be replaced with Reorg.
+type Chain struct {
+	mu       sync.RWMutex
+	chainID  *big.Int
+	signer   ethTypes.Signer
+	gasPrice *big.Int
+	blocks   []*chainBlock
+	pending  []*ethTypes.Transaction
+	syncing  *SyncStatus
+
+	// forks is stored in the extra data of new
+	// blocks so that replaced blocks get new hashes.
+	forks uint64
+}
+
+// NewChain returns a Chain whose genesis block allocates alloc.
+func NewChain(chainID *big.Int, alloc map[common.Address]*big.Int) *Chain {
+	c := &Chain{
+		chainID:  chainID,
+		signer:   ethTypes.NewEIP155Signer(chainID),
+		gasPrice: big.NewInt(DefaultGasPrice),
+	}
+
+	balances := map[common.Address]*big.Int{}
+	for address, balance := range alloc {
+		balances[address] = new(big.Int).Set(balance)
+	}
+	c.blocks = []*chainBlock{c.newBlock(nil, balances, map[common.Address]uint64{})}
+
+	return c
+}
+
+// ChainID returns the chain ID of the chain.
+func (c *Chain) ChainID() *big.Int {
+	return new(big.Int).Set(c.chainID)
+}
+
+// Head returns the header of the last block.
+func (c *Chain) Head() *ethTypes.Header {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return ethTypes.CopyHeader(c.blocks[len(c.blocks)-1].header)
+}
+
+// Header returns the header of the block at number.
+func (c *Chain) Header(number uint64) *ethTypes.Header {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if number >= uint64(len(c.blocks)) {
+		return nil
+	}
+
+	return ethTypes.CopyHeader(c.blocks[number].header)
+}
+
+// Transfer signs a transfer of value from the address of key
+// to to and adds it to the pending transactions.
+func (c *Chain) Transfer(
+	key *ecdsa.PrivateKey,
+	to common.Address,
+	value *big.Int,
+) (*ethTypes.Transaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	tx, err := ethTypes.SignTx(ethTypes.NewTransaction(
+		c.pendingNonce(from),
+		to,
+		value,
+		params.TxGas,
+		c.gasPrice,
+		nil,
+	), c.signer, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.addTransaction(tx); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// AddTransaction adds a signed transaction to
+// the pending transactions.
+func (c *Chain) AddTransaction(tx *ethTypes.Transaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.addTransaction(tx)
+}
+
+func (c *Chain) addTransaction(tx *ethTypes.Transaction) error {
+	if tx.Type() != ethTypes.LegacyTxType {
+		return fmt.Errorf("transaction type %d not supported", tx.Type())
+	}
+
+	from, err := ethTypes.Sender(c.signer, tx)
+	if err != nil {
+		return fmt.Errorf("invalid sender: %w", err)
+	}
+
+	if nonce := c.pendingNonce(from); tx.Nonce() != nonce {
+		return fmt.Errorf("invalid nonce %d, expected %d", tx.Nonce(), nonce)
+	}
+
+	if tx.Gas() < params.TxGas {
+		return errors.New("intrinsic gas too low")
+	}
+
+	cost := new(big.Int).Add(tx.Value(), new(big.Int).Mul(tx.GasPrice(), big.NewInt(int64(params.TxGas))))
+	if c.pendingBalance(from).Cmp(cost) < 0 {
+		return errors.New("insufficient funds for gas * price + value")
+	}
+
+	c.pending = append(c.pending, tx)
+	return nil
+}
+
+// pendingNonce returns the nonce of the next
+// transaction of address.
+func (c *Chain) pendingNonce(address common.Address) uint64 {
+	nonce := c.blocks[len(c.blocks)-1].nonces[address]
+	for _, tx := range c.pending {
+		if from, _ := ethTypes.Sender(c.signer, tx); from == address {
+			nonce++
+		}
+	}
+
+	return nonce
+}
+
+// pendingBalance returns the balance of address
+// once the pending transactions are executed.
+func (c *Chain) pendingBalance(address common.Address) *big.Int {
+	balance := new(big.Int).Set(balanceOf(c.blocks[len(c.blocks)-1].balances, address))
+	for _, tx := range c.pending {
+		from, _ := ethTypes.Sender(c.signer, tx)
+		if from == address {
+			balance.Sub(balance, tx.Value())
+			balance.Sub(balance, new(big.Int).Mul(tx.GasPrice(), big.NewInt(int64(params.TxGas))))
+		}
+		if to := tx.To(); to != nil && *to == address {
+			balance.Add(balance, tx.Value())
+		}
+	}
+
+	return balance
+}
+
+// Mine adds a block containing all pending
+// transactions and returns its header.
+func (c *Chain) Mine() *ethTypes.Header {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	parent := c.blocks[len(c.blocks)-1]
+	balances := map[common.Address]*big.Int{}
+	for address, balance := range parent.balances {
+		balances[address] = new(big.Int).Set(balance)
+	}
+	nonces := map[common.Address]uint64{}
+	for address, nonce := range parent.nonces {
+		nonces[address] = nonce
+	}
+
+	block := c.newBlock(c.pending, balances, nonces)
+	c.pending = nil
+	c.blocks = append(c.blocks, block)
+
+	return ethTypes.CopyHeader(block.header)
+}
+
+// Reorg removes the last depth blocks and returns their
+// transactions to the pending transactions. Blocks mined
+// afterwards have different hashes.
+func (c *Chain) Reorg(depth int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if depth <= 0 || depth >= len(c.blocks) {
+		return fmt.Errorf("invalid reorg depth %d", depth)
+	}
+
+	removed := []*ethTypes.Transaction{}
+	for _, block := range c.blocks[len(c.blocks)-depth:] {
+		removed = append(removed, block.txs...)
+	}
+
+	c.blocks = c.blocks[:len(c.blocks)-depth]
+	c.pending = append(removed, c.pending...)
+	c.forks++
+
+	return nil
+}
+
+// SetSyncing sets the progress reported by eth_syncing.
+// A nil status reports the node as synced.
+func (c *Chain) SetSyncing(status *SyncStatus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.syncing = status
+}
+
+// newBlock executes txs on top of the last block, updating
+// balances and nonces.
+func (c *Chain) newBlock(
+	txs []*ethTypes.Transaction,
+	balances map[common.Address]*big.Int,
+	nonces map[common.Address]uint64,
+) *chainBlock {
+	number := uint64(len(c.blocks))
+	extra := make([]byte, 8) // nolint:gomnd
+	binary.BigEndian.PutUint64(extra, c.forks)
+
+	header := &ethTypes.Header{
+		UncleHash:  ethTypes.EmptyUncleHash,
+		Root:       crypto.Keccak256Hash(extra, new(big.Int).SetUint64(number).Bytes()),
+		TxHash:     ethTypes.EmptyRootHash,
+		Difficulty: big.NewInt(0),
+		Number:     new(big.Int).SetUint64(number),
+		GasLimit:   blockGasLimit,
+		Time:       GenesisTime + number,
+		Extra:      extra,
+	}
+	if number > 0 {
+		header.ParentHash = c.blocks[number-1].header.Hash()
+	}
+
+	block := &chainBlock{
+		txs:      txs,
+		senders:  make([]common.Address, len(txs)),
+		receipts: make([]*ethTypes.Receipt, len(txs)),
+		balances: balances,
+		nonces:   nonces,
+	}
+
+	for i, tx := range txs {
+		from, _ := ethTypes.Sender(c.signer, tx)
+		block.senders[i] = from
+
+		fee := new(big.Int).Mul(tx.GasPrice(), big.NewInt(int64(params.TxGas)))
+		balances[from] = new(big.Int).Sub(balanceOf(balances, from), fee)
+		balances[from].Sub(balances[from], tx.Value())
+		balances[*tx.To()] = new(big.Int).Add(balanceOf(balances, *tx.To()), tx.Value())
+		nonces[from]++
+
+		header.GasUsed += params.TxGas
+		block.receipts[i] = &ethTypes.Receipt{
+			Type:              ethTypes.LegacyTxType,
+			Status:            ethTypes.ReceiptStatusSuccessful,
+			CumulativeGasUsed: header.GasUsed,
+			Logs:              []*ethTypes.Log{},
+			TxHash:            tx.Hash(),
+			GasUsed:           params.TxGas,
+			TransactionIndex:  uint(i),
+		}
+	}
+
+	if len(txs) > 0 {
+		header.TxHash = ethTypes.DeriveSha(ethTypes.Transactions(txs), trie.NewStackTrie(nil))
+		header.ReceiptHash = ethTypes.DeriveSha(ethTypes.Receipts(block.receipts), trie.NewStackTrie(nil))
+	} else {
+		header.ReceiptHash = ethTypes.EmptyRootHash
+	}
+
+	for _, receipt := range block.receipts {
+		receipt.BlockHash = header.Hash()
+		receipt.BlockNumber = header.Number
+	}
+	block.header = header
+
+	return block
+}
+
+func balanceOf(balances map[common.Address]*big.Int, address common.Address) *big.Int {
+	if balance, ok := balances[address]; ok {
+		return balance
+	}
+
+	return big.NewInt(0)
+}
+
+// Call implements the Backend interface.
+func (c *Chain) Call( // nolint:gocyclo
+	method string,
+	args []json.RawMessage,
+) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch method {
+	case "eth_chainId":
+		return (*hexutil.Big)(c.chainID), nil
+	case "net_version":
+		return c.chainID.String(), nil
+	case "eth_blockNumber":
+		return hexutil.Uint64(len(c.blocks) - 1), nil
+	case "eth_gasPrice":
+		return (*hexutil.Big)(c.gasPrice), nil
+	case "eth_syncing":
+		if c.syncing == nil {
+			return false, nil
+		}
+		return map[string]hexutil.Uint64{
+			"startingBlock": hexutil.Uint64(c.syncing.StartingBlock),
+			"currentBlock":  hexutil.Uint64(c.syncing.CurrentBlock),
+			"highestBlock":  hexutil.Uint64(c.syncing.HighestBlock),
+			"pulledStates":  0,
+			"knownStates":   0,
+		}, nil
+	case "admin_peers":
+		return []interface{}{}, nil
+	case "eth_getBlockByNumber", "eth_getBlockByHash":
+		var full bool
+		if err := decodeParams(args, nil, &full); err != nil {
+			return nil, err
+		}
+		block, err := c.blockParam(args)
+		if err != nil || block == nil {
+			return nil, err
+		}
+		return c.blockJSON(block, full), nil
+	case "eth_getUncleByBlockHashAndIndex":
+		return nil, nil
+	case "eth_getTransactionByHash":
+		var hash common.Hash
+		if err := decodeParams(args, &hash); err != nil {
+			return nil, err
+		}
+		return c.transactionByHash(hash), nil
+	case "eth_getTransactionReceipt":
+		var hash common.Hash
+		if err := decodeParams(args, &hash); err != nil {
+			return nil, err
+		}
+		block, index := c.findTransaction(hash)
+		if block == nil {
+			return nil, nil
+		}
+		return block.receipts[index], nil
+	case "eth_getBalance", "eth_getTransactionCount", "eth_getCode":
+		return c.accountState(method, args)
+	case "eth_sendRawTransaction":
+		var raw hexutil.Bytes
+		if err := decodeParams(args, &raw); err != nil {
+			return nil, err
+		}
+		var tx ethTypes.Transaction
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return nil, InvalidParams(err)
+		}
+		if err := c.addTransaction(&tx); err != nil {
+			return nil, err
+		}
+		return tx.Hash(), nil
+	case "eth_estimateGas":
+		return hexutil.Uint64(params.TxGas), nil
+	case "eth_call":
+		return hexutil.Bytes{}, nil
+	case "txpool_content":
+		return c.txPoolContent(), nil
+	case "debug_traceBlockByHash":
+		block, err := c.blockParam(args)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			return nil, errors.New("block not found")
+		}
+		traces := make([]map[string]interface{}, len(block.txs))
+		for i, tx := range block.txs {
+			traces[i] = map[string]interface{}{"result": callTrace(tx, block.senders[i])}
+		}
+		return traces, nil
+	case "debug_traceTransaction":
+		var hash common.Hash
+		if err := decodeParams(args, &hash); err != nil {
+			return nil, err
+		}
+		block, index := c.findTransaction(hash)
+		if block == nil {
+			return nil, fmt.Errorf("transaction %s not found", hash.Hex())
+		}
+		return callTrace(block.txs[index], block.senders[index]), nil
+	}
+
+	return nil, MethodNotFound(method)
+}
+
+// decodeParams decodes args into outputs.
+// A nil output skips the param.
+func decodeParams(args []json.RawMessage, outputs ...interface{}) error {
+	for i, output := range outputs {
+		if output == nil {
+			continue
+		}
+		if i >= len(args) {
+			return InvalidParams(fmt.Errorf("missing value for required argument %d", i))
+		}
+		if err := json.Unmarshal(args[i], output); err != nil {
+			return InvalidParams(err)
+		}
+	}
+
+	return nil
+}
+
+// blockParam returns the block selected by the first argument,
+// which is a block number, a block tag, a block hash or an
+// EIP-1898 object. A nil block is returned if it is unknown.
+func (c *Chain) blockParam(args []json.RawMessage) (*chainBlock, error) {
+	if len(args) == 0 {
+		return nil, InvalidParams(errors.New("missing block"))
+	}
+
+	var selector struct {
+		BlockNumber *string      `json:"blockNumber"`
+		BlockHash   *common.Hash `json:"blockHash"`
+	}
+	var tag string
+	if err := json.Unmarshal(args[0], &tag); err != nil {
+		if err := json.Unmarshal(args[0], &selector); err != nil {
+			return nil, InvalidParams(err)
+		}
+		if selector.BlockHash != nil {
+			tag = selector.BlockHash.Hex()
+		} else if selector.BlockNumber != nil {
+			tag = *selector.BlockNumber
+		}
+	}
+
+	switch {
+	case tag == "latest" || tag == "pending" || tag == "":
+		return c.blocks[len(c.blocks)-1], nil
+	case tag == "earliest":
+		return c.blocks[0], nil
+	case len(tag) == 2+2*common.HashLength:
+		hash := common.HexToHash(tag)
+		for _, block := range c.blocks {
+			if block.header.Hash() == hash {
+				return block, nil
+			}
+		}
+		return nil, nil
+	}
+
+	number, err := strconv.ParseUint(strings.TrimPrefix(tag, "0x"), 16, 64)
+	if err != nil {
+		return nil, InvalidParams(err)
+	}
+	if number >= uint64(len(c.blocks)) {
+		return nil, nil
+	}
+
+	return c.blocks[number], nil
+}
+
+// accountState answers eth_getBalance,
+// eth_getTransactionCount and eth_getCode.
+func (c *Chain) accountState(method string, args []json.RawMessage) (interface{}, error) {
+	var address common.Address
+	if err := decodeParams(args, &address); err != nil {
+		return nil, err
+	}
+
+	if method == "eth_getCode" {
+		return hexutil.Bytes{}, nil
+	}
+
+	pending := len(args) > 1 && strings.Contains(string(args[1]), "pending")
+	if method == "eth_getTransactionCount" && pending {
+		return hexutil.Uint64(c.pendingNonce(address)), nil
+	}
+
+	block, err := c.blockParam(args[1:])
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, errors.New("header not found")
+	}
+
+	if method == "eth_getTransactionCount" {
+		return hexutil.Uint64(block.nonces[address]), nil
+	}
+
+	return (*hexutil.Big)(balanceOf(block.balances, address)), nil
+}
+
+// findTransaction returns the block including
+// the transaction and its index in the block.
+func (c *Chain) findTransaction(hash common.Hash) (*chainBlock, int) {
+	for _, block := range c.blocks {
+		for i, tx := range block.txs {
+			if tx.Hash() == hash {
+				return block, i
+			}
+		}
+	}
+
+	return nil, -1
+}
+
+func (c *Chain) transactionByHash(hash common.Hash) map[string]interface{} {
+	if block, index := c.findTransaction(hash); block != nil {
+		return c.transactionJSON(block.txs[index], block.senders[index], block, index)
+	}
+
+	for _, tx := range c.pending {
+		if tx.Hash() == hash {
+			from, _ := ethTypes.Sender(c.signer, tx)
+			return c.transactionJSON(tx, from, nil, 0)
+		}
+	}
+
+	return nil
+}
+
+// transactionJSON encodes tx like the RPC API. A
+// nil block encodes a pending transaction.
+func (c *Chain) transactionJSON(
+	tx *ethTypes.Transaction,
+	from common.Address,
+	block *chainBlock,
+	index int,
+) map[string]interface{} {
+	encoded := map[string]interface{}{}
+	raw, _ := tx.MarshalJSON()
+	_ = json.Unmarshal(raw, &encoded)
+
+	encoded["from"] = from
+	encoded["blockHash"] = nil
+	encoded["blockNumber"] = nil
+	encoded["transactionIndex"] = nil
+	if block != nil {
+		encoded["blockHash"] = block.header.Hash()
+		encoded["blockNumber"] = (*hexutil.Big)(block.header.Number)
+		encoded["transactionIndex"] = hexutil.Uint64(index)
+	}
+
+	return encoded
+}
+
+func (c *Chain) blockJSON(block *chainBlock, full bool) map[string]interface{} {
+	encoded := map[string]interface{}{}
+	raw, _ := json.Marshal(block.header)
+	_ = json.Unmarshal(raw, &encoded)
+
+	txs := make([]interface{}, len(block.txs))
+	for i, tx := range block.txs {
+		if full {
+			txs[i] = c.transactionJSON(tx, block.senders[i], block, i)
+		} else {
+			txs[i] = tx.Hash()
+		}
+	}
+	encoded["transactions"] = txs
+	encoded["uncles"] = []common.Hash{}
+
+	return encoded
+}
+
+func (c *Chain) txPoolContent() map[string]interface{} {
+	pending := map[string]map[string]interface{}{}
+	for _, tx := range c.pending {
+		from, _ := ethTypes.Sender(c.signer, tx)
+		if _, ok := pending[from.Hex()]; !ok {
+			pending[from.Hex()] = map[string]interface{}{}
+		}
+		pending[from.Hex()][strconv.FormatUint(tx.Nonce(), 10)] = c.transactionJSON(tx, from, nil, 0)
+	}
+
+	return map[string]interface{}{
+		"pending": pending,
+		"queued":  map[string]interface{}{},
+	}
+}
+
+// callTrace returns the trace of a transfer in
+// the format of the call tracer of rosetta-fantom.
+func callTrace(tx *ethTypes.Transaction, from common.Address) map[string]interface{} {
+	return map[string]interface{}{
+		"type":    "CALL",
+		"from":    from,
+		"to":      tx.To(),
+		"value":   (*hexutil.Big)(tx.Value()),
+		"gas":     hexutil.Uint64(tx.Gas() - params.TxGas),
+		"gasUsed": hexutil.Uint64(0),
+		"input":   hexutil.Bytes(tx.Data()),
+		"output":  hexutil.Bytes{},
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operatest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// matchedParams are the number of parameters matched for the
+// methods whose trailing parameters are ignored. The tracer
+// configuration of traces changes with the tracer code and
+// must not invalidate recorded traces.
+var matchedParams = map[string]int{
+	"debug_traceBlockByHash":   1,
+	"debug_traceBlockByNumber": 1,
+	"debug_traceTransaction":   1,
+}
+
+// Fixture is a recorded JSON-RPC call. A fixture
+// without params answers the method for any params.
+type Fixture struct {
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage   `json:"result,omitempty"`
+	Error  *Error            `json:"error,omitempty"`
+}
+
+// Fixtures is a Backend replaying recorded calls.
+type Fixtures struct {
+	mu    sync.RWMutex
+	calls map[string]*Fixture
+}
+
+// NewFixtures returns a Backend replaying fixtures.
+func NewFixtures(fixtures ...*Fixture) *Fixtures {
+	f := &Fixtures{calls: map[string]*Fixture{}}
+	for _, fixture := range fixtures {
+		f.Add(fixture)
+	}
+
+	return f
+}
+
+// LoadFixtures loads the fixtures of all JSON files in dir. Each
+// file contains a single Fixture or a list of fixtures.
+func LoadFixtures(dir string) (*Fixtures, error) {
+	f := NewFixtures()
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		contents, err := ioutil.ReadFile(path) // #nosec G304
+		if err != nil {
+			return err
+		}
+
+		fixtures := []*Fixture{}
+		contents = bytes.TrimSpace(contents)
+		if bytes.HasPrefix(contents, []byte("[")) {
+			err = json.Unmarshal(contents, &fixtures)
+		} else {
+			var fixture Fixture
+			err = json.Unmarshal(contents, &fixture)
+			fixtures = append(fixtures, &fixture)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: unable to parse fixture %s", err, path)
+		}
+
+		for _, fixture := range fixtures {
+			if len(fixture.Method) == 0 {
+				return fmt.Errorf("method missing in fixture %s", path)
+			}
+			f.Add(fixture)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Add adds or replaces a fixture.
+func (f *Fixtures) Add(fixture *Fixture) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := fixture.Method
+	if fixture.Params != nil {
+		key = FixtureKey(fixture.Method, fixture.Params)
+	}
+	f.calls[key] = fixture
+}
+
+// Call implements the Backend interface.
+func (f *Fixtures) Call(method string, params []json.RawMessage) (interface{}, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	fixture, ok := f.calls[FixtureKey(method, params)]
+	if !ok {
+		fixture, ok = f.calls[method]
+	}
+	if !ok {
+		return nil, &Error{
+			Code:    ErrCodeMethodNotFound,
+			Message: fmt.Sprintf("no fixture for %s", FixtureKey(method, params)),
+		}
+	}
+
+	if fixture.Error != nil {
+		return nil, fixture.Error
+	}
+
+	if len(fixture.Result) == 0 {
+		return nil, nil
+	}
+
+	return fixture.Result, nil
+}
+
+// FixtureKey returns the key matching the calls of method with
+// params. Params are compared by value, with hex strings compared
+// case-insensitively.
+func FixtureKey(method string, params []json.RawMessage) string {
+	if n, ok := matchedParams[method]; ok && len(params) > n {
+		params = params[:n]
+	}
+
+	canonical := make([]interface{}, len(params))
+	for i, param := range params {
+		decoder := json.NewDecoder(bytes.NewReader(param))
+		decoder.UseNumber()
+
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			canonical[i] = string(param)
+			continue
+		}
+		canonical[i] = canonicalValue(value)
+	}
+
+	encoded, _ := json.Marshal(canonical)
+	return method + string(encoded)
+}
+
+// canonicalValue lowercases the hex strings of value.
+func canonicalValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if strings.HasPrefix(v, "0x") || strings.HasPrefix(v, "0X") {
+			return strings.ToLower(v)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = canonicalValue(v[i])
+		}
+		return v
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = canonicalValue(v[key])
+		}
+		return v
+	}
+
+	return value
+}
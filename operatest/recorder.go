@@ -0,0 +1,257 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operatest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// recorderTimeout is the timeout of the
+	// requests forwarded by a Recorder.
+	recorderTimeout = 120 * time.Second
+
+	// testdataFilePerm is the permission of
+	// the files written by WriteTestdata.
+	testdataFilePerm = 0600
+)
+
+// Recorder is a proxy to a live node recording the JSON-RPC
+// calls it forwards as fixtures. GraphQL requests are
+// forwarded without being recorded.
+type Recorder struct {
+	url    string
+	client *http.Client
+
+	mu       sync.Mutex
+	fixtures []*Fixture
+}
+
+// NewRecorder returns a Recorder forwarding requests to the
+// node at url. The Recorder is served like the handler of
+// NewHandler, for example by an httptest.Server.
+func NewRecorder(url string) *Recorder {
+	return &Recorder{
+		url:    strings.TrimSuffix(url, "/"),
+		client: &http.Client{Timeout: recorderTimeout},
+	}
+}
+
+// Fixtures returns the calls recorded so far, in order.
+func (r *Recorder) Fixtures() []*Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fixtures := make([]*Fixture, len(r.fixtures))
+	copy(fixtures, r.fixtures)
+	return fixtures
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	forward, err := http.NewRequestWithContext(req.Context(), req.Method, r.url+req.URL.Path, bytes.NewReader(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	forward.Header.Set("Content-Type", req.Header.Get("Content-Type"))
+
+	resp, err := r.client.Do(forward)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	result, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	if req.URL.Path != graphQLPath && resp.StatusCode == http.StatusOK {
+		r.record(body, result)
+	}
+
+	w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+	w.WriteHeader(resp.StatusCode)
+	_, _ = w.Write(result)
+}
+
+// record adds the fixtures of the calls in a request body
+// and the matching response body.
+func (r *Recorder) record(body []byte, result []byte) {
+	requests := []*rpcRequest{}
+	responses := []*rpcResponse{}
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+		if json.Unmarshal(body, &requests) != nil || json.Unmarshal(result, &responses) != nil {
+			return
+		}
+	} else {
+		var request rpcRequest
+		var response rpcResponse
+		if json.Unmarshal(body, &request) != nil || json.Unmarshal(result, &response) != nil {
+			return
+		}
+		requests = append(requests, &request)
+		responses = append(responses, &response)
+	}
+
+	// Batch responses are not required to be in request order
+	byID := map[string]*rpcResponse{}
+	for _, response := range responses {
+		byID[string(response.ID)] = response
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, request := range requests {
+		response, ok := byID[string(request.ID)]
+		if !ok {
+			continue
+		}
+
+		params := request.Params
+		if params == nil {
+			params = []json.RawMessage{}
+		}
+		r.fixtures = append(r.fixtures, &Fixture{
+			Method: request.Method,
+			Params: params,
+			Result: response.Result,
+			Error:  response.Error,
+		})
+	}
+}
+
+// WriteFixtures writes fixtures to path as a list
+// loadable by LoadFixtures.
+func WriteFixtures(path string, fixtures []*Fixture) error {
+	contents, err := json.MarshalIndent(fixtures, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(contents, '\n'), testdataFilePerm)
+}
+
+// WriteTestdata writes the results of fixtures to dir using the
+// file names of the fantom package tests (block_<number>.json,
+// block_trace_<hash>.json, tx_receipt_<hash>.json...). Calls
+// without a file name, failed calls and null results are skipped.
+// The names of the written files are returned.
+func WriteTestdata(dir string, fixtures []*Fixture) ([]string, error) {
+	written := []string{}
+	seen := map[string]bool{}
+	for _, fixture := range fixtures {
+		if fixture.Error != nil || len(fixture.Result) == 0 || string(fixture.Result) == "null" {
+			continue
+		}
+
+		name, err := testdataName(fixture)
+		if err != nil {
+			return nil, err
+		}
+		if len(name) == 0 || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		var contents bytes.Buffer
+		if err := json.Indent(&contents, fixture.Result, "", "  "); err != nil {
+			return nil, fmt.Errorf("%w: unable to format result of %s", err, fixture.Method)
+		}
+		contents.WriteByte('\n')
+
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, contents.Bytes(), testdataFilePerm); err != nil {
+			return nil, err
+		}
+		written = append(written, path)
+	}
+
+	return written, nil
+}
+
+// testdataName returns the testdata file name of the result
+// of fixture or an empty string if it has none.
+func testdataName(fixture *Fixture) (string, error) {
+	var first string
+	if len(fixture.Params) > 0 {
+		// Only hashes and block numbers are used in names
+		_ = json.Unmarshal(fixture.Params[0], &first)
+	}
+	first = strings.ToLower(first)
+
+	switch fixture.Method {
+	case "eth_getBlockByNumber":
+		number, err := strconv.ParseUint(strings.TrimPrefix(first, "0x"), 16, 64)
+		if err != nil {
+			// Tags like "latest" are named after the returned block
+			var header struct {
+				Number string `json:"number"`
+			}
+			if err := json.Unmarshal(fixture.Result, &header); err != nil {
+				return "", err
+			}
+			number, err = strconv.ParseUint(strings.TrimPrefix(header.Number, "0x"), 16, 64)
+			if err != nil {
+				return "", fmt.Errorf("%w: invalid block number %s", err, header.Number)
+			}
+		}
+		return fmt.Sprintf("block_%d.json", number), nil
+	case "eth_getBlockByHash":
+		return fmt.Sprintf("block_%s.json", first), nil
+	case "debug_traceBlockByHash":
+		return fmt.Sprintf("block_trace_%s.json", first), nil
+	case "debug_traceTransaction":
+		return fmt.Sprintf("transaction_trace_%s.json", first), nil
+	case "eth_getTransactionByHash":
+		return fmt.Sprintf("transaction_%s.json", first), nil
+	case "eth_getTransactionReceipt":
+		return fmt.Sprintf("tx_receipt_%s.json", first), nil
+	case "eth_getUncleByBlockHashAndIndex":
+		var uncle struct {
+			Hash string `json:"hash"`
+		}
+		if err := json.Unmarshal(fixture.Result, &uncle); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("uncle_%s.json", strings.ToLower(uncle.Hash)), nil
+	}
+
+	return "", nil
+}
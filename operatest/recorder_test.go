@@ -0,0 +1,96 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operatest
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Fantom-foundation/rosetta-fantom/fantom"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+
+	chain := NewChain(testChainID, map[common.Address]*big.Int{
+		crypto.PubkeyToAddress(key.PublicKey): big.NewInt(1e18),
+	})
+	tx, err := chain.Transfer(key, common.Address{1}, big.NewInt(1000))
+	assert.NoError(t, err)
+	header := chain.Mine()
+
+	node := NewServer(chain)
+	defer node.Close()
+
+	recorder := NewRecorder(node.URL)
+	proxy := httptest.NewServer(recorder)
+	defer proxy.Close()
+
+	client, err := fantom.NewClient(proxy.URL, true)
+	assert.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	block, err := client.Block(ctx, &types.PartialBlockIdentifier{Index: types.Int64(1)})
+	assert.NoError(t, err)
+
+	fixtures := recorder.Fixtures()
+	methods := []string{}
+	for _, fixture := range fixtures {
+		methods = append(methods, fixture.Method)
+	}
+	// Traces and receipts are fetched concurrently
+	assert.ElementsMatch(t, []string{
+		"eth_getBlockByNumber",
+		"debug_traceBlockByHash",
+		"eth_getTransactionReceipt",
+	}, methods)
+
+	dir := t.TempDir()
+	written, err := WriteTestdata(dir, fixtures)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(dir, "block_1.json"),
+		filepath.Join(dir, "block_trace_"+strings.ToLower(header.Hash().Hex())+".json"),
+		filepath.Join(dir, "tx_receipt_"+strings.ToLower(tx.Hash().Hex())+".json"),
+	}, written)
+
+	// Replaying the fixtures returns the same block
+	path := filepath.Join(dir, "fixtures", "block_1.json")
+	assert.NoError(t, WriteFixtures(path, fixtures))
+	loaded, err := LoadFixtures(filepath.Dir(path))
+	assert.NoError(t, err)
+
+	replay := NewServer(loaded)
+	defer replay.Close()
+
+	replayClient, err := fantom.NewClient(replay.URL, true)
+	assert.NoError(t, err)
+	defer replayClient.Close()
+
+	replayed, err := replayClient.Block(ctx, &types.PartialBlockIdentifier{Index: types.Int64(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, types.Hash(block), types.Hash(replayed))
+}
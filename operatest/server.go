@@ -0,0 +1,307 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package operatest provides an in-process fake Opera node serving
+// the JSON-RPC and GraphQL endpoints used by rosetta-fantom. Its
+// responses come either from recorded fixtures (Fixtures) or from a
+// scripted in-memory chain (Chain).
+package operatest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// graphQLPath is the path of the GraphQL endpoint.
+	graphQLPath = "/graphql"
+
+	// maxRequestSize is the largest request body accepted.
+	maxRequestSize = 16 * 1024 * 1024
+
+	// ErrCodeDefault is the code of errors returned by
+	// backends that are not an *Error.
+	ErrCodeDefault = -32000
+
+	// ErrCodeMethodNotFound is the code of the error
+	// returned for unknown methods.
+	ErrCodeMethodNotFound = -32601
+
+	// ErrCodeInvalidParams is the code of the error
+	// returned for invalid parameters.
+	ErrCodeInvalidParams = -32602
+)
+
+// Backend answers the JSON-RPC requests of a fake node. The
+// returned result is marshaled to JSON, a nil result is
+// returned as null.
+type Backend interface {
+	Call(method string, params []json.RawMessage) (interface{}, error)
+}
+
+// Error is a JSON-RPC error.
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// MethodNotFound returns the error of an unknown method.
+func MethodNotFound(method string) *Error {
+	return &Error{
+		Code:    ErrCodeMethodNotFound,
+		Message: fmt.Sprintf("the method %s does not exist/is not available", method),
+	}
+}
+
+// InvalidParams returns the error of invalid parameters.
+func InvalidParams(err error) *Error {
+	return &Error{Code: ErrCodeInvalidParams, Message: err.Error()}
+}
+
+type rpcRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Server is a fake Opera node listening on a local port.
+// Clients use URL for JSON-RPC and URL + "/graphql"
+// for GraphQL, like a real node.
+type Server struct {
+	*httptest.Server
+}
+
+// NewServer starts a fake node answering with backend.
+// The caller should call Close when finished.
+func NewServer(backend Backend) *Server {
+	return &Server{Server: httptest.NewServer(NewHandler(backend))}
+}
+
+// NewHandler returns the HTTP handler of a fake node
+// answering with backend.
+func NewHandler(backend Backend) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(graphQLPath, func(w http.ResponseWriter, r *http.Request) {
+		serveGraphQL(backend, w, r)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		serveJSONRPC(backend, w, r)
+	})
+
+	return mux
+}
+
+func serveJSONRPC(backend Backend, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var response interface{}
+	body = bytes.TrimSpace(body)
+	if bytes.HasPrefix(body, []byte("[")) {
+		var requests []*rpcRequest
+		if err := json.Unmarshal(body, &requests); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		responses := make([]*rpcResponse, len(requests))
+		for i, request := range requests {
+			responses[i] = handleRequest(backend, request)
+		}
+		response = responses
+	} else {
+		var request rpcRequest
+		if err := json.Unmarshal(body, &request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		response = handleRequest(backend, &request)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func handleRequest(backend Backend, request *rpcRequest) *rpcResponse {
+	response := &rpcResponse{Version: "2.0", ID: request.ID}
+
+	result, err := backend.Call(request.Method, request.Params)
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: ErrCodeDefault, Message: err.Error()}
+		}
+		response.Error = rpcErr
+		return response
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		response.Error = &Error{Code: ErrCodeDefault, Message: err.Error()}
+		return response
+	}
+	response.Result = encoded
+
+	return response
+}
+
+var (
+	// graphQLBlockPattern matches the block selection of a query.
+	graphQLBlockPattern = regexp.MustCompile(`block\s*(\(\s*(number|hash)\s*:\s*"?([^")\s]+)"?\s*\))?`)
+
+	// graphQLAccountPattern matches the account selection of a query.
+	graphQLAccountPattern = regexp.MustCompile(`account\s*\(\s*address\s*:\s*"([^"]+)"\s*\)`)
+)
+
+// serveGraphQL answers the block and account queries of
+// rosetta-fantom by translating them into JSON-RPC calls.
+// Other queries are not supported.
+func serveGraphQL(backend Backend, w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Query string `json:"query"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := graphQLBlock(backend, request.Query)
+	response := map[string]interface{}{"data": data}
+	if err != nil {
+		response["errors"] = []map[string]interface{}{{"message": err.Error()}}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func graphQLBlock(backend Backend, query string) (map[string]interface{}, error) {
+	match := graphQLBlockPattern.FindStringSubmatch(query)
+	if match == nil {
+		return nil, errors.New("only block queries are supported")
+	}
+
+	var raw interface{}
+	var err error
+	switch match[2] {
+	case "hash":
+		raw, err = call(backend, "eth_getBlockByHash", match[3], false)
+	case "number":
+		number, parseErr := strconv.ParseUint(match[3], 10, 64)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		raw, err = call(backend, "eth_getBlockByNumber", fmt.Sprintf("0x%x", number), false)
+	default:
+		raw, err = call(backend, "eth_getBlockByNumber", "latest", false)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var header struct {
+		Hash   string `json:"hash"`
+		Number string `json:"number"`
+	}
+	if err := remarshal(raw, &header); err != nil || len(header.Hash) == 0 {
+		return map[string]interface{}{"block": nil}, nil
+	}
+
+	number, err := strconv.ParseUint(strings.TrimPrefix(header.Number, "0x"), 16, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	block := map[string]interface{}{
+		"hash":   header.Hash,
+		"number": number,
+	}
+
+	if account := graphQLAccountPattern.FindStringSubmatch(query); account != nil {
+		fields := map[string]string{
+			"balance":          "eth_getBalance",
+			"transactionCount": "eth_getTransactionCount",
+			"code":             "eth_getCode",
+		}
+
+		result := map[string]interface{}{}
+		for field, method := range fields {
+			value, err := call(backend, method, account[1], header.Number)
+			if err != nil {
+				return nil, err
+			}
+			result[field] = value
+		}
+		block["account"] = result
+	}
+
+	return map[string]interface{}{"block": block}, nil
+}
+
+// call calls backend with params encoded as JSON.
+func call(backend Backend, method string, params ...interface{}) (interface{}, error) {
+	encoded := make([]json.RawMessage, len(params))
+	for i, param := range params {
+		raw, err := json.Marshal(param)
+		if err != nil {
+			return nil, err
+		}
+		encoded[i] = raw
+	}
+
+	return backend.Call(method, encoded)
+}
+
+// remarshal decodes value, as returned by a Backend, into output.
+func remarshal(value interface{}, output interface{}) error {
+	raw, ok := value.(json.RawMessage)
+	if !ok {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		raw = encoded
+	}
+
+	return json.Unmarshal(raw, output)
+}
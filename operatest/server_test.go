@@ -0,0 +1,310 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operatest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Fantom-foundation/rosetta-fantom/configuration"
+	"github.com/Fantom-foundation/rosetta-fantom/fantom"
+	"github.com/Fantom-foundation/rosetta-fantom/services"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testChainID = big.NewInt(0xFA2)
+	testNetwork = &types.NetworkIdentifier{
+		Blockchain: fantom.Blockchain,
+		Network:    fantom.TestnetNetwork,
+	}
+)
+
+// TestMain runs the tests from the repository root, where
+// fantom.NewClient loads the call tracer like the binary.
+func TestMain(m *testing.M) {
+	if err := os.Chdir(".."); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+func post(t *testing.T, url string, request interface{}, response interface{}) int {
+	body, err := json.Marshal(request)
+	assert.NoError(t, err)
+
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body)) // #nosec G107
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(response))
+	return resp.StatusCode
+}
+
+func newTestRouter(t *testing.T, operaURL string) (*fantom.Client, *httptest.Server) {
+	client, err := fantom.NewClient(operaURL, false)
+	assert.NoError(t, err)
+
+	cfg := &configuration.Configuration{
+		Mode:                   configuration.Online,
+		Network:                testNetwork,
+		GenesisBlockIdentifier: &types.BlockIdentifier{Index: 0},
+		ChainID:                testChainID,
+	}
+
+	asserter, err := asserter.NewServer(
+		fantom.OperationTypes,
+		fantom.HistoricalBalanceSupported,
+		[]*types.NetworkIdentifier{cfg.Network},
+		fantom.CallMethods,
+		fantom.IncludeMempoolCoins,
+		"",
+	)
+	assert.NoError(t, err)
+
+	return client, httptest.NewServer(services.NewBlockchainRouter(cfg, client, asserter))
+}
+
+func TestChain_Router(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	to := common.HexToAddress("0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d")
+
+	chain := NewChain(testChainID, map[common.Address]*big.Int{
+		from: big.NewInt(1e18),
+	})
+	node := NewServer(chain)
+	defer node.Close()
+
+	client, router := newTestRouter(t, node.URL)
+	defer client.Close()
+	defer router.Close()
+
+	// Opera is not ready before the first block, but
+	// its sync status is reported
+	var rosettaErr types.Error
+	assert.Equal(t, http.StatusInternalServerError, post(t, router.URL+"/block", &types.BlockRequest{
+		NetworkIdentifier: testNetwork,
+		BlockIdentifier:   &types.PartialBlockIdentifier{Index: types.Int64(0)},
+	}, &rosettaErr))
+	assert.Equal(t, services.ErrOperaNotReady.Code, rosettaErr.Code)
+
+	var syncing types.NetworkStatusResponse
+	assert.Equal(t, http.StatusOK, post(t, router.URL+"/network/status", &types.NetworkRequest{
+		NetworkIdentifier: testNetwork,
+	}, &syncing))
+	assert.Equal(t, int64(0), syncing.CurrentBlockIdentifier.Index)
+	assert.Equal(t, types.Bool(false), syncing.SyncStatus.Synced)
+
+	tx, err := chain.Transfer(key, to, big.NewInt(1000))
+	assert.NoError(t, err)
+
+	mempool, err := client.GetMempool(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.TransactionIdentifier{{Hash: tx.Hash().Hex()}}, mempool.TransactionIdentifiers)
+
+	head := chain.Mine()
+
+	var status types.NetworkStatusResponse
+	assert.Equal(t, http.StatusOK, post(t, router.URL+"/network/status", &types.NetworkRequest{
+		NetworkIdentifier: testNetwork,
+	}, &status))
+	assert.Equal(t, &types.BlockIdentifier{Index: 1, Hash: head.Hash().Hex()}, status.CurrentBlockIdentifier)
+	assert.Nil(t, status.SyncStatus)
+
+	var block types.BlockResponse
+	assert.Equal(t, http.StatusOK, post(t, router.URL+"/block", &types.BlockRequest{
+		NetworkIdentifier: testNetwork,
+		BlockIdentifier:   &types.PartialBlockIdentifier{Index: types.Int64(1)},
+	}, &block))
+	assert.Equal(t, head.Hash().Hex(), block.Block.BlockIdentifier.Hash)
+	assert.Len(t, block.Block.Transactions, 1)
+
+	balances := map[string]*big.Int{}
+	for _, op := range block.Block.Transactions[0].Operations {
+		value, ok := new(big.Int).SetString(op.Amount.Value, 10)
+		assert.True(t, ok)
+		if _, ok := balances[op.Account.Address]; !ok {
+			balances[op.Account.Address] = big.NewInt(0)
+		}
+		balances[op.Account.Address].Add(balances[op.Account.Address], value)
+	}
+	fee := new(big.Int).Mul(big.NewInt(DefaultGasPrice), big.NewInt(21000))
+	assert.Equal(t, map[string]*big.Int{
+		from.Hex(): new(big.Int).Neg(new(big.Int).Add(fee, big.NewInt(1000))),
+		to.Hex():   big.NewInt(1000),
+	}, balances)
+
+	var balance types.AccountBalanceResponse
+	assert.Equal(t, http.StatusOK, post(t, router.URL+"/account/balance", &types.AccountBalanceRequest{
+		NetworkIdentifier: testNetwork,
+		AccountIdentifier: &types.AccountIdentifier{Address: to.Hex()},
+		BlockIdentifier:   &types.PartialBlockIdentifier{Index: types.Int64(1)},
+	}, &balance))
+	assert.Equal(t, "1000", balance.Balances[0].Value)
+
+	// Replace block 1 with a block including the same transfer
+	assert.NoError(t, chain.Reorg(1))
+	chain.Mine()
+	reorgHead := chain.Mine()
+	assert.NotEqual(t, head.Hash(), chain.Header(1).Hash())
+
+	assert.Equal(t, http.StatusInternalServerError, post(t, router.URL+"/block", &types.BlockRequest{
+		NetworkIdentifier: testNetwork,
+		BlockIdentifier:   &types.PartialBlockIdentifier{Hash: types.String(head.Hash().Hex())},
+	}, &rosettaErr))
+
+	chain.SetSyncing(&SyncStatus{CurrentBlock: 2, HighestBlock: 10})
+	assert.Equal(t, http.StatusOK, post(t, router.URL+"/network/status", &types.NetworkRequest{
+		NetworkIdentifier: testNetwork,
+	}, &status))
+	assert.Equal(t, reorgHead.Hash().Hex(), status.CurrentBlockIdentifier.Hash)
+	assert.Equal(t, &types.SyncStatus{
+		CurrentIndex: types.Int64(2),
+		TargetIndex:  types.Int64(10),
+	}, status.SyncStatus)
+}
+
+func TestChain_SendTransaction(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	chain := NewChain(testChainID, map[common.Address]*big.Int{
+		from: big.NewInt(1e18),
+	})
+	node := NewServer(chain)
+	defer node.Close()
+
+	client, err := fantom.NewClient(node.URL, true)
+	assert.NoError(t, err)
+	defer client.Close()
+
+	tx, err := chain.Transfer(key, common.Address{1}, big.NewInt(1))
+	assert.NoError(t, err)
+
+	// Resubmitting the same nonce is rejected
+	err = client.SendTransaction(context.Background(), tx)
+	assert.Contains(t, err.Error(), "invalid nonce")
+
+	_, err = chain.Transfer(key, common.Address{1}, big.NewInt(1e18))
+	assert.Contains(t, err.Error(), "insufficient funds")
+}
+
+func TestGraphQL(t *testing.T) {
+	address := common.HexToAddress("0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d")
+	chain := NewChain(testChainID, map[common.Address]*big.Int{
+		address: big.NewInt(42),
+	})
+	node := NewServer(chain)
+	defer node.Close()
+
+	var response struct {
+		Data struct {
+			Block struct {
+				Hash    string `json:"hash"`
+				Number  int64  `json:"number"`
+				Account struct {
+					Balance string `json:"balance"`
+					Nonce   string `json:"transactionCount"`
+				} `json:"account"`
+			} `json:"block"`
+		} `json:"data"`
+	}
+	post(t, node.URL+"/graphql", map[string]string{
+		"query": `{block(number: 0) {hash number account(address: "` + address.Hex() + `") ` +
+			`{balance transactionCount code}}}`,
+	}, &response)
+
+	assert.Equal(t, chain.Header(0).Hash().Hex(), response.Data.Block.Hash)
+	assert.Equal(t, int64(0), response.Data.Block.Number)
+	assert.Equal(t, "0x2a", response.Data.Block.Account.Balance)
+	assert.Equal(t, "0x0", response.Data.Block.Account.Nonce)
+}
+
+func TestFixtures(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "chain.json"), []byte(`[
+		{"method": "eth_chainId", "result": "0xfa2"},
+		{"method": "eth_getBalance", "params": ["0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d", "0x1"], "result": "0x2a"},
+		{"method": "debug_traceTransaction", "params": ["0xabcd", {"tracer": "old"}], "result": {"type": "CALL"}}
+	]`), os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "error.json"), []byte(`{
+		"method": "eth_getCode",
+		"error": {"code": -32000, "message": "header not found"}
+	}`), os.ModePerm))
+
+	fixtures, err := LoadFixtures(dir)
+	assert.NoError(t, err)
+
+	raw := func(values ...string) []json.RawMessage {
+		params := make([]json.RawMessage, len(values))
+		for i, value := range values {
+			params[i] = json.RawMessage(value)
+		}
+		return params
+	}
+
+	result, err := fixtures.Call("eth_chainId", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, json.RawMessage(`"0xfa2"`), result)
+
+	// Addresses match case-insensitively
+	result, err = fixtures.Call("eth_getBalance", raw(`"0x57b414a0332b5cab885a451c2a28a07d1e9b8a8d"`, `"0x1"`))
+	assert.NoError(t, err)
+	assert.Equal(t, json.RawMessage(`"0x2a"`), result)
+
+	_, err = fixtures.Call("eth_getBalance", raw(`"0x57b414a0332b5cab885a451c2a28a07d1e9b8a8d"`, `"0x2"`))
+	assert.Equal(t, ErrCodeMethodNotFound, err.(*Error).Code)
+
+	// The tracer configuration is ignored
+	result, err = fixtures.Call("debug_traceTransaction", raw(`"0xABCD"`, `{"tracer": "new"}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type": "CALL"}`, string(result.(json.RawMessage)))
+
+	_, err = fixtures.Call("eth_getCode", raw(`"0x00"`, `"latest"`))
+	assert.Equal(t, &Error{Code: -32000, Message: "header not found"}, err)
+
+	// Served over JSON-RPC
+	node := NewServer(fixtures)
+	defer node.Close()
+
+	client, err := fantom.NewClient(node.URL, true)
+	assert.NoError(t, err)
+	defer client.Close()
+
+	balance, err := client.Balance(
+		context.Background(),
+		&types.AccountIdentifier{Address: "0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d"},
+		&types.PartialBlockIdentifier{Index: types.Int64(1)},
+	)
+	assert.Nil(t, balance)
+	assert.Error(t, err)
+}
@@ -0,0 +1,274 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"container/list"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Fantom-foundation/rosetta-fantom/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/server"
+)
+
+const (
+	// maxRateLimitBuckets is the number of token buckets
+	// above which the least recently used bucket is evicted.
+	maxRateLimitBuckets = 10000
+)
+
+// expensiveEndpoints are the endpoints rate limited
+// with the expensive rate limit.
+var expensiveEndpoints = map[string]struct{}{
+	"/block": {},
+	"/call":  {},
+}
+
+type clientContextKey struct{}
+
+// APIClientFromContext returns the client authenticated by
+// AuthMiddleware, or nil if authentication is disabled.
+func APIClientFromContext(ctx context.Context) *configuration.APIClient {
+	client, _ := ctx.Value(clientContextKey{}).(*configuration.APIClient)
+	return client
+}
+
+// AuthMiddleware rejects the requests of clients that are not
+// listed in the configuration with ErrUnauthorized. Clients
+// are authenticated by their API key or by the common name of
+// their verified TLS client certificate. All requests are
+// accepted if no clients are configured.
+func AuthMiddleware(config *configuration.ServerConfiguration, next http.Handler) http.Handler {
+	if len(config.Clients) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := authenticate(config, r)
+		if client == nil {
+			server.EncodeJSONResponse(
+				wrapErr(ErrUnauthorized, errors.New("missing or invalid credentials")),
+				http.StatusUnauthorized,
+				w,
+			)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientContextKey{}, client)))
+	})
+}
+
+// authenticate returns the client sending r, if any.
+func authenticate(config *configuration.ServerConfiguration, r *http.Request) *configuration.APIClient {
+	if key := r.Header.Get(config.APIKeyHeader); len(key) > 0 {
+		for _, client := range config.Clients {
+			if len(client.Key) > 0 && subtle.ConstantTimeCompare([]byte(client.Key), []byte(key)) == 1 {
+				return client
+			}
+		}
+
+		return nil
+	}
+
+	// Only certificates verified during the handshake
+	// are in VerifiedChains.
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+		return nil
+	}
+
+	commonName := r.TLS.VerifiedChains[0][0].Subject.CommonName
+	for _, client := range config.Clients {
+		if len(client.CommonName) > 0 && client.CommonName == commonName {
+			return client
+		}
+	}
+
+	return nil
+}
+
+// tokenBucket is a token bucket refilled
+// continuously at rate tokens per second.
+type tokenBucket struct {
+	key    string
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// take takes a token from the bucket at now. If the bucket
+// is empty, it returns false with the duration until the
+// next token is available.
+func (b *tokenBucket) take(now time.Time) (bool, time.Duration) {
+	b.refill(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	return false, time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// rateLimiter holds the token buckets of clients. At most
+// maxRateLimitBuckets buckets are kept, the least recently
+// used bucket is evicted when a new one is created.
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*list.Element
+	recent  *list.List // of *tokenBucket, most recently used first
+	now     func() time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		buckets: map[string]*list.Element{},
+		recent:  list.New(),
+		now:     time.Now,
+	}
+}
+
+// take takes a token from the bucket identified by key,
+// created with limit if it does not exist.
+func (l *rateLimiter) take(key string, limit *configuration.RateLimit) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if element, ok := l.buckets[key]; ok {
+		l.recent.MoveToFront(element)
+		return element.Value.(*tokenBucket).take(now)
+	}
+
+	if len(l.buckets) >= maxRateLimitBuckets {
+		oldest := l.recent.Back()
+		l.recent.Remove(oldest)
+		delete(l.buckets, oldest.Value.(*tokenBucket).key)
+	}
+
+	bucket := &tokenBucket{
+		key:    key,
+		rate:   limit.Rate,
+		burst:  float64(limit.Burst),
+		tokens: float64(limit.Burst),
+		last:   now,
+	}
+	l.buckets[key] = l.recent.PushFront(bucket)
+
+	return bucket.take(now)
+}
+
+// RateLimitMiddleware rejects requests exceeding the rate limits
+// of their client with ErrRateLimited. The expensive endpoints
+// (/block and /call) and the other endpoints have separate budgets.
+// Clients are identified by AuthMiddleware or, when authentication
+// is disabled, by their IP address.
+func RateLimitMiddleware(config *configuration.ServerConfiguration, next http.Handler) http.Handler {
+	limiter := newRateLimiter()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := config.RateLimit
+		expensiveLimit := config.ExpensiveRateLimit
+
+		var key string
+		if client := APIClientFromContext(r.Context()); client != nil {
+			key = client.Name
+			if client.RateLimit != nil {
+				limit = client.RateLimit
+			}
+			if client.ExpensiveRateLimit != nil {
+				expensiveLimit = client.ExpensiveRateLimit
+			}
+		} else {
+			key = r.RemoteAddr
+			if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+				key = host
+			}
+		}
+
+		if _, ok := expensiveEndpoints[r.URL.Path]; ok {
+			key = "expensive:" + key
+			limit = expensiveLimit
+		}
+
+		if limit != nil {
+			if ok, wait := limiter.take(key, limit); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				server.EncodeJSONResponse(
+					wrapErr(ErrRateLimited, fmt.Errorf("retry in %s", wait.Round(time.Millisecond))),
+					http.StatusTooManyRequests,
+					w,
+				)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CorsMiddleware answers CORS preflight requests and allows
+// requests from the configured origins, or from all origins if
+// none are configured. The API key header is allowed.
+func CorsMiddleware(config *configuration.ServerConfiguration, next http.Handler) http.Handler {
+	allowed := map[string]struct{}{}
+	for _, origin := range config.CORSOrigins {
+		allowed[origin] = struct{}{}
+	}
+
+	headers := "Origin, X-Requested-With, Content-Type, Accept"
+	if len(config.Clients) > 0 {
+		headers += ", " + config.APIKeyHeader
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowOrigin := "*"
+		if len(allowed) > 0 {
+			w.Header().Add("Vary", "Origin")
+			allowOrigin = r.Header.Get("Origin")
+			if _, ok := allowed[allowOrigin]; !ok {
+				allowOrigin = ""
+			}
+		}
+
+		if len(allowOrigin) > 0 {
+			w.Header().Set("Access-Control-Allow-Origin", allowOrigin)
+			w.Header().Set("Access-Control-Allow-Headers", headers)
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST,OPTIONS")
+		}
+
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,280 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/rosetta-fantom/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testAPIClients = []*configuration.APIClient{
+		{Name: "explorer", Key: "explorer-key"},
+		{Name: "custody", CommonName: "custody.internal"},
+		{
+			Name:               "indexer",
+			Key:                "indexer-key",
+			ExpensiveRateLimit: &configuration.RateLimit{Rate: 0.001, Burst: 3},
+		},
+	}
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	var client *configuration.APIClient
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client = APIClientFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	serve := func(handler http.Handler, r *http.Request) *httptest.ResponseRecorder {
+		client = nil
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		handler := AuthMiddleware(&configuration.ServerConfiguration{}, next)
+		w := serve(handler, httptest.NewRequest(http.MethodPost, "/block", strings.NewReader("{}")))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Nil(t, client)
+	})
+
+	handler := AuthMiddleware(&configuration.ServerConfiguration{
+		Clients:      testAPIClients,
+		APIKeyHeader: configuration.DefaultAPIKeyHeader,
+	}, next)
+
+	t.Run("api key", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/block", strings.NewReader("{}"))
+		r.Header.Set(configuration.DefaultAPIKeyHeader, "indexer-key")
+		w := serve(handler, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, testAPIClients[2], client)
+	})
+
+	t.Run("client certificate", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/block", strings.NewReader("{}"))
+		r.TLS = &tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{
+				{{Subject: pkix.Name{CommonName: "custody.internal"}}},
+			},
+		}
+		w := serve(handler, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, testAPIClients[1], client)
+	})
+
+	unauthorized := map[string]func(r *http.Request){
+		"missing credentials": func(r *http.Request) {},
+		"invalid api key": func(r *http.Request) {
+			r.Header.Set(configuration.DefaultAPIKeyHeader, "custody.internal")
+		},
+		"unverified certificate": func(r *http.Request) {
+			r.TLS = &tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{
+					{Subject: pkix.Name{CommonName: "custody.internal"}},
+				},
+			}
+		},
+		"unknown certificate": func(r *http.Request) {
+			r.TLS = &tls.ConnectionState{
+				VerifiedChains: [][]*x509.Certificate{
+					{{Subject: pkix.Name{CommonName: "unknown.internal"}}},
+				},
+			}
+		},
+	}
+	for name, setup := range unauthorized {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/block", strings.NewReader("{}"))
+			setup(r)
+			w := serve(handler, r)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Nil(t, client)
+
+			var rosettaErr types.Error
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rosettaErr))
+			assert.Equal(t, ErrUnauthorized.Code, rosettaErr.Code)
+		})
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	config := &configuration.ServerConfiguration{
+		Clients:            testAPIClients,
+		APIKeyHeader:       configuration.DefaultAPIKeyHeader,
+		RateLimit:          &configuration.RateLimit{Rate: 0.001, Burst: 2},
+		ExpensiveRateLimit: &configuration.RateLimit{Rate: 0.001, Burst: 1},
+	}
+	handler := AuthMiddleware(config, RateLimitMiddleware(config, next))
+
+	serve := func(path string, key string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader("{}"))
+		r.Header.Set(configuration.DefaultAPIKeyHeader, key)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	// Cheap and expensive endpoints have separate budgets
+	assert.Equal(t, http.StatusOK, serve("/block", "explorer-key").Code)
+	assert.Equal(t, http.StatusOK, serve("/network/status", "explorer-key").Code)
+	assert.Equal(t, http.StatusOK, serve("/account/balance", "explorer-key").Code)
+
+	w := serve("/call", "explorer-key")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	var rosettaErr types.Error
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rosettaErr))
+	assert.Equal(t, ErrRateLimited.Code, rosettaErr.Code)
+	assert.True(t, rosettaErr.Retriable)
+
+	assert.Equal(t, http.StatusTooManyRequests, serve("/mempool", "explorer-key").Code)
+
+	// Clients have separate buckets and can override limits
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, serve("/block", "indexer-key").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, serve("/block", "indexer-key").Code)
+
+	t.Run("by ip address", func(t *testing.T) {
+		config := &configuration.ServerConfiguration{
+			RateLimit: &configuration.RateLimit{Rate: 0.001, Burst: 1},
+		}
+		handler := RateLimitMiddleware(config, next)
+
+		serve := func(remoteAddr string) int {
+			r := httptest.NewRequest(http.MethodPost, "/network/status", strings.NewReader("{}"))
+			r.RemoteAddr = remoteAddr
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			return w.Code
+		}
+
+		assert.Equal(t, http.StatusOK, serve("10.0.0.1:1234"))
+		assert.Equal(t, http.StatusTooManyRequests, serve("10.0.0.1:5678"))
+		assert.Equal(t, http.StatusOK, serve("10.0.0.2:1234"))
+
+		// Expensive endpoints are not limited
+		r := httptest.NewRequest(http.MethodPost, "/block", strings.NewReader("{}"))
+		r.RemoteAddr = "10.0.0.1:1234"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	bucket := &tokenBucket{rate: 2, burst: 2, tokens: 2, last: now}
+
+	for i := 0; i < 2; i++ {
+		ok, _ := bucket.take(now)
+		assert.True(t, ok)
+	}
+
+	ok, wait := bucket.take(now)
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// Tokens refill up to the burst
+	ok, _ = bucket.take(now.Add(500 * time.Millisecond))
+	assert.True(t, ok)
+	bucket.refill(now.Add(time.Hour))
+	assert.Equal(t, float64(2), bucket.tokens)
+}
+
+func TestRateLimiter_Evict(t *testing.T) {
+	limiter := newRateLimiter()
+	limit := &configuration.RateLimit{Rate: 1, Burst: 1}
+
+	// Buckets are evicted even if they are not refilled
+	for i := 0; i < maxRateLimitBuckets; i++ {
+		ok, _ := limiter.take(strconv.Itoa(i), limit)
+		assert.True(t, ok)
+	}
+
+	// The bucket of 0 is the most recently used
+	ok, _ := limiter.take("0", limit)
+	assert.False(t, ok)
+
+	ok, _ = limiter.take("new", limit)
+	assert.True(t, ok)
+	assert.Len(t, limiter.buckets, maxRateLimitBuckets)
+	assert.Equal(t, maxRateLimitBuckets, limiter.recent.Len())
+
+	_, evicted := limiter.buckets["1"]
+	assert.False(t, evicted)
+	_, kept := limiter.buckets["0"]
+	assert.True(t, kept)
+}
+
+func TestCorsMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	serve := func(handler http.Handler, method string, origin string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/network/list", strings.NewReader("{}"))
+		r.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("all origins", func(t *testing.T) {
+		handler := CorsMiddleware(&configuration.ServerConfiguration{}, next)
+		w := serve(handler, http.MethodOptions, "https://explorer.example.com")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	handler := CorsMiddleware(&configuration.ServerConfiguration{
+		Clients:      testAPIClients,
+		APIKeyHeader: configuration.DefaultAPIKeyHeader,
+		CORSOrigins:  []string{"https://explorer.example.com"},
+	}, next)
+
+	t.Run("allowed origin", func(t *testing.T) {
+		w := serve(handler, http.MethodOptions, "https://explorer.example.com")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "https://explorer.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), configuration.DefaultAPIKeyHeader)
+	})
+
+	t.Run("other origin", func(t *testing.T) {
+		w := serve(handler, http.MethodPost, "https://evil.example.com")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
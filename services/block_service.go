@@ -17,6 +17,7 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/Fantom-foundation/rosetta-fantom/configuration"
 	"github.com/Fantom-foundation/rosetta-fantom/fantom"
@@ -24,6 +25,24 @@ import (
 	"github.com/coinbase/rosetta-sdk-go/types"
 )
 
+const (
+	// IncludeZeroValueCallsMetadataKey is the request metadata
+	// key overriding configuration.IncludeZeroValueCallsEnv.
+	IncludeZeroValueCallsMetadataKey = "include_zero_value_calls"
+
+	// MetadataProfileMetadataKey is the request metadata
+	// key overriding configuration.MetadataProfileEnv.
+	MetadataProfileMetadataKey = "metadata_profile"
+
+	// IncludeWFTMOperationsMetadataKey is the request metadata
+	// key overriding configuration.IncludeWFTMOperationsEnv.
+	IncludeWFTMOperationsMetadataKey = "include_wftm_operations"
+
+	// IncludeNFTOperationsMetadataKey is the request metadata
+	// key overriding configuration.IncludeNFTOperationsEnv.
+	IncludeNFTOperationsMetadataKey = "include_nft_operations"
+)
+
 // BlockAPIService implements the server.BlockAPIServicer interface.
 type BlockAPIService struct {
 	config *configuration.Configuration
@@ -50,12 +69,14 @@ func (s *BlockAPIService) Block(
 		return nil, ErrUnavailableOffline
 	}
 
-	block, err := s.client.Block(ctx, request.BlockIdentifier)
-	if errors.Is(err, fantom.ErrBlockOrphaned) {
-		return nil, wrapErr(ErrBlockOrphaned, err)
+	ctx, rErr := s.withBlockOptions(ctx)
+	if rErr != nil {
+		return nil, rErr
 	}
+
+	block, err := s.client.Block(ctx, request.BlockIdentifier)
 	if err != nil {
-		return nil, wrapErr(ErrOpera, err)
+		return nil, blockErr(err)
 	}
 
 	return &types.BlockResponse{
@@ -72,12 +93,92 @@ func (s *BlockAPIService) BlockTransaction(
 		return nil, ErrUnavailableOffline
 	}
 
+	ctx, rErr := s.withBlockOptions(ctx)
+	if rErr != nil {
+		return nil, rErr
+	}
+
 	tx, err := s.client.Transaction(ctx, request.BlockIdentifier, request.TransactionIdentifier)
 	if err != nil {
-		return nil, wrapErr(ErrOpera, err)
+		return nil, blockErr(err)
 	}
 
 	return &types.BlockTransactionResponse{
 		Transaction: tx,
 	}, nil
 }
+
+// blockErr returns the types.Error of err, returned
+// by Opera when fetching a block or a transaction.
+func blockErr(err error) *types.Error {
+	switch {
+	case errors.Is(err, fantom.ErrBlockOrphaned):
+		return wrapErr(ErrBlockOrphaned, err)
+	case errors.Is(err, fantom.ErrFinalizedBlockMismatch):
+		return wrapErr(ErrFinalizedBlockMismatch, err)
+	default:
+		return wrapErr(ErrOpera, err)
+	}
+}
+
+// withBlockOptions returns ctx with the fantom.BlockOptions of the
+// request, which default to the configuration and are overridden by
+// the request metadata. ctx is returned as is for the default options.
+func (s *BlockAPIService) withBlockOptions(ctx context.Context) (context.Context, *types.Error) {
+	options := &fantom.BlockOptions{
+		IncludeZeroValueCalls: s.config.IncludeZeroValueCalls,
+		MetadataProfile:       s.config.MetadataProfile,
+		IncludeWFTMOperations: s.config.IncludeWFTMOperations,
+		IncludeNFTOperations:  s.config.IncludeNFTOperations,
+	}
+
+	metadata := requestMetadata(ctx)
+	if err := boolMetadata(metadata, IncludeZeroValueCallsMetadataKey, &options.IncludeZeroValueCalls); err != nil {
+		return nil, err
+	}
+	if err := boolMetadata(metadata, IncludeWFTMOperationsMetadataKey, &options.IncludeWFTMOperations); err != nil {
+		return nil, err
+	}
+	if err := boolMetadata(metadata, IncludeNFTOperationsMetadataKey, &options.IncludeNFTOperations); err != nil {
+		return nil, err
+	}
+
+	if value, ok := metadata[MetadataProfileMetadataKey]; ok {
+		name, ok := value.(string)
+		if !ok {
+			return nil, wrapErr(
+				ErrInvalidInput,
+				fmt.Errorf("%s must be a string", MetadataProfileMetadataKey),
+			)
+		}
+
+		profile, err := fantom.ParseMetadataProfile(name)
+		if err != nil {
+			return nil, wrapErr(ErrInvalidInput, err)
+		}
+		options.MetadataProfile = profile
+	}
+
+	if *options == (fantom.BlockOptions{}) {
+		return ctx, nil
+	}
+
+	return fantom.WithBlockOptions(ctx, options), nil
+}
+
+// boolMetadata sets value to the boolean of metadata
+// at key, if it is set.
+func boolMetadata(metadata map[string]interface{}, key string, value *bool) *types.Error {
+	raw, ok := metadata[key]
+	if !ok {
+		return nil
+	}
+
+	val, ok := raw.(bool)
+	if !ok {
+		return wrapErr(ErrInvalidInput, fmt.Errorf("%s must be a boolean", key))
+	}
+	*value = val
+
+	return nil
+}
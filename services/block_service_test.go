@@ -25,6 +25,7 @@ import (
 
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestBlockService_Offline(t *testing.T) {
@@ -104,6 +105,135 @@ func TestBlockService_Online(t *testing.T) {
 		assert.Equal(t, ErrBlockOrphaned.Retriable, err.Retriable)
 	})
 
+	t.Run("finalized block mismatch", func(t *testing.T) {
+		pbIdentifier := types.ConstructPartialBlockIdentifier(block.BlockIdentifier)
+		mockClient.On("Block", ctx, pbIdentifier).Return(nil, fantom.ErrFinalizedBlockMismatch).Once()
+		b, err := servicer.Block(ctx, &types.BlockRequest{
+			BlockIdentifier: pbIdentifier,
+		})
+
+		assert.Nil(t, b)
+		assert.Equal(t, ErrFinalizedBlockMismatch.Code, err.Code)
+		assert.Equal(t, ErrFinalizedBlockMismatch.Message, err.Message)
+		assert.False(t, err.Retriable)
+	})
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestBlockService_BlockOptions(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockClient := &mocks.Client{}
+	servicer := NewBlockAPIService(cfg, mockClient)
+
+	block := &types.Block{
+		BlockIdentifier: &types.BlockIdentifier{
+			Index: 100,
+			Hash:  "block 100",
+		},
+	}
+	includeZeroValueCalls := func(include bool) interface{} {
+		return mock.MatchedBy(func(ctx context.Context) bool {
+			return fantom.BlockOptionsFromContext(ctx).IncludeZeroValueCalls == include
+		})
+	}
+
+	t.Run("request metadata", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), requestMetadataContextKey{}, map[string]interface{}{
+			IncludeZeroValueCallsMetadataKey: true,
+		})
+		mockClient.On("Block", includeZeroValueCalls(true), (*types.PartialBlockIdentifier)(nil)).Return(block, nil).Once()
+		b, err := servicer.Block(ctx, &types.BlockRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, block, b.Block)
+	})
+
+	t.Run("configuration", func(t *testing.T) {
+		cfg.IncludeZeroValueCalls = true
+		defer func() { cfg.IncludeZeroValueCalls = false }()
+
+		mockClient.On("Block", includeZeroValueCalls(true), (*types.PartialBlockIdentifier)(nil)).Return(block, nil).Once()
+		b, err := servicer.Block(context.Background(), &types.BlockRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, block, b.Block)
+
+		// Request metadata overrides the configuration
+		ctx := context.WithValue(context.Background(), requestMetadataContextKey{}, map[string]interface{}{
+			IncludeZeroValueCallsMetadataKey: false,
+		})
+		mockClient.On("Transaction", includeZeroValueCalls(false), (*types.BlockIdentifier)(nil), (*types.TransactionIdentifier)(nil)).Return(&types.Transaction{}, nil).Once()
+		tx, err := servicer.BlockTransaction(ctx, &types.BlockTransactionRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, &types.Transaction{}, tx.Transaction)
+	})
+
+	t.Run("metadata profile", func(t *testing.T) {
+		cfg.MetadataProfile = fantom.MetadataProfileCompact
+		defer func() { cfg.MetadataProfile = "" }()
+
+		profile := func(profile fantom.MetadataProfile) interface{} {
+			return mock.MatchedBy(func(ctx context.Context) bool {
+				return fantom.BlockOptionsFromContext(ctx).MetadataProfile == profile
+			})
+		}
+
+		mockClient.On("Block", profile(fantom.MetadataProfileCompact), (*types.PartialBlockIdentifier)(nil)).Return(block, nil).Once()
+		b, err := servicer.Block(context.Background(), &types.BlockRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, block, b.Block)
+
+		ctx := context.WithValue(context.Background(), requestMetadataContextKey{}, map[string]interface{}{
+			MetadataProfileMetadataKey: "none",
+		})
+		mockClient.On("Block", profile(fantom.MetadataProfileNone), (*types.PartialBlockIdentifier)(nil)).Return(block, nil).Once()
+		b, err = servicer.Block(ctx, &types.BlockRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, block, b.Block)
+	})
+
+	t.Run("wftm operations", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), requestMetadataContextKey{}, map[string]interface{}{
+			IncludeWFTMOperationsMetadataKey: true,
+		})
+		mockClient.On("Block", mock.MatchedBy(func(ctx context.Context) bool {
+			return fantom.BlockOptionsFromContext(ctx).IncludeWFTMOperations
+		}), (*types.PartialBlockIdentifier)(nil)).Return(block, nil).Once()
+		b, err := servicer.Block(ctx, &types.BlockRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, block, b.Block)
+	})
+
+	t.Run("nft operations", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), requestMetadataContextKey{}, map[string]interface{}{
+			IncludeNFTOperationsMetadataKey: true,
+		})
+		mockClient.On("Block", mock.MatchedBy(func(ctx context.Context) bool {
+			return fantom.BlockOptionsFromContext(ctx).IncludeNFTOperations
+		}), (*types.PartialBlockIdentifier)(nil)).Return(block, nil).Once()
+		b, err := servicer.Block(ctx, &types.BlockRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, block, b.Block)
+	})
+
+	invalidMetadata := map[string]interface{}{
+		IncludeZeroValueCallsMetadataKey: "yes",
+		IncludeWFTMOperationsMetadataKey: 1,
+		IncludeNFTOperationsMetadataKey:  "no",
+		MetadataProfileMetadataKey:       "tiny",
+	}
+	for key, value := range invalidMetadata {
+		t.Run("invalid "+key, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), requestMetadataContextKey{}, map[string]interface{}{
+				key: value,
+			})
+			b, err := servicer.Block(ctx, &types.BlockRequest{})
+			assert.Nil(t, b)
+			assert.Equal(t, ErrInvalidInput.Code, err.Code)
+		})
+	}
+
 	mockClient.AssertExpectations(t)
 }
 
@@ -57,6 +57,9 @@ func (s *CallAPIService) Call(
 	if errors.Is(err, fantom.ErrCallMethodInvalid) {
 		return nil, wrapErr(ErrCallMethodInvalid, err)
 	}
+	if errors.Is(err, fantom.ErrTransactionNotTracked) {
+		return nil, wrapErr(ErrTransactionNotFound, err)
+	}
 	if err != nil {
 		return nil, wrapErr(ErrOpera, err)
 	}
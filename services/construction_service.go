@@ -17,14 +17,15 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
-	"strconv"
 
 	"github.com/Fantom-foundation/rosetta-fantom/configuration"
 	"github.com/Fantom-foundation/rosetta-fantom/fantom"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	ethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 
@@ -73,35 +74,23 @@ func (s *ConstructionAPIService) ConstructionPreprocess(
 	ctx context.Context,
 	request *types.ConstructionPreprocessRequest,
 ) (*types.ConstructionPreprocessResponse, *types.Error) {
-	descriptions := &parser.Descriptions{
-		OperationDescriptions: []*parser.OperationDescription{
-			{
-				Type: fantom.CallOpType,
-				Account: &parser.AccountDescription{
-					Exists: true,
-				},
-				Amount: &parser.AmountDescription{
-					Exists:   true,
-					Sign:     parser.NegativeAmountSign,
-					Currency: fantom.Currency,
-				},
-			},
-			{
-				Type: fantom.CallOpType,
-				Account: &parser.AccountDescription{
-					Exists: true,
-				},
-				Amount: &parser.AmountDescription{
-					Exists:   true,
-					Sign:     parser.PositiveAmountSign,
-					Currency: fantom.Currency,
-				},
-			},
-		},
-		ErrUnmatched: true,
+	if isNFTTransfer(request.Operations) {
+		call, err := parseNFTTransfer(request.Operations)
+		if err != nil {
+			return nil, err
+		}
+
+		return preprocessResponse(&options{
+			From: call.From,
+			To:   call.Contract,
+			Data: hexutil.Encode(call.Data),
+		})
 	}
 
-	matches, err := parser.MatchOperations(descriptions, request.Operations)
+	matches, err := parser.MatchOperations(
+		transferDescriptions(fantom.CallOpType, fantom.Currency),
+		request.Operations,
+	)
 	if err != nil {
 		return nil, wrapErr(ErrUnclearIntent, err)
 	}
@@ -123,10 +112,14 @@ func (s *ConstructionAPIService) ConstructionPreprocess(
 		return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", toAdd))
 	}
 
-	preprocessOutput := &options{
+	return preprocessResponse(&options{
 		From: checkFrom,
-	}
+	})
+}
 
+// preprocessResponse returns the /construction/preprocess
+// response with preprocessOutput as options.
+func preprocessResponse(preprocessOutput *options) (*types.ConstructionPreprocessResponse, *types.Error) {
 	marshaled, err := marshalJSONMap(preprocessOutput)
 	if err != nil {
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
@@ -165,62 +158,75 @@ func (s *ConstructionAPIService) ConstructionMetadata(
 		GasPrice: gasPrice,
 	}
 
+	// Estimate the gas limit of contract calls
+	gasLimit := uint64(fantom.TransferGasLimit)
+	if len(input.Data) > 0 {
+		estimate, err := s.estimateGas(ctx, &input, gasPrice)
+		if err != nil {
+			return nil, wrapErr(ErrOpera, err)
+		}
+
+		metadata.GasLimit = estimate
+		gasLimit = estimate
+	}
+
 	metadataMap, err := marshalJSONMap(metadata)
 	if err != nil {
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 	}
 
 	// Find suggested gas usage
-	suggestedFee := metadata.GasPrice.Int64() * fantom.TransferGasLimit
+	suggestedFee := new(big.Int).Mul(metadata.GasPrice, new(big.Int).SetUint64(gasLimit))
 
 	return &types.ConstructionMetadataResponse{
 		Metadata: metadataMap,
 		SuggestedFee: []*types.Amount{
 			{
-				Value:    strconv.FormatInt(suggestedFee, 10),
+				Value:    suggestedFee.String(),
 				Currency: fantom.Currency,
 			},
 		},
 	}, nil
 }
 
+// estimateGas returns the gas estimated by Opera for the
+// contract call of input, sent with its value at gasPrice.
+func (s *ConstructionAPIService) estimateGas(
+	ctx context.Context,
+	input *options,
+	gasPrice *big.Int,
+) (uint64, error) {
+	params := map[string]interface{}{
+		"from":      input.From,
+		"to":        input.To,
+		"data":      input.Data,
+		"gas_price": hexutil.EncodeBig(gasPrice),
+	}
+	if len(input.Value) > 0 {
+		params["value"] = input.Value
+	}
+
+	resp, err := s.client.Call(ctx, &types.CallRequest{
+		Method:     "eth_estimateGas",
+		Parameters: params,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	estimate, ok := resp.Result["data"].(string)
+	if !ok {
+		return 0, fmt.Errorf("unexpected gas estimate %v", resp.Result["data"])
+	}
+
+	return hexutil.DecodeUint64(estimate)
+}
+
 // ConstructionPayloads implements the /construction/payloads endpoint.
 func (s *ConstructionAPIService) ConstructionPayloads(
 	ctx context.Context,
 	request *types.ConstructionPayloadsRequest,
 ) (*types.ConstructionPayloadsResponse, *types.Error) {
-	descriptions := &parser.Descriptions{
-		OperationDescriptions: []*parser.OperationDescription{
-			{
-				Type: fantom.CallOpType,
-				Account: &parser.AccountDescription{
-					Exists: true,
-				},
-				Amount: &parser.AmountDescription{
-					Exists:   true,
-					Sign:     parser.NegativeAmountSign,
-					Currency: fantom.Currency,
-				},
-			},
-			{
-				Type: fantom.CallOpType,
-				Account: &parser.AccountDescription{
-					Exists: true,
-				},
-				Amount: &parser.AmountDescription{
-					Exists:   true,
-					Sign:     parser.PositiveAmountSign,
-					Currency: fantom.Currency,
-				},
-			},
-		},
-		ErrUnmatched: true,
-	}
-	matches, err := parser.MatchOperations(descriptions, request.Operations)
-	if err != nil {
-		return nil, wrapErr(ErrUnclearIntent, err)
-	}
-
 	// Convert map to Metadata struct
 	var metadata metadata
 	if err := unmarshalJSONMap(request.Metadata, &metadata); err != nil {
@@ -228,28 +234,62 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 	}
 
 	// Required Fields for constructing a real Opera transaction
-	toOp, amount := matches[1].First()
-	toAdd := toOp.Account.Address
+	var checkFrom, checkTo string
+	var amount *big.Int
 	nonce := metadata.Nonce
 	gasPrice := metadata.GasPrice
 	chainID := s.config.ChainID
 	transferGasLimit := uint64(fantom.TransferGasLimit)
 	transferData := []byte{}
 
-	// Additional Fields for constructing custom Opera tx struct
-	fromOp, _ := matches[0].First()
-	fromAdd := fromOp.Account.Address
+	if isNFTTransfer(request.Operations) {
+		call, err := parseNFTTransfer(request.Operations)
+		if err != nil {
+			return nil, err
+		}
 
-	// Ensure valid from address
-	checkFrom, ok := fantom.ChecksumAddress(fromAdd)
-	if !ok {
-		return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", fromAdd))
-	}
+		if metadata.GasLimit == 0 {
+			return nil, wrapErr(
+				ErrUnableToParseIntermediateResult,
+				errors.New("gas_limit is missing from metadata"),
+			)
+		}
 
-	// Ensure valid to address
-	checkTo, ok := fantom.ChecksumAddress(toAdd)
-	if !ok {
-		return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", toAdd))
+		// NFTs are transferred by calling their contract
+		checkFrom = call.From
+		checkTo = call.Contract
+		amount = new(big.Int)
+		transferGasLimit = metadata.GasLimit
+		transferData = call.Data
+	} else {
+		matches, err := parser.MatchOperations(
+			transferDescriptions(fantom.CallOpType, fantom.Currency),
+			request.Operations,
+		)
+		if err != nil {
+			return nil, wrapErr(ErrUnclearIntent, err)
+		}
+
+		toOp, toAmount := matches[1].First()
+		toAdd := toOp.Account.Address
+		amount = toAmount
+
+		// Additional Fields for constructing custom Opera tx struct
+		fromOp, _ := matches[0].First()
+		fromAdd := fromOp.Account.Address
+
+		// Ensure valid from address
+		var ok bool
+		checkFrom, ok = fantom.ChecksumAddress(fromAdd)
+		if !ok {
+			return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", fromAdd))
+		}
+
+		// Ensure valid to address
+		checkTo, ok = fantom.ChecksumAddress(toAdd)
+		if !ok {
+			return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", toAdd))
+		}
 	}
 
 	tx := ethTypes.NewTransaction(
@@ -392,38 +432,22 @@ func (s *ConstructionAPIService) ConstructionParse(
 		return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", tx.To))
 	}
 
-	ops := []*types.Operation{
-		{
-			Type: fantom.CallOpType,
-			OperationIdentifier: &types.OperationIdentifier{
-				Index: 0,
-			},
-			Account: &types.AccountIdentifier{
-				Address: checkFrom,
-			},
-			Amount: &types.Amount{
-				Value:    new(big.Int).Neg(tx.Value).String(),
-				Currency: fantom.Currency,
-			},
-		},
-		{
-			Type: fantom.CallOpType,
-			OperationIdentifier: &types.OperationIdentifier{
-				Index: 1,
-			},
-			RelatedOperations: []*types.OperationIdentifier{
-				{
-					Index: 0,
-				},
-			},
-			Account: &types.AccountIdentifier{
-				Address: checkTo,
-			},
-			Amount: &types.Amount{
-				Value:    tx.Value.String(),
-				Currency: fantom.Currency,
-			},
-		},
+	ops := transferOps(fantom.CallOpType, checkFrom, checkTo, tx.Value, fantom.Currency)
+
+	// Contract calls transferring NFTs are parsed into NFT transfers
+	if transfer, err := fantom.ParseSafeTransferFrom(tx.Data); err == nil && tx.Value.Sign() == 0 {
+		opType := fantom.ERC721TransferOpType
+		if transfer.Standard == fantom.ERC1155Standard {
+			opType = fantom.ERC1155TransferOpType
+		}
+
+		ops = transferOps(
+			opType,
+			fantom.MustChecksum(transfer.From.Hex()),
+			fantom.MustChecksum(transfer.To.Hex()),
+			transfer.Amount,
+			fantom.NFTCurrency(transfer.Standard, common.HexToAddress(checkTo), transfer.TokenID),
+		)
 	}
 
 	metadata := &parseMetadata{
@@ -482,3 +506,153 @@ func (s *ConstructionAPIService) ConstructionSubmit(
 		TransactionIdentifier: txIdentifier,
 	}, nil
 }
+
+// transferDescriptions returns the descriptions of the debit and
+// credit operations of opType of a transfer. Any currency matches
+// when currency is nil.
+func transferDescriptions(opType string, currency *types.Currency) *parser.Descriptions {
+	return &parser.Descriptions{
+		OperationDescriptions: []*parser.OperationDescription{
+			{
+				Type: opType,
+				Account: &parser.AccountDescription{
+					Exists: true,
+				},
+				Amount: &parser.AmountDescription{
+					Exists:   true,
+					Sign:     parser.NegativeAmountSign,
+					Currency: currency,
+				},
+			},
+			{
+				Type: opType,
+				Account: &parser.AccountDescription{
+					Exists: true,
+				},
+				Amount: &parser.AmountDescription{
+					Exists:   true,
+					Sign:     parser.PositiveAmountSign,
+					Currency: currency,
+				},
+			},
+		},
+		ErrUnmatched: true,
+	}
+}
+
+// transferOps returns the debit and credit operations
+// of opType transferring value currency from from to to.
+func transferOps(
+	opType string,
+	from string,
+	to string,
+	value *big.Int,
+	currency *types.Currency,
+) []*types.Operation {
+	return []*types.Operation{
+		{
+			Type: opType,
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
+			},
+			Account: &types.AccountIdentifier{
+				Address: from,
+			},
+			Amount: &types.Amount{
+				Value:    new(big.Int).Neg(value).String(),
+				Currency: currency,
+			},
+		},
+		{
+			Type: opType,
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 1,
+			},
+			RelatedOperations: []*types.OperationIdentifier{
+				{
+					Index: 0,
+				},
+			},
+			Account: &types.AccountIdentifier{
+				Address: to,
+			},
+			Amount: &types.Amount{
+				Value:    value.String(),
+				Currency: currency,
+			},
+		},
+	}
+}
+
+// nftCall is the contract call transferring
+// the NFTs of construction operations.
+type nftCall struct {
+	From     string
+	Contract string
+	Data     []byte
+}
+
+// isNFTTransfer returns a boolean indicating if
+// operations transfer ERC-721 or ERC-1155 tokens.
+func isNFTTransfer(operations []*types.Operation) bool {
+	if len(operations) == 0 {
+		return false
+	}
+
+	_, ok := fantom.NFTStandard(operations[0].Type)
+	return ok
+}
+
+// parseNFTTransfer returns the safeTransferFrom call of the
+// NFT contract transferring the NFTs of operations.
+func parseNFTTransfer(operations []*types.Operation) (*nftCall, *types.Error) {
+	opType := operations[0].Type
+	standard, _ := fantom.NFTStandard(opType)
+	matches, err := parser.MatchOperations(transferDescriptions(opType, nil), operations)
+	if err != nil {
+		return nil, wrapErr(ErrUnclearIntent, err)
+	}
+
+	fromOp, fromAmount := matches[0].First()
+	toOp, toAmount := matches[1].First()
+	if types.Hash(fromOp.Amount.Currency) != types.Hash(toOp.Amount.Currency) {
+		return nil, wrapErr(ErrUnclearIntent, errors.New("currencies of operations must match"))
+	}
+	if new(big.Int).Neg(fromAmount).Cmp(toAmount) != 0 {
+		return nil, wrapErr(ErrUnclearIntent, errors.New("amounts of operations must balance"))
+	}
+
+	contract, tokenID, err := fantom.ParseNFTCurrency(standard, toOp.Amount.Currency)
+	if err != nil {
+		return nil, wrapErr(ErrUnclearIntent, err)
+	}
+
+	// Ensure valid from address
+	checkFrom, ok := fantom.ChecksumAddress(fromOp.Account.Address)
+	if !ok {
+		return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", fromOp.Account.Address))
+	}
+
+	// Ensure valid to address
+	checkTo, ok := fantom.ChecksumAddress(toOp.Account.Address)
+	if !ok {
+		return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", toOp.Account.Address))
+	}
+
+	data, err := fantom.SafeTransferFromData(
+		standard,
+		common.HexToAddress(checkFrom),
+		common.HexToAddress(checkTo),
+		tokenID,
+		toAmount,
+	)
+	if err != nil {
+		return nil, wrapErr(ErrUnclearIntent, err)
+	}
+
+	return &nftCall{
+		From:     checkFrom,
+		Contract: fantom.MustChecksum(contract.Hex()),
+		Data:     data,
+	}, nil
+}
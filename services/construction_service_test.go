@@ -27,6 +27,7 @@ import (
 
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -239,3 +240,188 @@ func TestConstructionService(t *testing.T) {
 
 	mockClient.AssertExpectations(t)
 }
+
+func TestConstructionService_NFTTransfer(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Online,
+		Network: networkIdentifier,
+		ChainID: big.NewInt(0xFA2),
+	}
+
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	from := "0x881d953652933937186BDf0680eD3c3c8a0162Ab"
+	to := "0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d"
+	contract := common.HexToAddress("0x04068DA6C83AFCFA0e13ba15A6696662335D5B75")
+	currency := fantom.NFTCurrency(fantom.ERC1155Standard, contract, big.NewInt(7))
+	ops := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 0},
+			Type:                fantom.ERC1155TransferOpType,
+			Account:             &types.AccountIdentifier{Address: from},
+			Amount:              &types.Amount{Value: "-5", Currency: currency},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 1},
+			RelatedOperations:   []*types.OperationIdentifier{{Index: 0}},
+			Type:                fantom.ERC1155TransferOpType,
+			Account:             &types.AccountIdentifier{Address: to},
+			Amount:              &types.Amount{Value: "5", Currency: currency},
+		},
+	}
+	data, err := fantom.SafeTransferFromData(
+		fantom.ERC1155Standard,
+		common.HexToAddress(from),
+		common.HexToAddress(to),
+		big.NewInt(7),
+		big.NewInt(5),
+	)
+	assert.NoError(t, err)
+
+	// Test Preprocess
+	preprocessResponse, rosettaErr := servicer.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+	})
+	assert.Nil(t, rosettaErr)
+	nftOptions := &options{
+		From: from,
+		To:   contract.Hex(),
+		Data: hexutil.Encode(data),
+	}
+	assert.Equal(t, forceMarshalMap(t, nftOptions), preprocessResponse.Options)
+
+	// Test Metadata
+	mockClient.On("SuggestGasPrice", ctx).Return(big.NewInt(1000000000), nil).Once()
+	mockClient.On("PendingNonceAt", ctx, common.HexToAddress(from)).Return(uint64(3), nil).Once()
+	mockClient.On("Call", ctx, &types.CallRequest{
+		Method: "eth_estimateGas",
+		Parameters: map[string]interface{}{
+			"from":      from,
+			"to":        contract.Hex(),
+			"data":      hexutil.Encode(data),
+			"gas_price": "0x3b9aca00",
+		},
+	}).Return(&types.CallResponse{
+		Result: map[string]interface{}{"data": "0xc350"},
+	}, nil).Once()
+	metadataResponse, rosettaErr := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           preprocessResponse.Options,
+	})
+	assert.Nil(t, rosettaErr)
+	nftMetadata := &metadata{
+		Nonce:    3,
+		GasPrice: big.NewInt(1000000000),
+		GasLimit: 50000,
+	}
+	assert.Equal(t, &types.ConstructionMetadataResponse{
+		Metadata: forceMarshalMap(t, nftMetadata),
+		SuggestedFee: []*types.Amount{
+			{
+				Value:    "50000000000000",
+				Currency: fantom.Currency,
+			},
+		},
+	}, metadataResponse)
+
+	// Test Payloads
+	payloadsResponse, rosettaErr := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          metadataResponse.Metadata,
+	})
+	assert.Nil(t, rosettaErr)
+	var unsignedTx transaction
+	assert.NoError(t, json.Unmarshal([]byte(payloadsResponse.UnsignedTransaction), &unsignedTx))
+	assert.Zero(t, unsignedTx.Value.Sign())
+	assert.Equal(t, transaction{
+		From:     from,
+		To:       contract.Hex(),
+		Value:    unsignedTx.Value,
+		Data:     data,
+		Nonce:    3,
+		GasPrice: big.NewInt(1000000000),
+		GasLimit: 50000,
+		ChainID:  big.NewInt(0xFA2),
+	}, unsignedTx)
+
+	// The gas limit must be estimated
+	_, rosettaErr = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          forceMarshalMap(t, &metadata{Nonce: 3, GasPrice: big.NewInt(1000000000)}),
+	})
+	assert.Equal(t, ErrUnableToParseIntermediateResult.Code, rosettaErr.Code)
+
+	// Test Parse Unsigned
+	parseResponse, rosettaErr := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, rosettaErr)
+	assert.Equal(t, ops, parseResponse.Operations)
+
+	// ERC-721 tokens are unique
+	ops[0].Type = fantom.ERC721TransferOpType
+	ops[1].Type = fantom.ERC721TransferOpType
+	_, rosettaErr = servicer.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+	})
+	assert.Equal(t, ErrUnclearIntent.Code, rosettaErr.Code)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestConstructionMetadata_PayableCall(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Online,
+		Network: networkIdentifier,
+		ChainID: big.NewInt(0xFA2),
+	}
+
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	from := "0x881d953652933937186BDf0680eD3c3c8a0162Ab"
+	contract := "0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83"
+	callOptions := &options{
+		From:  from,
+		To:    contract,
+		Data:  "0xd0e30db0",
+		Value: "0xde0b6b3a7640000",
+	}
+
+	// The value is sent with the estimated call
+	mockClient.On("SuggestGasPrice", ctx).Return(big.NewInt(1000000000), nil).Once()
+	mockClient.On("PendingNonceAt", ctx, common.HexToAddress(from)).Return(uint64(3), nil).Once()
+	mockClient.On("Call", ctx, &types.CallRequest{
+		Method: "eth_estimateGas",
+		Parameters: map[string]interface{}{
+			"from":      from,
+			"to":        contract,
+			"data":      "0xd0e30db0",
+			"gas_price": "0x3b9aca00",
+			"value":     "0xde0b6b3a7640000",
+		},
+	}).Return(&types.CallResponse{
+		Result: map[string]interface{}{"data": "0xafc8"},
+	}, nil).Once()
+	metadataResponse, rosettaErr := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           forceMarshalMap(t, callOptions),
+	})
+	assert.Nil(t, rosettaErr)
+	assert.Equal(t, forceMarshalMap(t, &metadata{
+		Nonce:    3,
+		GasPrice: big.NewInt(1000000000),
+		GasLimit: 45000,
+	}), metadataResponse.Metadata)
+
+	mockClient.AssertExpectations(t)
+}
@@ -38,6 +38,10 @@ var (
 		ErrInvalidAddress,
 		ErrOperaNotReady,
 		ErrInvalidInput,
+		ErrTransactionNotFound,
+		ErrUnauthorized,
+		ErrRateLimited,
+		ErrFinalizedBlockMismatch,
 	}
 
 	// ErrUnimplemented is returned when an endpoint
@@ -151,6 +155,37 @@ var (
 		Code:    14, //nolint
 		Message: "invalid input",
 	}
+
+	// ErrTransactionNotFound is returned when a transaction
+	// is neither in the mempool nor tracked after submission.
+	ErrTransactionNotFound = &types.Error{
+		Code:    15, //nolint
+		Message: "Transaction not found",
+	}
+
+	// ErrUnauthorized is returned when a request
+	// is not sent by a configured API client.
+	ErrUnauthorized = &types.Error{
+		Code:    16, //nolint
+		Message: "Unauthorized",
+	}
+
+	// ErrRateLimited is returned when an API client
+	// exceeds its rate limit.
+	ErrRateLimited = &types.Error{
+		Code:      17, //nolint
+		Message:   "Rate limit exceeded",
+		Retriable: true,
+	}
+
+	// ErrFinalizedBlockMismatch is returned when the receipts
+	// of a block at or below the finalized head do not match
+	// the block. Opera blocks are final, so retrying does not
+	// resolve the mismatch.
+	ErrFinalizedBlockMismatch = &types.Error{
+		Code:    18, //nolint
+		Message: "Finalized block mismatch",
+	}
 )
 
 // wrapErr adds details to the types.Error provided. We use a function
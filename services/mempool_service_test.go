@@ -16,9 +16,11 @@ package services
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/Fantom-foundation/rosetta-fantom/configuration"
+	"github.com/Fantom-foundation/rosetta-fantom/fantom"
 	mocks "github.com/Fantom-foundation/rosetta-fantom/mocks/services"
 	"github.com/coinbase/rosetta-sdk-go/types"
 
@@ -40,8 +42,8 @@ func TestMempoolService_Offline(t *testing.T) {
 
 	memTransaction, err := servicer.MempoolTransaction(ctx, nil)
 	assert.Nil(t, memTransaction)
-	assert.Equal(t, ErrUnimplemented.Code, err.Code)
-	assert.Equal(t, ErrUnimplemented.Message, err.Message)
+	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
+	assert.Equal(t, ErrUnavailableOffline.Message, err.Message)
 
 	mockClient.AssertExpectations(t)
 }
@@ -74,5 +76,61 @@ func TestMempoolService_Online(t *testing.T) {
 		assert.Equal(t, mempool, actualMempool)
 	})
 
+	transactionIdentifier := mempool.TransactionIdentifiers[0]
+	transaction := &types.Transaction{
+		TransactionIdentifier: transactionIdentifier,
+		Metadata: map[string]interface{}{
+			"tracking": map[string]interface{}{
+				"status": fantom.TxStatusPending,
+			},
+		},
+	}
+
+	t.Run("mempool transaction", func(t *testing.T) {
+		mockClient.
+			On("MempoolTransaction", ctx, transactionIdentifier).
+			Return(transaction, nil).
+			Once()
+
+		actualTransaction, err := servicer.MempoolTransaction(
+			ctx,
+			&types.MempoolTransactionRequest{TransactionIdentifier: transactionIdentifier},
+		)
+
+		assert.Nil(t, err)
+		assert.Equal(t, &types.MempoolTransactionResponse{Transaction: transaction}, actualTransaction)
+	})
+
+	t.Run("mempool transaction not pending", func(t *testing.T) {
+		mockClient.
+			On("MempoolTransaction", ctx, transactionIdentifier).
+			Return(nil, fantom.ErrTransactionNotPending).
+			Once()
+
+		actualTransaction, err := servicer.MempoolTransaction(
+			ctx,
+			&types.MempoolTransactionRequest{TransactionIdentifier: transactionIdentifier},
+		)
+
+		assert.Nil(t, actualTransaction)
+		assert.Equal(t, ErrTransactionNotFound.Code, err.Code)
+		assert.Equal(t, ErrTransactionNotFound.Message, err.Message)
+	})
+
+	t.Run("mempool transaction opera error", func(t *testing.T) {
+		mockClient.
+			On("MempoolTransaction", ctx, transactionIdentifier).
+			Return(nil, errors.New("some err")).
+			Once()
+
+		actualTransaction, err := servicer.MempoolTransaction(
+			ctx,
+			&types.MempoolTransactionRequest{TransactionIdentifier: transactionIdentifier},
+		)
+
+		assert.Nil(t, actualTransaction)
+		assert.Equal(t, ErrOpera.Code, err.Code)
+	})
+
 	mockClient.AssertExpectations(t)
 }
@@ -60,6 +60,7 @@ func (s *NetworkAPIService) NetworkOptions(
 			NodeVersion:       fantom.NodeVersion,
 			RosettaVersion:    types.RosettaAPIVersion,
 			MiddlewareVersion: types.String(configuration.MiddlewareVersion),
+			Metadata:          fantom.FinalityMetadata,
 		},
 		Allow: &types.Allow{
 			Errors:                  Errors,
@@ -89,6 +90,14 @@ func (s *NetworkAPIService) NetworkStatus(
 		return nil, ErrOperaNotReady
 	}
 
+	// Opera is not synced until it has imported blocks
+	if currentBlock.Index == 0 {
+		if syncStatus == nil {
+			syncStatus = &types.SyncStatus{CurrentIndex: types.Int64(0)}
+		}
+		syncStatus.Synced = types.Bool(false)
+	}
+
 	return &types.NetworkStatusResponse{
 		CurrentBlockIdentifier: currentBlock,
 		CurrentBlockTimestamp:  currentTime,
@@ -31,8 +31,9 @@ var (
 	defaultNetworkOptions = &types.NetworkOptionsResponse{
 		Version: &types.Version{
 			RosettaVersion:    types.RosettaAPIVersion,
-			NodeVersion:       "1.1.0-rc.4",
+			NodeVersion:       "1.1.0-rc.5",
 			MiddlewareVersion: &middlewareVersion,
+			Metadata:          fantom.FinalityMetadata,
 		},
 		Allow: &types.Allow{
 			OperationStatuses:       fantom.OperationStatuses,
@@ -135,3 +136,39 @@ func TestNetworkEndpoints_Online(t *testing.T) {
 
 	mockClient.AssertExpectations(t)
 }
+
+func TestNetworkStatus_NotSynced(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:                   configuration.Online,
+		Network:                networkIdentifier,
+		GenesisBlockIdentifier: fantom.FantomMainnetGenesisBlockIdentifier,
+	}
+	mockClient := &mocks.Client{}
+	servicer := NewNetworkAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	genesisBlock := &types.BlockIdentifier{
+		Index: 0,
+		Hash:  "block 0",
+	}
+	mockClient.On(
+		"Status",
+		ctx,
+	).Return(
+		genesisBlock,
+		int64(1000000000000),
+		(*types.SyncStatus)(nil),
+		[]*types.Peer{},
+		nil,
+	)
+
+	networkStatus, err := servicer.NetworkStatus(ctx, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, genesisBlock, networkStatus.CurrentBlockIdentifier)
+	assert.Equal(t, &types.SyncStatus{
+		CurrentIndex: types.Int64(0),
+		Synced:       types.Bool(false),
+	}, networkStatus.SyncStatus)
+
+	mockClient.AssertExpectations(t)
+}
@@ -15,6 +15,10 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/Fantom-foundation/rosetta-fantom/configuration"
@@ -66,7 +70,7 @@ func NewBlockchainRouter(
 		asserter,
 	)
 
-	return server.NewRouter(
+	router := server.NewRouter(
 		networkAPIController,
 		accountAPIController,
 		blockAPIController,
@@ -74,4 +78,98 @@ func NewBlockchainRouter(
 		mempoolAPIController,
 		callAPIController,
 	)
+
+	metadataRouter := RequestMetadataMiddleware(router)
+	if config.Mode != configuration.Online {
+		return metadataRouter
+	}
+
+	var handler http.Handler = metadataRouter
+	if config.BlockStream {
+		mux := http.NewServeMux()
+		mux.Handle("/", metadataRouter)
+		mux.Handle(BlockStreamEndpoint, NewBlockStreamHandler(config, client))
+		handler = mux
+	}
+
+	return ReadinessMiddleware(client, handler)
+}
+
+// dataEndpoints are the endpoints serving data queried
+// from Opera. /network/status is not included so that
+// the sync status can be queried before Opera is ready.
+var dataEndpoints = map[string]struct{}{
+	"/account/balance":     {},
+	"/account/coins":       {},
+	"/block":               {},
+	"/block/transaction":   {},
+	BlockStreamEndpoint:    {},
+	"/mempool":             {},
+	"/mempool/transaction": {},
+	"/call":                {},
+}
+
+// ReadinessMiddleware rejects requests to the data endpoints
+// with ErrOperaNotReady until Opera is ready to serve them.
+func ReadinessMiddleware(client Client, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := dataEndpoints[r.URL.Path]; ok {
+			if err := client.Ready(r.Context()); err != nil {
+				server.EncodeJSONResponse(
+					wrapErr(ErrOperaNotReady, err),
+					http.StatusInternalServerError,
+					w,
+				)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// metadataEndpoints are the endpoints accepting request
+// metadata that is not part of the Rosetta request types.
+var metadataEndpoints = map[string]struct{}{
+	"/block":             {},
+	"/block/transaction": {},
+}
+
+type requestMetadataContextKey struct{}
+
+// RequestMetadataMiddleware makes the "metadata" object of the
+// requests to /block and /block/transaction available to services
+// through requestMetadata, as it is dropped when the requests are
+// decoded.
+func RequestMetadataMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := metadataEndpoints[r.URL.Path]; !ok || r.Body == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		// Invalid requests are rejected by the controllers
+		var request struct {
+			Metadata map[string]interface{} `json:"metadata"`
+		}
+		if json.Unmarshal(body, &request) == nil && len(request.Metadata) > 0 {
+			r = r.WithContext(context.WithValue(r.Context(), requestMetadataContextKey{}, request.Metadata))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestMetadata returns the metadata of the request
+// served with ctx, or nil if it has none.
+func requestMetadata(ctx context.Context) map[string]interface{} {
+	metadata, _ := ctx.Value(requestMetadataContextKey{}).(map[string]interface{})
+	return metadata
 }
@@ -0,0 +1,111 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Fantom-foundation/rosetta-fantom/fantom"
+	mocks "github.com/Fantom-foundation/rosetta-fantom/mocks/services"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReadinessMiddleware(t *testing.T) {
+	mockClient := &mocks.Client{}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := ReadinessMiddleware(mockClient, next)
+
+	t.Run("static endpoint", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/network/options", strings.NewReader("{}")))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("network status", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/network/status", strings.NewReader("{}")))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("not ready", func(t *testing.T) {
+		mockClient.On("Ready", mock.Anything).Return(
+			fmt.Errorf("%w: no blocks imported", fantom.ErrOperaNotReady),
+		).Once()
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/block", strings.NewReader("{}")))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+		var rosettaErr types.Error
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rosettaErr))
+		assert.Equal(t, ErrOperaNotReady.Code, rosettaErr.Code)
+		assert.True(t, rosettaErr.Retriable)
+	})
+
+	t.Run("ready", func(t *testing.T) {
+		mockClient.On("Ready", mock.Anything).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/block", strings.NewReader("{}")))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestRequestMetadataMiddleware(t *testing.T) {
+	var metadata map[string]interface{}
+	var body string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		metadata = requestMetadata(r.Context())
+		contents, _ := ioutil.ReadAll(r.Body)
+		body = string(contents)
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := RequestMetadataMiddleware(next)
+
+	serve := func(path string, request string) {
+		metadata = nil
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader(request)))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, request, body)
+	}
+
+	request := `{"block_identifier":{"index":1},"metadata":{"include_zero_value_calls":true}}`
+	serve("/block", request)
+	assert.Equal(t, map[string]interface{}{"include_zero_value_calls": true}, metadata)
+
+	serve("/block/transaction", `{"metadata":{}}`)
+	assert.Nil(t, metadata)
+
+	// Other endpoints and invalid requests are passed through
+	serve("/account/balance", request)
+	assert.Nil(t, metadata)
+
+	serve("/block", "invalid")
+	assert.Nil(t, metadata)
+}
@@ -0,0 +1,121 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Fantom-foundation/rosetta-fantom/fantom"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SignPayloads signs the payloads of a /construction/payloads
+// response with key. The hash of each payload is recomputed from
+// the unsigned transaction and the payload is only signed if it
+// matches and belongs to the address of key. The returned
+// signatures can be provided to /construction/combine.
+func SignPayloads(
+	response *types.ConstructionPayloadsResponse,
+	key *ecdsa.PrivateKey,
+) ([]*types.Signature, error) {
+	if response == nil || len(response.Payloads) == 0 {
+		return nil, errors.New("no payloads to sign")
+	}
+
+	var unsignedTx transaction
+	if err := json.Unmarshal([]byte(response.UnsignedTransaction), &unsignedTx); err != nil {
+		return nil, fmt.Errorf("%w: unable to parse unsigned transaction", err)
+	}
+
+	if unsignedTx.ChainID == nil || unsignedTx.Value == nil || unsignedTx.GasPrice == nil {
+		return nil, errors.New("unsigned transaction is incomplete")
+	}
+
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	if !common.IsHexAddress(unsignedTx.From) || common.HexToAddress(unsignedTx.From) != address {
+		return nil, fmt.Errorf(
+			"key of %s cannot sign transaction from %s",
+			address.Hex(),
+			unsignedTx.From,
+		)
+	}
+
+	tx := ethTypes.NewTransaction(
+		unsignedTx.Nonce,
+		common.HexToAddress(unsignedTx.To),
+		unsignedTx.Value,
+		unsignedTx.GasLimit,
+		unsignedTx.GasPrice,
+		unsignedTx.Data,
+	)
+	hash := ethTypes.NewEIP155Signer(unsignedTx.ChainID).Hash(tx)
+
+	publicKey := &types.PublicKey{
+		Bytes:     crypto.CompressPubkey(&key.PublicKey),
+		CurveType: types.Secp256k1,
+	}
+
+	signatures := make([]*types.Signature, len(response.Payloads))
+	for i, payload := range response.Payloads {
+		if payload.SignatureType != "" && payload.SignatureType != types.EcdsaRecovery {
+			return nil, fmt.Errorf("signature type %s is not supported", payload.SignatureType)
+		}
+
+		account := payload.AccountIdentifier
+		if account == nil {
+			return nil, fmt.Errorf("payload %d has no account", i)
+		}
+
+		checkAccount, ok := fantom.ChecksumAddress(account.Address)
+		if !ok || common.HexToAddress(checkAccount) != address {
+			return nil, fmt.Errorf(
+				"key of %s cannot sign payload of %s",
+				address.Hex(),
+				account.Address,
+			)
+		}
+
+		if !bytes.Equal(payload.Bytes, hash.Bytes()) {
+			return nil, fmt.Errorf(
+				"payload %d hash %x does not match unsigned transaction hash %x",
+				i,
+				payload.Bytes,
+				hash.Bytes(),
+			)
+		}
+
+		signature, err := crypto.Sign(payload.Bytes, key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to sign payload %d", err, i)
+		}
+
+		signatures[i] = &types.Signature{
+			SigningPayload: payload,
+			PublicKey:      publicKey,
+			SignatureType:  types.EcdsaRecovery,
+			Bytes:          signature,
+		}
+	}
+
+	return signatures, nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/Fantom-foundation/rosetta-fantom/configuration"
+	"github.com/Fantom-foundation/rosetta-fantom/fantom"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignPayloads(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Offline,
+		ChainID: big.NewInt(0xFA2),
+	}
+	servicer := NewConstructionAPIService(cfg, nil)
+	ctx := context.Background()
+
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	to := "0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d"
+
+	payloadsResponse, rosettaErr := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		Operations: []*types.Operation{
+			{
+				OperationIdentifier: &types.OperationIdentifier{Index: 0},
+				Type:                fantom.CallOpType,
+				Account:             &types.AccountIdentifier{Address: from},
+				Amount:              &types.Amount{Value: "-1000", Currency: fantom.Currency},
+			},
+			{
+				OperationIdentifier: &types.OperationIdentifier{Index: 1},
+				Type:                fantom.CallOpType,
+				Account:             &types.AccountIdentifier{Address: to},
+				Amount:              &types.Amount{Value: "1000", Currency: fantom.Currency},
+			},
+		},
+		Metadata: map[string]interface{}{
+			"nonce":     "0x2",
+			"gas_price": "0x3b9aca00",
+		},
+	})
+	assert.Nil(t, rosettaErr)
+
+	// Round-trip through JSON as done by utils:sign
+	raw, err := json.Marshal(payloadsResponse)
+	assert.NoError(t, err)
+	var response types.ConstructionPayloadsResponse
+	assert.NoError(t, json.Unmarshal(raw, &response))
+
+	t.Run("valid", func(t *testing.T) {
+		signatures, err := SignPayloads(&response, key)
+		assert.NoError(t, err)
+		assert.Len(t, signatures, 1)
+		assert.Equal(t, types.EcdsaRecovery, signatures[0].SignatureType)
+		assert.Equal(t, crypto.CompressPubkey(&key.PublicKey), signatures[0].PublicKey.Bytes)
+		assert.Len(t, signatures[0].Bytes, 65)
+
+		combineResponse, rosettaErr := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+			UnsignedTransaction: response.UnsignedTransaction,
+			Signatures:          signatures,
+		})
+		assert.Nil(t, rosettaErr)
+
+		var signedTx ethTypes.Transaction
+		assert.NoError(t, signedTx.UnmarshalJSON([]byte(combineResponse.SignedTransaction)))
+		sender, err := ethTypes.Sender(ethTypes.NewEIP155Signer(cfg.ChainID), &signedTx)
+		assert.NoError(t, err)
+		assert.Equal(t, common.HexToAddress(from), sender)
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		otherKey, err := crypto.GenerateKey()
+		assert.NoError(t, err)
+
+		signatures, err := SignPayloads(&response, otherKey)
+		assert.Error(t, err)
+		assert.Nil(t, signatures)
+	})
+
+	t.Run("tampered transaction", func(t *testing.T) {
+		var unsignedTx transaction
+		assert.NoError(t, json.Unmarshal([]byte(response.UnsignedTransaction), &unsignedTx))
+		unsignedTx.Value = big.NewInt(1000000)
+		tampered, err := json.Marshal(&unsignedTx)
+		assert.NoError(t, err)
+
+		signatures, err := SignPayloads(&types.ConstructionPayloadsResponse{
+			UnsignedTransaction: string(tampered),
+			Payloads:            response.Payloads,
+		}, key)
+		assert.Contains(t, err.Error(), "does not match unsigned transaction hash")
+		assert.Nil(t, signatures)
+	})
+
+	t.Run("no payloads", func(t *testing.T) {
+		signatures, err := SignPayloads(&types.ConstructionPayloadsResponse{
+			UnsignedTransaction: response.UnsignedTransaction,
+		}, key)
+		assert.Error(t, err)
+		assert.Nil(t, signatures)
+	})
+}
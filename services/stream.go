@@ -0,0 +1,191 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Fantom-foundation/rosetta-fantom/configuration"
+	"github.com/Fantom-foundation/rosetta-fantom/fantom"
+
+	"github.com/coinbase/rosetta-sdk-go/server"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// BlockStreamEndpoint is the path of the Server-Sent Events
+	// stream of blocks, served when configuration.BlockStreamEnv
+	// is set.
+	BlockStreamEndpoint = "/block/stream"
+
+	// BlockStreamFormatBlocks streams each block
+	// as returned by /block.
+	BlockStreamFormatBlocks = "blocks"
+
+	// BlockStreamFormatIdentifiers streams the
+	// identifier of each block.
+	BlockStreamFormatIdentifiers = "identifiers"
+
+	// blockStreamDuration is the duration after which streams
+	// are closed, so that they end on a complete event before
+	// the write timeout of the server. Clients resume streams
+	// by reconnecting with the Last-Event-ID header, as
+	// EventSource does.
+	blockStreamDuration = 100 * time.Second
+)
+
+// BlockStreamHandler serves the Server-Sent Events stream of
+// the blocks of Opera at BlockStreamEndpoint. Each event has
+// the index of its block as id.
+//
+// The "start" query parameter is the index of the first block
+// to stream, defaulting to the first block after the current
+// head, and the "format" query parameter is BlockStreamFormatBlocks
+// (the default) or BlockStreamFormatIdentifiers. A Last-Event-ID
+// header resumes the stream after the block it identifies.
+type BlockStreamHandler struct {
+	blockService *BlockAPIService
+	client       Client
+	duration     time.Duration
+	pollInterval time.Duration
+}
+
+// NewBlockStreamHandler creates a new instance of a BlockStreamHandler.
+func NewBlockStreamHandler(
+	cfg *configuration.Configuration,
+	client Client,
+) *BlockStreamHandler {
+	return &BlockStreamHandler{
+		blockService: NewBlockAPIService(cfg, client),
+		client:       client,
+		duration:     blockStreamDuration,
+		pollInterval: fantom.DefaultStreamPollInterval,
+	}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *BlockStreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	start, format, err := parseBlockStreamRequest(r)
+	if err != nil {
+		server.EncodeJSONResponse(wrapErr(ErrInvalidInput, err), http.StatusBadRequest, w)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// Blocks are fetched with the configured block options
+	ctx, rosettaErr := h.blockService.withBlockOptions(r.Context())
+	if rosettaErr != nil {
+		server.EncodeJSONResponse(rosettaErr, http.StatusInternalServerError, w)
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.duration)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	err = fantom.StreamBlocks(ctx, h.client, start, h.pollInterval, func(block *types.Block) error {
+		var data interface{} = block
+		if format == BlockStreamFormatIdentifiers {
+			data = block.BlockIdentifier
+		}
+
+		if err := writeEvent(w, strconv.FormatInt(block.BlockIdentifier.Index, 10), "block", data); err != nil {
+			return err
+		}
+
+		flusher.Flush()
+		return nil
+	})
+	if err == nil || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return
+	}
+
+	// The status is sent already, so errors end the stream with an
+	// error event. Clients resume the stream after the last block.
+	if writeEvent(w, "", "error", wrapErr(ErrOpera, err)) == nil {
+		flusher.Flush()
+	}
+}
+
+// parseBlockStreamRequest returns the index of the first block
+// and the format of the stream requested by r.
+func parseBlockStreamRequest(r *http.Request) (int64, string, error) {
+	start := int64(-1)
+	if value := r.URL.Query().Get("start"); len(value) > 0 {
+		index, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || index < 0 {
+			return -1, "", fmt.Errorf("start %s is not a valid block index", value)
+		}
+		start = index
+	}
+
+	// Reconnecting clients resume after the last event
+	if value := r.Header.Get("Last-Event-ID"); len(value) > 0 {
+		index, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || index < 0 {
+			return -1, "", fmt.Errorf("last event ID %s is not a valid block index", value)
+		}
+		start = index + 1
+	}
+
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "":
+		format = BlockStreamFormatBlocks
+	case BlockStreamFormatBlocks, BlockStreamFormatIdentifiers:
+	default:
+		return -1, "", fmt.Errorf("format %s is not supported", format)
+	}
+
+	return start, format, nil
+}
+
+// writeEvent writes the Server-Sent Event of type event
+// with data, encoded as JSON, to w.
+func writeEvent(w http.ResponseWriter, id string, event string, data interface{}) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if len(id) > 0 {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, encoded)
+	return err
+}
@@ -0,0 +1,127 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/rosetta-fantom/configuration"
+	mocks "github.com/Fantom-foundation/rosetta-fantom/mocks/services"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBlockStreamHandler(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	head := &types.BlockIdentifier{Index: 11, Hash: "0xb"}
+	block := func(index int64) *types.Block {
+		return &types.Block{
+			BlockIdentifier:       &types.BlockIdentifier{Index: index, Hash: fmt.Sprintf("0x%x", index)},
+			ParentBlockIdentifier: &types.BlockIdentifier{Index: index - 1, Hash: "0x"},
+		}
+	}
+	partial := func(index int64) *types.PartialBlockIdentifier {
+		return &types.PartialBlockIdentifier{Index: &index}
+	}
+
+	newHandler := func(mockClient *mocks.Client) *BlockStreamHandler {
+		handler := NewBlockStreamHandler(cfg, mockClient)
+		handler.duration = 20 * time.Millisecond
+		handler.pollInterval = time.Millisecond
+		return handler
+	}
+
+	t.Run("identifiers", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		mockClient.On("Status", mock.Anything).Return(head, int64(0), nil, nil, nil)
+		mockClient.On("Block", mock.Anything, partial(10)).Return(block(10), nil).Once()
+		mockClient.On("Block", mock.Anything, partial(11)).Return(block(11), nil).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, BlockStreamEndpoint+"?start=10&format=identifiers", nil)
+		newHandler(mockClient).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.Equal(
+			t,
+			"id: 10\nevent: block\ndata: {\"index\":10,\"hash\":\"0xa\"}\n\n"+
+				"id: 11\nevent: block\ndata: {\"index\":11,\"hash\":\"0xb\"}\n\n",
+			w.Body.String(),
+		)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("resume blocks", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		mockClient.On("Status", mock.Anything).Return(head, int64(0), nil, nil, nil)
+		mockClient.On("Block", mock.Anything, partial(11)).Return(block(11), nil).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, BlockStreamEndpoint+"?start=3", nil)
+		r.Header.Set("Last-Event-ID", "10")
+		newHandler(mockClient).ServeHTTP(w, r)
+
+		data, err := json.Marshal(block(11))
+		assert.NoError(t, err)
+		assert.Equal(t, "id: 11\nevent: block\ndata: "+string(data)+"\n\n", w.Body.String())
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("opera error", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		mockClient.On("Status", mock.Anything).Return(head, int64(0), nil, nil, nil)
+		mockClient.On("Block", mock.Anything, partial(11)).Return(nil, errors.New("unavailable")).Once()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, BlockStreamEndpoint+"?start=11", nil)
+		newHandler(mockClient).ServeHTTP(w, r)
+
+		assert.Contains(t, w.Body.String(), "event: error\ndata: {\"code\":2,")
+		mockClient.AssertExpectations(t)
+	})
+
+	invalidRequests := map[string]string{
+		"start":  BlockStreamEndpoint + "?start=-2",
+		"format": BlockStreamEndpoint + "?format=rows",
+	}
+	for name, target := range invalidRequests {
+		t.Run("invalid "+name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			newHandler(&mocks.Client{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var rosettaErr types.Error
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rosettaErr))
+			assert.Equal(t, ErrInvalidInput.Code, rosettaErr.Code)
+		})
+	}
+
+	t.Run("invalid method", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		newHandler(&mocks.Client{}).ServeHTTP(w, httptest.NewRequest(http.MethodPost, BlockStreamEndpoint, nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
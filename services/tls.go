@@ -0,0 +1,135 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Fantom-foundation/rosetta-fantom/configuration"
+)
+
+// certReloader serves a certificate loaded from files and
+// reloads it when the files change, so that renewed
+// certificates are served without a restart.
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	certMod time.Time
+	keyMod  time.Time
+}
+
+func newCertReloader(certFile string, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// reload loads the certificate if the files changed
+// since it was last loaded.
+func (r *certReloader) reload() error {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return err
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	if r.cert != nil && certInfo.ModTime().Equal(r.certMod) && keyInfo.ModTime().Equal(r.keyMod) {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("%w: unable to load certificate", err)
+	}
+
+	if r.cert != nil {
+		log.Printf("reloaded TLS certificate %s\n", r.certFile)
+	}
+	r.cert = &cert
+	r.certMod = certInfo.ModTime()
+	r.keyMod = keyInfo.ModTime()
+
+	return nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate. The
+// previous certificate is served if reloading fails, for
+// example while the files are being replaced.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.reload(); err != nil {
+		log.Printf("err: %s\n", err)
+	}
+
+	return r.cert, nil
+}
+
+// NewTLSConfig returns the TLS configuration of the Rosetta
+// API, or nil if TLS is disabled. The certificate is reloaded
+// when its files change and client certificates are verified
+// against the configured client authorities.
+func NewTLSConfig(config *configuration.Configuration) (*tls.Config, error) {
+	if len(config.TLSCert) == 0 {
+		return nil, nil
+	}
+
+	reloader, err := newCertReloader(config.TLSCert, config.TLSKey)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if len(config.TLSClientCA) > 0 {
+		contents, err := ioutil.ReadFile(config.TLSClientCA)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to read client CA", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(contents) {
+			return nil, fmt.Errorf("no certificates found in %s", config.TLSClientCA)
+		}
+		tlsConfig.ClientCAs = pool
+
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		if config.TLSClientAuth == configuration.TLSClientAuthRequired {
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	return tlsConfig, nil
+}
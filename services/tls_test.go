@@ -0,0 +1,152 @@
+// Copyright 2022 Fantom Foundation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/rosetta-fantom/configuration"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestCertificate writes a self-signed certificate for
+// commonName and its key to certFile and keyFile.
+func writeTestCertificate(t *testing.T, commonName string, certFile string, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	assert.NoError(t, ioutil.WriteFile(
+		certFile,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		0600,
+	))
+	assert.NoError(t, ioutil.WriteFile(
+		keyFile,
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+		0600,
+	))
+}
+
+func servedCommonName(t *testing.T, config *tls.Config) string {
+	cert, err := config.GetCertificate(&tls.ClientHelloInfo{})
+	assert.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	assert.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	caFile := filepath.Join(dir, "ca.pem")
+	writeTestCertificate(t, "rosetta.internal", certFile, keyFile)
+	writeTestCertificate(t, "clients.internal", caFile, filepath.Join(dir, "ca_key.pem"))
+
+	t.Run("disabled", func(t *testing.T) {
+		config, err := NewTLSConfig(&configuration.Configuration{})
+		assert.NoError(t, err)
+		assert.Nil(t, config)
+	})
+
+	t.Run("missing certificate", func(t *testing.T) {
+		config, err := NewTLSConfig(&configuration.Configuration{
+			TLSCert: filepath.Join(dir, "missing.pem"),
+			TLSKey:  keyFile,
+		})
+		assert.Error(t, err)
+		assert.Nil(t, config)
+	})
+
+	t.Run("client certificates", func(t *testing.T) {
+		config, err := NewTLSConfig(&configuration.Configuration{
+			TLSCert:       certFile,
+			TLSKey:        keyFile,
+			TLSClientCA:   caFile,
+			TLSClientAuth: configuration.TLSClientAuthOptional,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, tls.VerifyClientCertIfGiven, config.ClientAuth)
+		assert.NotNil(t, config.ClientCAs)
+
+		config, err = NewTLSConfig(&configuration.Configuration{
+			TLSCert:       certFile,
+			TLSKey:        keyFile,
+			TLSClientCA:   caFile,
+			TLSClientAuth: configuration.TLSClientAuthRequired,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, tls.RequireAndVerifyClientCert, config.ClientAuth)
+
+		_, err = NewTLSConfig(&configuration.Configuration{
+			TLSCert:     certFile,
+			TLSKey:      keyFile,
+			TLSClientCA: keyFile,
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		config, err := NewTLSConfig(&configuration.Configuration{
+			TLSCert: certFile,
+			TLSKey:  keyFile,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, tls.NoClientCert, config.ClientAuth)
+		assert.Equal(t, "rosetta.internal", servedCommonName(t, config))
+
+		// Renewed certificates are served once the files change
+		writeTestCertificate(t, "renewed.internal", certFile, keyFile)
+		later := time.Now().Add(time.Minute)
+		assert.NoError(t, os.Chtimes(certFile, later, later))
+		assert.NoError(t, os.Chtimes(keyFile, later, later))
+		assert.Equal(t, "renewed.internal", servedCommonName(t, config))
+
+		// The previous certificate is kept if the files are invalid
+		assert.NoError(t, ioutil.WriteFile(certFile, []byte("invalid"), 0600))
+		later = later.Add(time.Minute)
+		assert.NoError(t, os.Chtimes(certFile, later, later))
+		assert.Equal(t, "renewed.internal", servedCommonName(t, config))
+	})
+}
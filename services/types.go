@@ -28,6 +28,8 @@ import (
 // Client is used by the services to get block
 // data and to submit transactions.
 type Client interface {
+	Ready(context.Context) error
+
 	Status(context.Context) (
 		*types.BlockIdentifier,
 		int64,
@@ -61,6 +63,11 @@ type Client interface {
 
 	GetMempool(ctx context.Context) (*types.MempoolResponse, error)
 
+	MempoolTransaction(
+		ctx context.Context,
+		transactionIdentifier *types.TransactionIdentifier,
+	) (*types.Transaction, error)
+
 	Call(
 		ctx context.Context,
 		request *types.CallRequest,
@@ -69,16 +76,30 @@ type Client interface {
 
 type options struct {
 	From string `json:"from"`
+
+	// To and Data are set for contract calls,
+	// like the transfers of NFTs.
+	To   string `json:"to,omitempty"`
+	Data string `json:"data,omitempty"`
+
+	// Value is the hex-encoded amount of FTM
+	// sent with payable contract calls.
+	Value string `json:"value,omitempty"`
 }
 
 type metadata struct {
 	Nonce    uint64   `json:"nonce"`
 	GasPrice *big.Int `json:"gas_price"`
+
+	// GasLimit is the estimated gas limit of contract
+	// calls. Transfers of FTM use fantom.TransferGasLimit.
+	GasLimit uint64 `json:"gas_limit,omitempty"`
 }
 
 type metadataWire struct {
 	Nonce    string `json:"nonce"`
 	GasPrice string `json:"gas_price"`
+	GasLimit string `json:"gas_limit,omitempty"`
 }
 
 func (m *metadata) MarshalJSON() ([]byte, error) {
@@ -86,6 +107,9 @@ func (m *metadata) MarshalJSON() ([]byte, error) {
 		Nonce:    hexutil.Uint64(m.Nonce).String(),
 		GasPrice: hexutil.EncodeBig(m.GasPrice),
 	}
+	if m.GasLimit > 0 {
+		mw.GasLimit = hexutil.Uint64(m.GasLimit).String()
+	}
 
 	return json.Marshal(mw)
 }
@@ -106,6 +130,15 @@ func (m *metadata) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	if len(mw.GasLimit) > 0 {
+		gasLimit, err := hexutil.DecodeUint64(mw.GasLimit)
+		if err != nil {
+			return err
+		}
+
+		m.GasLimit = gasLimit
+	}
+
 	m.GasPrice = gasPrice
 	m.Nonce = nonce
 	return nil